@@ -9,21 +9,33 @@ import (
 
 	"gabe565.com/gones/cmd/options"
 	"gabe565.com/gones/internal/config"
+	"gabe565.com/gones/internal/console"
 	"gabe565.com/gones/internal/util"
+	"gabe565.com/utils/must"
 	"github.com/spf13/cobra"
 )
 
+const (
+	FlagRecord          = "record"
+	FlagRecordFromState = "record-from-state"
+	FlagPlay            = "play"
+	FlagRecordVideo     = "record-video"
+	FlagDumpAudio       = "dump-audio"
+)
+
 func New(opts ...options.Option) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "gones ROM",
 		Short: "NES emulator written in Go",
+		Args:  cobra.MaximumNArgs(1),
 		RunE:  runCobra,
 
-		ValidArgsFunction: util.CompleteROM,
+		ValidArgsFunction: util.CompleteGame,
 		SilenceErrors:     true,
 		DisableAutoGenTag: true,
 	}
-	config.Flags(cmd)
+	registerFlags(cmd)
+	cmd.AddCommand(newRunCmd(), newPlayNSFCmd())
 
 	for _, opt := range opts {
 		opt(cmd)
@@ -32,12 +44,44 @@ func New(opts ...options.Option) *cobra.Command {
 	return cmd
 }
 
+func registerFlags(cmd *cobra.Command) {
+	config.Flags(cmd)
+
+	cmd.Flags().String(FlagRecord, "", "Record controller input to an FM2 movie file. Zappers and Vaus paddles can't be recorded. Saves are not loaded or written while a movie is active.")
+	cmd.Flags().Bool(FlagRecordFromState, false, "Anchor the recorded movie to the resume state instead of power-on. FCEUX can't play movies anchored to a GoNES state")
+	cmd.Flags().String(FlagPlay, "", "Play back controller input from an FM2 movie file")
+	for _, name := range []string{FlagRecord, FlagPlay} {
+		if err := cmd.RegisterFlagCompletionFunc(name, func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return []string{"fm2"}, cobra.ShellCompDirectiveFilterFileExt
+		}); err != nil {
+			panic(err)
+		}
+	}
+	cmd.MarkFlagsMutuallyExclusive(FlagRecord, FlagPlay)
+
+	cmd.Flags().String(FlagRecordVideo, "", "Record video to a Y4M file, and audio to a WAV file next to it. Both are timed by emulated frames, so they stay in sync.")
+	if err := cmd.RegisterFlagCompletionFunc(FlagRecordVideo, func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return []string{"y4m"}, cobra.ShellCompDirectiveFilterFileExt
+	}); err != nil {
+		panic(err)
+	}
+
+	cmd.Flags().String(FlagDumpAudio, "", "Dump raw APU output to WAV files in a directory. Use --stems to also write each channel to its own file.")
+	if err := cmd.RegisterFlagCompletionFunc(FlagDumpAudio, func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	}); err != nil {
+		panic(err)
+	}
+}
+
 func runCobra(cmd *cobra.Command, args []string) error {
 	cmd.SilenceUsage = true
 
 	var path string
 	if len(args) > 0 {
 		path = args[0]
+	} else if isHeadless(cmd) {
+		return ErrHeadlessROM
 	}
 
 	cart, err := loadCartridge(path)
@@ -49,9 +93,30 @@ func runCobra(cmd *cobra.Command, args []string) error {
 	if err := conf.Load(cmd, cart.Name(), cart.Hash()); err != nil {
 		return err
 	}
+	if err := validateDebugger(conf); err != nil {
+		return err
+	}
+
+	var opts []console.Option
+	if path := must.Must2(cmd.Flags().GetString(FlagRecord)); path != "" {
+		fromState := must.Must2(cmd.Flags().GetBool(FlagRecordFromState))
+		opts = append(opts, console.WithMovieRecord(path, fromState))
+	}
+	if path := must.Must2(cmd.Flags().GetString(FlagPlay)); path != "" {
+		opts = append(opts, console.WithMoviePlayback(path))
+	}
+	if path := must.Must2(cmd.Flags().GetString(FlagRecordVideo)); path != "" {
+		opts = append(opts, console.WithRecording(path))
+	}
+	if dir := must.Must2(cmd.Flags().GetString(FlagDumpAudio)); dir != "" {
+		opts = append(opts, console.WithAudioDump(dir))
+	}
 
 	ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
 	defer cancel()
 
-	return run(ctx, conf, cart)
+	if isHeadless(cmd) {
+		return runHeadless(ctx, cmd, conf, cart, opts...)
+	}
+	return run(ctx, conf, cart, opts...)
 }
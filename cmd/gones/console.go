@@ -3,7 +3,11 @@
 package gones
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"log/slog"
+	"os"
 
 	"gabe565.com/gones/internal/cartridge"
 	"gabe565.com/gones/internal/config"
@@ -18,7 +22,12 @@ func loadCartridge(path string) (*cartridge.Cartridge, error) {
 			zenity.Title("Choose a ROM file"),
 			zenity.FileFilter{
 				Name:     "NES ROM",
-				Patterns: []string{"*.nes"},
+				Patterns: []string{"*.nes", "*.fds"},
+				CaseFold: true,
+			},
+			zenity.FileFilter{
+				Name:     "NES music",
+				Patterns: []string{"*.nsf", "*.nsfe"},
 				CaseFold: true,
 			},
 		); err != nil {
@@ -26,15 +35,58 @@ func loadCartridge(path string) (*cartridge.Cartridge, error) {
 		}
 	}
 
-	cart, err := cartridge.FromINESFile(path)
+	b, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+
+	var cart *cartridge.Cartridge
+	switch {
+	case cartridge.IsFDS(b):
+		bios, err := loadFDSBIOS()
+		if err != nil {
+			return nil, err
+		}
+
+		if cart, err = cartridge.FromFDS(bytes.NewReader(b), bios); err != nil {
+			return nil, err
+		}
+	case cartridge.IsNSF(b):
+		if cart, err = cartridge.FromNSF(bytes.NewReader(b)); err != nil {
+			return nil, err
+		}
+	default:
+		if cart, err = cartridge.FromINES(bytes.NewReader(b)); err != nil {
+			return nil, err
+		}
+	}
+	if cart.Name() == "" {
+		cart.SetName(path)
+	}
 	slog.Info("Loaded cartridge", "", cart)
 
 	return cart, nil
 }
 
-func newConsole(conf *config.Config, cart *cartridge.Cartridge) (*console.Console, error) {
-	return console.New(conf, cart)
+var ErrMissingBIOS = errors.New("missing FDS BIOS")
+
+// loadFDSBIOS reads the Famicom Disk System BIOS from the config directory.
+func loadFDSBIOS() ([]byte, error) {
+	path, err := config.GetFDSBIOSPath()
+	if err != nil {
+		return nil, err
+	}
+
+	bios, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: copy the BIOS to %s", ErrMissingBIOS, path)
+		}
+		return nil, err
+	}
+	return bios, nil
+}
+
+func newConsole(conf *config.Config, cart *cartridge.Cartridge, opts ...console.Option) (*console.Console, error) {
+	return console.New(conf, cart, opts...)
 }
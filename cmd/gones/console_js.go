@@ -20,7 +20,13 @@ func loadCartridge() (*cartridge.Cartridge, error) {
 
 	r := bytes.NewReader(goData)
 
-	cart, err := cartridge.FromINES(r)
+	var cart *cartridge.Cartridge
+	var err error
+	if cartridge.IsNSF(goData) {
+		cart, err = cartridge.FromNSF(r)
+	} else {
+		cart, err = cartridge.FromINES(r)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -32,10 +38,10 @@ func loadCartridge() (*cartridge.Cartridge, error) {
 	return cart, nil
 }
 
-func newConsole(conf *config.Config, cart *cartridge.Cartridge) (*console.Console, error) {
+func newConsole(conf *config.Config, cart *cartridge.Cartridge, opts ...console.Option) (*console.Console, error) {
 	js.Global().Get("GonesClient").Call("setRomName", cart.Name())
 
-	c, err := console.New(conf, cart)
+	c, err := console.New(conf, cart, opts...)
 	if err != nil {
 		return c, err
 	}
@@ -0,0 +1,36 @@
+package gones
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+
+	"gabe565.com/gones/internal/config"
+	"gabe565.com/gones/internal/debugger"
+)
+
+var ErrUnknownDebugger = errors.New("unknown debugger")
+
+func validateDebugger(conf *config.Config) error {
+	if val := conf.Debug.Debugger; val != "" && !slices.Contains(config.DebuggerStrings(), val) {
+		return fmt.Errorf("%w: %s", ErrUnknownDebugger, val)
+	}
+	return nil
+}
+
+func runDebugger(ctx context.Context, conf *config.Config, d *debugger.Debugger) {
+	var err error
+	switch conf.Debug.Debugger {
+	case config.DebuggerREPL:
+		err = debugger.RunREPL(ctx, d, os.Stdin, os.Stdout)
+	case config.DebuggerGDB:
+		err = debugger.ListenGDB(ctx, d, cmp.Or(conf.Debug.GDBAddr, config.DefaultGDBAddr))
+	}
+	if err != nil {
+		slog.Error("Debugger failed", "error", err)
+	}
+}
@@ -0,0 +1,98 @@
+//go:build !js
+
+package gones
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+
+	"gabe565.com/gones/internal/cartridge"
+	"gabe565.com/gones/internal/config"
+	"gabe565.com/gones/internal/console"
+	"gabe565.com/gones/internal/util"
+	"gabe565.com/utils/must"
+	"github.com/spf13/cobra"
+)
+
+const (
+	FlagHeadless   = "headless"
+	FlagFrames     = "frames"
+	FlagScreenshot = "screenshot"
+	FlagSRAM       = "sram"
+	FlagState      = "state"
+)
+
+func newRunCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run ROM",
+		Short: "Run a ROM, optionally without a window",
+		Args:  cobra.MaximumNArgs(1),
+		RunE:  runCobra,
+
+		ValidArgsFunction: util.CompleteGame,
+	}
+	registerFlags(cmd)
+
+	cmd.Flags().Bool(FlagHeadless, false, "Run without a window or audio. Saves are not loaded or written.")
+	cmd.Flags().Int(FlagFrames, 0, "Number of frames to run in headless mode (0 runs until interrupted)")
+	cmd.Flags().String(FlagScreenshot, "", "Write the final frame to a PNG file when a headless run exits")
+	cmd.Flags().String(FlagSRAM, "", "Write SRAM to a file when a headless run exits")
+	cmd.Flags().String(FlagState, "", "Write a save state to a file when a headless run exits")
+	return cmd
+}
+
+func runHeadless(ctx context.Context, cmd *cobra.Command, conf *config.Config, cart *cartridge.Cartridge, opts ...console.Option) error {
+	opts = append(opts, console.WithHeadless())
+	c, err := console.New(conf, cart, opts...)
+	if err != nil {
+		return err
+	}
+
+	frames := must.Must2(cmd.Flags().GetInt(FlagFrames))
+	slog.Info("Running headless", "frames", frames)
+	runErr := c.RunFrames(ctx, frames)
+	if errors.Is(runErr, context.Canceled) {
+		runErr = nil
+	}
+
+	errs := []error{runErr}
+	if path := must.Must2(cmd.Flags().GetString(FlagScreenshot)); path != "" {
+		errs = append(errs, writeFile(path, c.WriteFramePNG))
+	}
+	if path := must.Must2(cmd.Flags().GetString(FlagSRAM)); path != "" {
+		slog.Info("Writing SRAM", "path", path)
+		errs = append(errs, os.WriteFile(path, c.Cartridge.SRAM, 0o666))
+	}
+	if path := must.Must2(cmd.Flags().GetString(FlagState)); path != "" {
+		errs = append(errs, writeFile(path, c.SaveState))
+	}
+	errs = append(errs, c.Close())
+	return errors.Join(errs...)
+}
+
+var ErrHeadlessROM = errors.New("a ROM path is required in headless mode")
+
+func isHeadless(cmd *cobra.Command) bool {
+	return cmd.Flags().Lookup(FlagHeadless) != nil && must.Must2(cmd.Flags().GetBool(FlagHeadless))
+}
+
+func writeFile(path string, write func(w io.Writer) error) error {
+	slog.Info("Writing file", "path", path)
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	if err := write(f); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
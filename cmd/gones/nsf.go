@@ -0,0 +1,66 @@
+//go:build !js
+
+package gones
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"gabe565.com/gones/internal/config"
+	"gabe565.com/gones/internal/console"
+	"gabe565.com/gones/internal/util"
+	"gabe565.com/utils/must"
+	"github.com/spf13/cobra"
+)
+
+const (
+	FlagTrack  = "track"
+	FlagLength = "length"
+	FlagFade   = "fade"
+)
+
+func newPlayNSFCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "play-nsf FILE",
+		Short: "Play an NSF or NSFe music file",
+		Long: `Play an NSF or NSFe music file.
+
+Tracks advance automatically when they end. Use the player 1 left and right keys to switch tracks.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runPlayNSF,
+
+		ValidArgsFunction: util.CompleteNSF,
+	}
+	config.Flags(cmd)
+
+	cmd.Flags().Int(FlagTrack, 0, "Track to play first (default is the file's starting track)")
+	cmd.Flags().Duration(FlagLength, 0, "Time to play each track before fading out (default is the file's track length, or 3m)")
+	cmd.Flags().Duration(FlagFade, 0, "Time to fade out each track (default is the file's fade time, or 5s)")
+	return cmd
+}
+
+func runPlayNSF(cmd *cobra.Command, args []string) error {
+	cmd.SilenceUsage = true
+
+	cart, err := loadCartridge(args[0])
+	if err != nil {
+		return err
+	}
+
+	conf := config.NewDefault()
+	if err := conf.Load(cmd, cart.Name(), cart.Hash()); err != nil {
+		return err
+	}
+
+	opts := console.NSFOptions{
+		Track:  must.Must2(cmd.Flags().GetInt(FlagTrack)),
+		Length: must.Must2(cmd.Flags().GetDuration(FlagLength)),
+		Fade:   must.Must2(cmd.Flags().GetDuration(FlagFade)),
+	}
+
+	ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+	defer cancel()
+
+	return run(ctx, conf, cart, console.WithNSF(opts))
+}
@@ -13,7 +13,7 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
-func run(ctx context.Context, conf *config.Config, cart *cartridge.Cartridge) error {
+func run(ctx context.Context, conf *config.Config, cart *cartridge.Cartridge, opts ...console.Option) error {
 	if pprof.Enabled {
 		go func() {
 			if err := pprof.ListenAndServe(); err != nil {
@@ -22,7 +22,7 @@ func run(ctx context.Context, conf *config.Config, cart *cartridge.Cartridge) er
 		}()
 	}
 
-	c, err := newConsole(conf, cart)
+	c, err := newConsole(conf, cart, opts...)
 	if err != nil {
 		return err
 	}
@@ -38,6 +38,10 @@ func run(ctx context.Context, conf *config.Config, cart *cartridge.Cartridge) er
 			slog.Info("Exiting...")
 			c.SetUpdateAction(console.ActionExit)
 		}()
+
+		if d := c.Debugger(); d != nil {
+			go runDebugger(ctx, conf, d)
+		}
 	}
 
 	scale := conf.UI.Scale
@@ -45,7 +49,9 @@ func run(ctx context.Context, conf *config.Config, cart *cartridge.Cartridge) er
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 	ebiten.SetFullscreen(conf.UI.Fullscreen)
 	ebiten.SetScreenClearedEveryFrame(false)
-	ebiten.SetRunnableOnUnfocused(!conf.UI.PauseUnfocused)
+	// The debugger is driven from a terminal or GDB client, so the window will usually be unfocused
+	ebiten.SetRunnableOnUnfocused(!conf.UI.PauseUnfocused || c.Debugger() != nil)
+	ebiten.SetTPS(c.Region().TargetFrameRate())
 	setWindowIcons()
 
 	if name := c.Cartridge.Name(); name != "" {
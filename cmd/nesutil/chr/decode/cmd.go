@@ -92,7 +92,7 @@ func loadCHR(input string) ([]byte, error) {
 			return nil, err
 		}
 
-		if cart.Header.CHRCount == 0 {
+		if cart.Header.CHRSize() == 0 {
 			return nil, fmt.Errorf("%w: %s", ErrNoCHR, input)
 		}
 
@@ -0,0 +1,124 @@
+package disasm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"gabe565.com/gones/internal/cartridge"
+	"gabe565.com/gones/internal/disasm"
+	"gabe565.com/gones/internal/util"
+	"gabe565.com/utils/must"
+	"github.com/spf13/cobra"
+)
+
+const (
+	FlagBankSize     = "bank-size"
+	FlagMap          = "map"
+	FlagUndocumented = "undocumented"
+	FlagOutput       = "output"
+	FlagLinkerConfig = "ld-config"
+)
+
+var ErrInvalidMap = errors.New("invalid bank mapping")
+
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "disasm ROM",
+		Short: "Disassemble PRG ROM into ca65 source",
+		Long: `Disassemble PRG ROM into ca65 source.
+
+Code is traced from the reset, NMI, and IRQ vectors, and everything else is written as data.
+The generated source reassembles to the original ROM when linked with the config written by --ld-config:
+
+  ca65 game.s -o game.o && ld65 -C game.cfg game.o -o game.nes`,
+		Args: cobra.ExactArgs(1),
+		RunE: run,
+
+		ValidArgsFunction: util.CompleteROM,
+	}
+
+	flag := cmd.Flags()
+	flag.Int(FlagBankSize, 16, "PRG bank size in KiB")
+	flag.StringSlice(FlagMap, nil, "Map a PRG bank to a CPU address (format: BANK=ADDR, e.g. 0=C000)")
+	flag.Bool(FlagUndocumented, false, "Trace undocumented opcodes as code")
+	flag.StringP(FlagOutput, "o", "", "Source output file path (default stdout)")
+	flag.String(FlagLinkerConfig, "", "Write an ld65 linker config to this path")
+
+	return cmd
+}
+
+func run(cmd *cobra.Command, args []string) error {
+	conf := disasm.Config{
+		BankSize:     must.Must2(cmd.Flags().GetInt(FlagBankSize)) * 1024,
+		Undocumented: must.Must2(cmd.Flags().GetBool(FlagUndocumented)),
+	}
+	var err error
+	if conf.Mapping, err = parseMapping(must.Must2(cmd.Flags().GetStringSlice(FlagMap))); err != nil {
+		return err
+	}
+
+	cart, err := cartridge.FromINESFile(args[0])
+	if err != nil {
+		return err
+	}
+	cmd.SilenceUsage = true
+
+	d, err := disasm.New(cart, conf)
+	if err != nil {
+		return err
+	}
+
+	if path := must.Must2(cmd.Flags().GetString(FlagLinkerConfig)); path != "" {
+		if err := writeFile(path, d.WriteLinkerConfig); err != nil {
+			return err
+		}
+		slog.Info("Wrote linker config", "path", path)
+	}
+
+	if path := must.Must2(cmd.Flags().GetString(FlagOutput)); path != "" {
+		if err := writeFile(path, d.WriteSource); err != nil {
+			return err
+		}
+		slog.Info("Wrote source", "path", path)
+		return nil
+	}
+	return d.WriteSource(cmd.OutOrStdout())
+}
+
+func parseMapping(values []string) (map[int]uint16, error) {
+	mapping := make(map[int]uint16, len(values))
+	for _, v := range values {
+		bankStr, addrStr, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidMap, v)
+		}
+		bank, err := strconv.Atoi(bankStr)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidMap, v)
+		}
+		addrStr = strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(addrStr), "$"), "0x")
+		addr, err := strconv.ParseUint(addrStr, 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidMap, v)
+		}
+		mapping[bank] = uint16(addr)
+	}
+	return mapping, nil
+}
+
+func writeFile(path string, fn func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -1,11 +1,10 @@
 package encode
 
 import (
-	"errors"
 	"io"
 	"strconv"
-	"strings"
 
+	"gabe565.com/gones/internal/genie"
 	"github.com/spf13/cobra"
 )
 
@@ -40,7 +39,7 @@ func run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	code, err := encode(int(address), int(replace), int(compare))
+	code, err := genie.Encode(int(address), int(replace), int(compare))
 	if err != nil {
 		return err
 	}
@@ -48,45 +47,3 @@ func run(cmd *cobra.Command, args []string) error {
 	_, err = io.WriteString(cmd.OutOrStdout(), code+"\n")
 	return err
 }
-
-var ErrOutOfRange = errors.New("encoded value out of range")
-
-func encode(address, replace, compare int) (string, error) {
-	var codeLen int
-	var bigint int
-
-	// Create 24/32-bit int and clear/set MSB of address for 6/8-letter codes
-	if compare == -1 {
-		codeLen = 6
-		address &= 0x7fff
-		bigint = (address << 8) | replace
-	} else {
-		codeLen = 8
-		address |= 0x8000
-		bigint = (address << 16) | (replace << 8) | compare
-	}
-
-	// Convert into 4-bit ints
-	encoded := make([]int, codeLen)
-	loPosOrder := []int{3, 5, 2, 4, 1, 0, 7, 6}
-	for i := codeLen - 1; i >= 0; i-- {
-		loPos := loPosOrder[i]
-		hiPos := (loPos - 1 + codeLen) % codeLen
-		encoded[loPos] |= bigint & 0b111
-		encoded[hiPos] |= bigint & 0b1000
-		bigint >>= 4
-	}
-
-	// Convert into letters
-	var result strings.Builder
-	result.Grow(codeLen)
-	const lookup = "APZLGITYEOXUKSVN"
-	for _, val := range encoded {
-		if val < 0 || val >= len(lookup) {
-			return "", ErrOutOfRange
-		}
-		result.WriteByte(lookup[val])
-	}
-
-	return result.String(), nil
-}
@@ -9,19 +9,27 @@ import (
 	"strings"
 
 	"gabe565.com/gones/internal/cartridge"
-	"gabe565.com/gones/internal/consts"
 	"gabe565.com/gones/internal/util"
 	"gabe565.com/utils/must"
 	"github.com/spf13/cobra"
 )
 
 const (
-	FlagHeader  = "header"
-	FlagPRG     = "prg"
-	FlagCHR     = "chr"
-	FlagMapper  = "mapper"
-	FlagMirror  = "mirror"
-	FlagBattery = "battery"
+	FlagHeader    = "header"
+	FlagPRG       = "prg"
+	FlagCHR       = "chr"
+	FlagMapper    = "mapper"
+	FlagSubmapper = "submapper"
+	FlagMirror    = "mirror"
+	FlagBattery   = "battery"
+	FlagNESv2     = "nes2"
+	FlagPRGRAM    = "prg-ram"
+	FlagPRGNVRAM  = "prg-nvram"
+	FlagCHRRAM    = "chr-ram"
+	FlagCHRNVRAM  = "chr-nvram"
+	FlagTiming    = "timing"
+	FlagConsole   = "console"
+	FlagExpansion = "expansion"
 )
 
 func New() *cobra.Command {
@@ -38,15 +46,32 @@ func New() *cobra.Command {
 	flag.StringP(FlagHeader, "H", "", "Header file")
 	flag.StringP(FlagPRG, "p", "", "PRG ROM output file path")
 	flag.StringP(FlagCHR, "c", "", "CHR ROM output file path")
-	flag.Uint8P(FlagMapper, "m", 0, "INES mapper number")
+	flag.Uint16P(FlagMapper, "m", 0, "INES mapper number")
+	flag.Uint8(FlagSubmapper, 0, "NES 2.0 submapper number")
 	flag.StringP(FlagMirror, "n", "", "Type of nametable mirroring (one of horizontal, vertical, fourscreen)")
 	flag.BoolP(FlagBattery, "b", false, "Enable battery/extra RAM")
+	flag.Bool(FlagNESv2, true, "Write a NES 2.0 header")
+	flag.Int(FlagPRGRAM, 0, "NES 2.0 PRG-RAM size in bytes")
+	flag.Int(FlagPRGNVRAM, 0, "NES 2.0 battery-backed PRG-RAM size in bytes")
+	flag.Int(FlagCHRRAM, 0, "NES 2.0 CHR-RAM size in bytes")
+	flag.Int(FlagCHRNVRAM, 0, "NES 2.0 battery-backed CHR-RAM size in bytes")
+	flag.String(FlagTiming, "", "NES 2.0 CPU/PPU timing (one of ntsc, pal, multi, dendy)")
+	must.Must(cmd.RegisterFlagCompletionFunc(FlagTiming,
+		func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+			return []string{"ntsc", "pal", "multi", "dendy"}, cobra.ShellCompDirectiveNoFileComp
+		},
+	))
+	flag.Uint8(FlagConsole, 0, "Console type number")
+	flag.Uint8(FlagExpansion, 0, "NES 2.0 default expansion device number")
 	must.Must(cmd.MarkFlagRequired(FlagPRG))
 
 	return cmd
 }
 
-var ErrUnknownMirror = errors.New("unknown mirror")
+var (
+	ErrUnknownMirror = errors.New("unknown mirror")
+	ErrUnknownTiming = errors.New("unknown timing")
+)
 
 func run(cmd *cobra.Command, args []string) error {
 	cart := cartridge.New()
@@ -71,7 +96,6 @@ func run(cmd *cobra.Command, args []string) error {
 		if cart.PRG, err = os.ReadFile(prg); err != nil {
 			return err
 		}
-		cart.Header.PRGCount = byte(len(cart.PRG) / consts.PRGChunkSize)
 	}
 
 	if chr := must.Must2(cmd.Flags().GetString(FlagCHR)); chr != "" {
@@ -80,15 +104,38 @@ func run(cmd *cobra.Command, args []string) error {
 		if cart.CHR, err = os.ReadFile(chr); err != nil {
 			return err
 		}
-		cart.Header.CHRCount = byte(len(cart.CHR) / consts.CHRChunkSize)
+	}
+
+	if cmd.Flags().Lookup(FlagNESv2).Changed {
+		nes2 := must.Must2(cmd.Flags().GetBool(FlagNESv2))
+		slog.Info("Set NES 2.0", "value", nes2)
+		cart.Header.SetNESv2(nes2)
+	}
+
+	if cart.PRG != nil {
+		if err := cart.Header.SetPRGSize(len(cart.PRG)); err != nil {
+			return err
+		}
+	}
+
+	if cart.CHR != nil {
+		if err := cart.Header.SetCHRSize(len(cart.CHR)); err != nil {
+			return err
+		}
 	}
 
 	if cmd.Flags().Lookup(FlagMapper).Changed {
-		mapper := must.Must2(cmd.Flags().GetUint8(FlagMapper))
+		mapper := must.Must2(cmd.Flags().GetUint16(FlagMapper))
 		slog.Info("Set mapper", "value", mapper)
 		cart.Header.SetMapper(mapper)
 	}
 
+	if cmd.Flags().Lookup(FlagSubmapper).Changed {
+		submapper := must.Must2(cmd.Flags().GetUint8(FlagSubmapper))
+		slog.Info("Set submapper", "value", submapper)
+		cart.Header.SetSubmapper(submapper)
+	}
+
 	if cmd.Flags().Lookup(FlagMirror).Changed {
 		var mirror cartridge.Mirror
 		switch strings.ToLower(must.Must2(cmd.Flags().GetString(FlagMirror))) {
@@ -111,6 +158,54 @@ func run(cmd *cobra.Command, args []string) error {
 		cart.Header.SetBattery(battery)
 	}
 
+	for _, ram := range []struct {
+		flag string
+		set  func(int) error
+	}{
+		{FlagPRGRAM, cart.Header.SetPRGRAMSize},
+		{FlagPRGNVRAM, cart.Header.SetPRGNVRAMSize},
+		{FlagCHRRAM, cart.Header.SetCHRRAMSize},
+		{FlagCHRNVRAM, cart.Header.SetCHRNVRAMSize},
+	} {
+		if cmd.Flags().Lookup(ram.flag).Changed {
+			size := must.Must2(cmd.Flags().GetInt(ram.flag))
+			slog.Info("Set "+ram.flag, "value", size)
+			if err := ram.set(size); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cmd.Flags().Lookup(FlagTiming).Changed {
+		var timing cartridge.Timing
+		switch val := strings.ToLower(must.Must2(cmd.Flags().GetString(FlagTiming))); val {
+		case "ntsc", "n":
+			timing = cartridge.TimingNTSC
+		case "pal", "p":
+			timing = cartridge.TimingPAL
+		case "multi", "m":
+			timing = cartridge.TimingMulti
+		case "dendy", "d":
+			timing = cartridge.TimingDendy
+		default:
+			return fmt.Errorf("%w: %s", ErrUnknownTiming, val)
+		}
+		slog.Info("Set timing", "value", timing)
+		cart.Header.SetTiming(timing)
+	}
+
+	if cmd.Flags().Lookup(FlagConsole).Changed {
+		console := cartridge.ConsoleType(must.Must2(cmd.Flags().GetUint8(FlagConsole)))
+		slog.Info("Set console type", "value", console)
+		cart.Header.SetConsoleType(console)
+	}
+
+	if cmd.Flags().Lookup(FlagExpansion).Changed {
+		expansion := cartridge.ExpansionDevice(must.Must2(cmd.Flags().GetUint8(FlagExpansion)))
+		slog.Info("Set expansion device", "value", expansion)
+		cart.Header.SetExpansionDevice(expansion)
+	}
+
 	f, err := os.Create(args[0])
 	if err != nil {
 		return err
@@ -206,13 +206,13 @@ func deleteFunc(filters map[string]string, errCh chan error) func(e *entry) bool
 			case NameField:
 				return !strings.Contains(strings.ToLower(e.Name), strings.ToLower(filter))
 			case MapperField:
-				parsed, err := strconv.ParseUint(filter, 10, 8)
+				parsed, err := strconv.ParseUint(filter, 10, 16)
 				if err != nil {
 					errCh <- fmt.Errorf("invalid mapper filter value: %w", err)
 					return false
 				}
 
-				return byte(parsed) != e.Mapper
+				return uint16(parsed) != e.Mapper
 			case MirrorField:
 				return !strings.Contains(strings.ToLower(e.Mirror), strings.ToLower(filter))
 			case BatteryField:
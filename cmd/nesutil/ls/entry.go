@@ -3,21 +3,42 @@ package ls
 import "gabe565.com/gones/internal/cartridge"
 
 func newEntry(file string, cart *cartridge.Cartridge) *entry {
-	return &entry{
-		Path:    file,
-		Name:    cart.Name(),
-		Mapper:  cart.Header.Mapper(),
-		Mirror:  cart.Mirror.String(),
-		Battery: cart.Battery,
-		Hash:    cart.Hash(),
+	e := &entry{
+		Path:      file,
+		Name:      cart.Name(),
+		Mapper:    cart.Header.Mapper(),
+		Submapper: cart.Header.Submapper(),
+		Mirror:    cart.Mirror.String(),
+		Battery:   cart.Battery,
+		NESv2:     cart.Header.NESv2(),
+		Console:   cart.Header.ConsoleType().String(),
+		Hash:      cart.Hash(),
 	}
+	if e.NESv2 {
+		e.Timing = cart.Header.Timing().String()
+		e.PRGRAM = cart.Header.PRGRAMSize()
+		e.PRGNVRAM = cart.Header.PRGNVRAMSize()
+		e.CHRRAM = cart.Header.CHRRAMSize()
+		e.CHRNVRAM = cart.Header.CHRNVRAMSize()
+		e.Expansion = cart.Header.ExpansionDevice().String()
+	}
+	return e
 }
 
 type entry struct {
-	Path    string `json:"path" yaml:"path"`
-	Name    string `json:"name" yaml:"name"`
-	Mapper  uint8  `json:"mapper" yaml:"mapper"`
-	Mirror  string `json:"mirror" yaml:"mirror"`
-	Battery bool   `json:"battery" yaml:"battery"`
-	Hash    string `json:"hash" yaml:"hash"`
+	Path      string `json:"path" yaml:"path"`
+	Name      string `json:"name" yaml:"name"`
+	Mapper    uint16 `json:"mapper" yaml:"mapper"`
+	Submapper uint8  `json:"submapper" yaml:"submapper"`
+	Mirror    string `json:"mirror" yaml:"mirror"`
+	Battery   bool   `json:"battery" yaml:"battery"`
+	NESv2     bool   `json:"nes2" yaml:"nes2"`
+	Console   string `json:"console" yaml:"console"`
+	Timing    string `json:"timing,omitempty" yaml:"timing,omitempty"`
+	PRGRAM    int    `json:"prgRam,omitempty" yaml:"prgRam,omitempty"`
+	PRGNVRAM  int    `json:"prgNvram,omitempty" yaml:"prgNvram,omitempty"`
+	CHRRAM    int    `json:"chrRam,omitempty" yaml:"chrRam,omitempty"`
+	CHRNVRAM  int    `json:"chrNvram,omitempty" yaml:"chrNvram,omitempty"`
+	Expansion string `json:"expansion,omitempty" yaml:"expansion,omitempty"`
+	Hash      string `json:"hash" yaml:"hash"`
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"text/tabwriter"
 
 	"gopkg.in/yaml.v3"
@@ -47,17 +48,27 @@ func printEntries(out io.Writer, carts []*entry, format OutputFormat) error {
 
 func printTable(out io.Writer, carts []*entry) error {
 	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', 0)
-	if _, err := fmt.Fprintln(w, "FILE\tNAME\tMAPPER\tMIRROR\tBATTERY\tHASH\t"); err != nil {
+	if _, err := fmt.Fprintln(w, "FILE\tNAME\tMAPPER\tMIRROR\tBATTERY\tTIMING\tHASH\t"); err != nil {
 		return err
 	}
 
 	for _, entry := range carts {
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%t\t%s\t\n",
+		mapper := strconv.Itoa(int(entry.Mapper))
+		if entry.Submapper != 0 {
+			mapper += "." + strconv.Itoa(int(entry.Submapper))
+		}
+		timing := entry.Timing
+		if timing == "" {
+			timing = "-"
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%s\t%s\t\n",
 			entry.Path,
 			entry.Name,
-			entry.Mapper,
+			mapper,
 			entry.Mirror,
 			entry.Battery,
+			timing,
 			entry.Hash,
 		)
 	}
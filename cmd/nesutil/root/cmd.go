@@ -2,6 +2,7 @@ package root
 
 import (
 	"gabe565.com/gones/cmd/nesutil/chr"
+	"gabe565.com/gones/cmd/nesutil/disasm"
 	"gabe565.com/gones/cmd/nesutil/genie"
 	"gabe565.com/gones/cmd/nesutil/ines"
 	"gabe565.com/gones/cmd/nesutil/ls"
@@ -17,7 +18,7 @@ func New(opts ...options.Option) *cobra.Command {
 		SilenceErrors:     true,
 		DisableAutoGenTag: true,
 	}
-	cmd.AddCommand(ls.New(), ines.New(), chr.New(), genie.New())
+	cmd.AddCommand(ls.New(), ines.New(), chr.New(), genie.New(), disasm.New())
 
 	for _, opt := range opts {
 		opt(cmd)
@@ -3,12 +3,16 @@ package apu
 import (
 	"log/slog"
 	"math"
+	"slices"
+	"time"
 
+	"gabe565.com/gones/internal/cartridge"
 	"gabe565.com/gones/internal/config"
 	"gabe565.com/gones/internal/consts"
 	"gabe565.com/gones/internal/interrupt"
 	"gabe565.com/gones/internal/log"
 	"gabe565.com/gones/internal/memory"
+	"gabe565.com/gones/internal/region"
 )
 
 type CPU interface {
@@ -16,10 +20,11 @@ type CPU interface {
 	interrupt.Stall
 }
 
-const (
-	FrameCounterRate  = float64(consts.CPUFrequency) / 240.0
-	DefaultSampleRate = float64(consts.CPUFrequency) / float64(consts.AudioSampleRate) * consts.FrameRateDiff
-)
+// DefaultSampleRate returns the number of CPU cycles per output sample.
+// The rate is scaled to match the frame rate that games are presented at.
+func DefaultSampleRate(r region.Region) float64 {
+	return r.CPUFrequency() / float64(consts.AudioSampleRate) * float64(r.TargetFrameRate()) / r.FrameRate()
+}
 
 //nolint:gochecknoglobals
 var (
@@ -51,27 +56,74 @@ func init() { //nolint:all
 	}
 }
 
-func New(conf *config.Config) *APU {
+func New(conf *config.Config, r region.Region) *APU {
 	a := &APU{
-		Enabled:    true,
-		SampleRate: DefaultSampleRate,
-		conf:       &conf.Audio,
-		buf:        newRingBuffer(int(conf.Audio.BufferSize)),
+		Enabled:          true,
+		Volume:           1,
+		SampleRate:       DefaultSampleRate(r),
+		recordRate:       DefaultSampleRate(r),
+		conf:             &conf.Audio,
+		buf:              newRingBuffer(int(conf.Audio.BufferSize)),
+		mix:              newSynth(consts.AudioSampleRate),
+		recordMix:        newSynth(consts.AudioSampleRate),
+		frameCounterRate: r.FrameCounterRate(),
+
+		expansionMix: conf.Audio.ExpansionMix(),
 
 		Square: [2]Square{{Channel1: true}, {}},
-		Noise:  Noise{ShiftRegister: 1},
+		Noise:  Noise{ShiftRegister: 1, periods: &noisePeriodTable},
+		DMC:    DMC{periods: &dmcPeriodTable},
 
 		FramePeriod: 4,
 	}
+	for i := range a.stems {
+		a.stems[i] = newSynth(consts.AudioSampleRate)
+	}
+	if r == region.PAL {
+		a.Noise.periods = &noisePeriodTablePAL
+		a.DMC.periods = &dmcPeriodTablePAL
+	}
 	return a
 }
 
+// SampleRecorder receives every output sample, including samples that are not played.
+type SampleRecorder interface {
+	WriteSample(sample float32)
+}
+
+// ChannelRecorder is a [SampleRecorder] that also receives the output of each channel, mixed on its own.
+// Channels are in the order of [APU.ChannelLevels].
+type ChannelRecorder interface {
+	SampleRecorder
+	WriteChannels(channels [5]float32)
+}
+
 type APU struct {
-	Enabled    bool    `msgpack:"-"`
+	Enabled bool `msgpack:"-"`
+	// Volume scales every output sample. It is used to fade out NSF tracks.
+	Volume float32 `msgpack:"-"`
+	// SampleRate is the number of CPU cycles per played sample.
+	// It is changed by fast-forward, and nudged to keep the audio buffer near its latency target.
 	SampleRate float64 `msgpack:"-"`
 	conf       *config.Audio
 	buf        *ringBuffer
-	sample     float32
+	mix        synth
+	// offset is the position between the current and next output sample, between 0 and 1
+	offset float64
+
+	// recorders receive samples even while audio output is disabled.
+	// They have their own output at the default sample rate, so changes to SampleRate never reach recordings.
+	recorders    []SampleRecorder
+	recordRate   float64
+	recordMix    synth
+	recordOffset float64
+	// stems are the output of each channel on its own, if any recorder needs them
+	stems          [5]synth
+	recordChannels bool
+
+	frameCounterRate float64
+
+	expansionMix cartridge.AudioMix
 
 	Square   [2]Square
 	Triangle Triangle
@@ -158,28 +210,45 @@ func (a *APU) Reset() {
 	a.WriteMem(0x4015, 0)
 }
 
-func (a *APU) Step() bool {
+// Step clocks the APU by one CPU cycle.
+// If the cartridge has expansion audio, it is mixed into the output. The console clocks it separately.
+func (a *APU) Step(expansion cartridge.MapperAudio) bool {
 	cycle1 := float64(a.Cycle)
 	a.Cycle++
 	cycle2 := float64(a.Cycle)
 
 	a.stepTimer()
 
-	f1 := uint32(cycle1 / FrameCounterRate)
-	f2 := uint32(cycle2 / FrameCounterRate)
+	f1 := uint32(cycle1 / a.frameCounterRate)
+	f2 := uint32(cycle2 / a.frameCounterRate)
 	if f1 != f2 {
 		a.stepFrameCounter()
 	}
 
 	if a.Enabled {
-		a.sample += a.output()
-
-		s1 := uint32(cycle1 / a.SampleRate)
-		s2 := uint32(cycle2 / a.SampleRate)
-		if s1 != s2 {
+		a.offset += 1 / a.SampleRate
+		if a.offset >= 1 {
+			a.offset--
 			a.sendSample()
 		}
 	}
+	recording := len(a.recorders) != 0
+	if recording {
+		a.recordOffset += 1 / a.recordRate
+		if a.recordOffset >= 1 {
+			a.recordOffset--
+			a.sendRecorders()
+		}
+	}
+	if a.Enabled || recording {
+		out := a.output(expansion)
+		if a.Enabled {
+			a.mix.update(out, a.offset)
+		}
+		if recording {
+			a.recordMix.update(out, a.recordOffset)
+		}
+	}
 
 	return a.IRQPending || a.DMC.IRQPending
 }
@@ -237,39 +306,115 @@ func (a *APU) stepLength() {
 	a.Noise.stepLength()
 }
 
-func (a *APU) output() float32 {
+func (a *APU) output(expansion cartridge.MapperAudio) float32 {
+	square1, square2 := a.Square[0].output(), a.Square[1].output()
+	triangle, noise, dmc := a.Triangle.output(), a.Noise.output(), a.DMC.output()
+	if a.recordChannels {
+		a.stems[0].update(squareTable[square1], a.recordOffset)
+		a.stems[1].update(squareTable[square2], a.recordOffset)
+		a.stems[2].update(tndTable[3*triangle], a.recordOffset)
+		a.stems[3].update(tndTable[2*noise], a.recordOffset)
+		a.stems[4].update(tndTable[dmc], a.recordOffset)
+	}
+
 	var square byte
 	if a.conf.Channels.Square1 {
-		square += a.Square[0].output()
+		square += square1
 	}
 	if a.conf.Channels.Square2 {
-		square += a.Square[1].output()
+		square += square2
 	}
 
 	var tnd byte
 	if a.conf.Channels.Triangle {
-		tnd += 3 * a.Triangle.output()
+		tnd += 3 * triangle
 	}
 	if a.conf.Channels.Noise {
-		tnd += 2 * a.Noise.output()
+		tnd += 2 * noise
 	}
 	if a.conf.Channels.PCM {
-		tnd += a.DMC.output()
+		tnd += dmc
 	}
 
-	return squareTable[square] + tndTable[tnd]
+	out := squareTable[square] + tndTable[tnd]
+	if expansion != nil {
+		out += expansion.Audio(&a.expansionMix)
+	}
+	return out
+}
+
+// ChannelLevels returns the current output of the pulse, triangle, noise, and DMC channels, between 0 and 1.
+func (a *APU) ChannelLevels() [5]float32 {
+	return [5]float32{
+		float32(a.Square[0].output()) / 15,
+		float32(a.Square[1].output()) / 15,
+		float32(a.Triangle.output()) / 15,
+		float32(a.Noise.output()) / 15,
+		float32(a.DMC.output()) / 127,
+	}
 }
 
 func (a *APU) sendSample() {
-	result := a.sample / float32(a.SampleRate)
-	a.sample = 0
-	b := math.Float32bits(result)
+	b := math.Float32bits(a.mix.read() * a.Volume)
 	a.buf.Write([]byte{
 		byte(b), byte(b >> 8), byte(b >> 16), byte(b >> 24),
 		byte(b), byte(b >> 8), byte(b >> 16), byte(b >> 24),
 	})
 }
 
+// sendRecorders sends a sample to each recorder, along with each channel's sample if they are recorded.
+func (a *APU) sendRecorders() {
+	sample := a.recordMix.read() * a.Volume
+	var channels [5]float32
+	if a.recordChannels {
+		for i := range a.stems {
+			channels[i] = a.stems[i].read() * a.Volume
+		}
+	}
+
+	for _, r := range a.recorders {
+		r.WriteSample(sample)
+		if r, ok := r.(ChannelRecorder); ok {
+			r.WriteChannels(channels)
+		}
+	}
+}
+
+// AddRecorder starts sending samples to r.
+func (a *APU) AddRecorder(r SampleRecorder) {
+	a.recorders = append(a.recorders, r)
+	a.updateRecordChannels()
+}
+
+// RemoveRecorder stops sending samples to r.
+func (a *APU) RemoveRecorder(r SampleRecorder) {
+	a.recorders = slices.DeleteFunc(a.recorders, func(v SampleRecorder) bool {
+		return v == r
+	})
+	a.updateRecordChannels()
+}
+
+func (a *APU) updateRecordChannels() {
+	a.recordChannels = slices.ContainsFunc(a.recorders, func(r SampleRecorder) bool {
+		_, ok := r.(ChannelRecorder)
+		return ok
+	})
+}
+
+// Buffered returns the duration of audio that is waiting to be played.
+func (a *APU) Buffered() time.Duration {
+	return samplesDuration(a.buf.len())
+}
+
+// BufferCapacity returns the duration of audio that fits in the buffer.
+func (a *APU) BufferCapacity() time.Duration {
+	return samplesDuration(a.buf.size)
+}
+
+func samplesDuration(bytes int) time.Duration {
+	return time.Duration(bytes/consts.AudioBytesPerSample) * time.Second / consts.AudioSampleRate
+}
+
 func (a *APU) Clear() {
 	a.buf.Reset()
 }
@@ -0,0 +1,52 @@
+package apu
+
+import (
+	"testing"
+	"time"
+
+	"gabe565.com/gones/internal/config"
+	"gabe565.com/gones/internal/consts"
+	"gabe565.com/gones/internal/region"
+	"github.com/stretchr/testify/assert"
+)
+
+type countRecorder int
+
+func (c *countRecorder) WriteSample(float32) { *c++ }
+
+func TestAPU_Recorder(t *testing.T) {
+	a := New(config.NewDefault(), region.NTSC)
+	var rec countRecorder
+	a.AddRecorder(&rec)
+
+	// Fast-forward plays fewer samples, but recordings stay at the default rate
+	a.SampleRate *= 3
+	cycles := int(DefaultSampleRate(region.NTSC) * consts.AudioSampleRate / 10)
+	for range cycles {
+		a.Step(nil)
+	}
+	assert.InDelta(t, consts.AudioSampleRate/10, int(rec), 1)
+}
+
+func TestAPU_Buffered(t *testing.T) {
+	conf := config.NewDefault()
+	conf.Audio.BufferSize = config.Bytes(consts.AudioSampleRate * consts.AudioBytesPerSample)
+	a := New(conf, region.NTSC)
+	var rec countRecorder
+	a.AddRecorder(&rec)
+
+	// Playback is slowed down, but recordings stay at the default rate
+	a.SampleRate *= 1.005
+	cycles := int(DefaultSampleRate(region.NTSC) * consts.AudioSampleRate / 10)
+	for range cycles {
+		a.Step(nil)
+	}
+
+	assert.InDelta(t, consts.AudioSampleRate/10, int(rec), 1)
+	assert.InDelta(t, float64(100*time.Millisecond)/1.005, float64(a.Buffered()), float64(time.Millisecond))
+	assert.Equal(t, time.Second, a.BufferCapacity())
+
+	a.RemoveRecorder(&rec)
+	a.Step(nil)
+	assert.InDelta(t, consts.AudioSampleRate/10, int(rec), 1)
+}
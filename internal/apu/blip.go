@@ -0,0 +1,80 @@
+package apu
+
+import "math"
+
+// Band-limited step synthesis, based on blargg's blip_buf.
+//
+// Instead of sampling the output, each change in amplitude is added as a band-limited step at its exact position
+// between two output samples. The steps are stored as impulses, and integrating them gives the output.
+// This removes the aliasing of high-pitched square and noise channels, and only costs work when the output changes.
+
+const (
+	// blipPhases is the number of positions between two output samples that a step can be placed at.
+	blipPhases = 32
+	// blipWidth is the number of output samples that each step is spread across.
+	blipWidth = 16
+	// blipCutoff is the cutoff of the impulse as a fraction of the Nyquist frequency.
+	// It is slightly below 1 so that frequencies near the Nyquist frequency do not alias.
+	blipCutoff = 0.9
+	// blipMask wraps positions in the buffer. The buffer is a power of two that fits one step.
+	blipMask = 2*blipWidth - 1
+)
+
+//nolint:gochecknoglobals
+var blipKernel [blipPhases][blipWidth]float32
+
+func init() { //nolint:all
+	for phase := range blipKernel {
+		// The center of the impulse moves from sample blipWidth/2-1 to blipWidth/2 across the phases
+		center := blipWidth/2 - 1 + float64(phase)/blipPhases
+		var sum float64
+		var impulse [blipWidth]float64
+		for i := range impulse {
+			x := float64(i) - center
+			// Windowed sinc
+			v := blipCutoff
+			if x != 0 {
+				v = math.Sin(math.Pi*blipCutoff*x) / (math.Pi * x)
+			}
+			// Blackman window across the width of the step
+			w := 0.42 + 0.5*math.Cos(2*math.Pi*x/blipWidth) + 0.08*math.Cos(4*math.Pi*x/blipWidth)
+			impulse[i] = v * w
+			sum += impulse[i]
+		}
+		// Each step must add exactly its delta to the output
+		for i, v := range impulse {
+			blipKernel[phase][i] = float32(v / sum)
+		}
+	}
+}
+
+// blip turns changes in amplitude into band-limited output samples.
+type blip struct {
+	buf        [blipMask + 1]float32
+	pos        int
+	amplitude  float32
+	integrator float32
+}
+
+// update sets the amplitude at a position between the current and next output sample, between 0 and 1.
+func (b *blip) update(amplitude float32, offset float64) {
+	delta := amplitude - b.amplitude
+	if delta == 0 {
+		return
+	}
+	b.amplitude = amplitude
+
+	kernel := &blipKernel[min(int(offset*blipPhases), blipPhases-1)]
+	for i, v := range kernel {
+		b.buf[(b.pos+i)&blipMask] += delta * v
+	}
+}
+
+// read returns the current output sample, then moves to the next one.
+// The output is delayed by half of blipWidth.
+func (b *blip) read() float32 {
+	b.integrator += b.buf[b.pos]
+	b.buf[b.pos] = 0
+	b.pos = (b.pos + 1) & blipMask
+	return b.integrator
+}
@@ -0,0 +1,50 @@
+package apu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_blipKernel(t *testing.T) {
+	for phase, kernel := range blipKernel {
+		var sum float32
+		for _, v := range kernel {
+			sum += v
+		}
+		assert.InDelta(t, 1, sum, 1e-6, "phase %d", phase)
+	}
+}
+
+func Test_blip(t *testing.T) {
+	var b blip
+	b.update(1, 0.5)
+	b.update(1, 0.75)
+
+	var last float32
+	for i := range 2 * blipWidth {
+		last = b.read()
+		if i < blipWidth/4 {
+			assert.InDelta(t, 0, last, 0.01, "output is delayed by half of the width")
+		}
+	}
+	assert.InDelta(t, 1, last, 1e-6, "step settles at its amplitude")
+
+	b.update(0.25, 0)
+	for range 2 * blipWidth {
+		last = b.read()
+	}
+	assert.InDelta(t, 0.25, last, 1e-6)
+}
+
+func Test_filter(t *testing.T) {
+	high := newHighPass(44100, 90)
+	low := newLowPass(44100, 14000)
+	var hv, lv float32
+	for range 44100 {
+		hv = high.step(1)
+		lv = low.step(1)
+	}
+	assert.InDelta(t, 0, hv, 1e-3, "high-pass removes DC")
+	assert.InDelta(t, 1, lv, 1e-3, "low-pass passes DC")
+}
@@ -1,9 +1,14 @@
 package apu
 
 //nolint:gochecknoglobals
-var dmcPeriodTable = [...]byte{
-	214, 190, 170, 160, 143, 127, 113, 107, 95, 80, 71, 64, 53, 42, 36, 27,
-}
+var (
+	dmcPeriodTable = [16]byte{
+		214, 190, 170, 160, 143, 127, 113, 107, 95, 80, 71, 64, 53, 42, 36, 27,
+	}
+	dmcPeriodTablePAL = [16]byte{
+		199, 177, 158, 149, 138, 118, 105, 99, 88, 74, 66, 59, 49, 39, 33, 25,
+	}
+)
 
 type DMC struct {
 	Enabled bool
@@ -13,6 +18,7 @@ type DMC struct {
 	IRQPending bool `msgpack:"alias:IrqPending"`
 	Loop       bool
 
+	periods    *[16]byte
 	TickPeriod byte
 	TickValue  byte
 
@@ -34,7 +40,7 @@ func (d *DMC) Write(addr uint16, data byte) {
 			d.IRQPending = false
 		}
 		d.Loop = data>>6&1 == 1
-		d.TickPeriod = dmcPeriodTable[data&0xF]
+		d.TickPeriod = d.periods[data&0xF]
 	case 0x4011:
 		d.Value = data & 0x7F
 	case 0x4012:
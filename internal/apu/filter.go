@@ -0,0 +1,66 @@
+package apu
+
+import "math"
+
+// filter is a first-order high-pass or low-pass filter.
+type filter struct {
+	alpha float32
+	high  bool
+	prevX float32
+	prevY float32
+}
+
+// newHighPass returns a high-pass filter for the given sample rate and cutoff frequency.
+func newHighPass(rate, cutoff float64) filter {
+	rc := 1 / (2 * math.Pi * cutoff)
+	dt := 1 / rate
+	return filter{alpha: float32(rc / (rc + dt)), high: true}
+}
+
+// newLowPass returns a low-pass filter for the given sample rate and cutoff frequency.
+func newLowPass(rate, cutoff float64) filter {
+	rc := 1 / (2 * math.Pi * cutoff)
+	dt := 1 / rate
+	return filter{alpha: float32(dt / (rc + dt))}
+}
+
+func (f *filter) step(x float32) float32 {
+	if f.high {
+		f.prevY = f.alpha * (f.prevY + x - f.prevX)
+	} else {
+		f.prevY += f.alpha * (x - f.prevY)
+	}
+	f.prevX = x
+	return f.prevY
+}
+
+// synth is a band-limited output followed by the filters of the NES's audio output.
+// See https://www.nesdev.org/wiki/APU_Mixer
+type synth struct {
+	blip    blip
+	filters [3]filter
+}
+
+func newSynth(rate float64) synth {
+	return synth{
+		filters: [3]filter{
+			newHighPass(rate, 90),
+			newHighPass(rate, 440),
+			newLowPass(rate, 14000),
+		},
+	}
+}
+
+// update sets the amplitude at a position between the current and next output sample, between 0 and 1.
+func (s *synth) update(amplitude float32, offset float64) {
+	s.blip.update(amplitude, offset)
+}
+
+// read returns the current filtered output sample, then moves to the next one.
+func (s *synth) read() float32 {
+	v := s.blip.read()
+	for i := range s.filters {
+		v = s.filters[i].step(v)
+	}
+	return v
+}
@@ -1,9 +1,14 @@
 package apu
 
 //nolint:gochecknoglobals
-var noisePeriodTable = [...]uint16{
-	4, 8, 16, 32, 64, 96, 128, 160, 202, 254, 380, 508, 762, 1016, 2034, 4068,
-}
+var (
+	noisePeriodTable = [16]uint16{
+		4, 8, 16, 32, 64, 96, 128, 160, 202, 254, 380, 508, 762, 1016, 2034, 4068,
+	}
+	noisePeriodTablePAL = [16]uint16{
+		4, 8, 14, 30, 60, 88, 118, 148, 188, 236, 354, 472, 708, 944, 1890, 3778,
+	}
+)
 
 type Noise struct {
 	Enabled bool
@@ -20,6 +25,7 @@ type Noise struct {
 	LoopNoise     bool
 	ShiftRegister uint16
 
+	periods     *[16]uint16
 	TimerPeriod uint16
 	TimerValue  uint16
 
@@ -37,7 +43,7 @@ func (n *Noise) Write(addr uint16, data byte) {
 		n.Volume = data & 0xF
 	case 0x400E:
 		n.LoopNoise = data>>7&1 == 1
-		n.TimerPeriod = noisePeriodTable[data&0xF]
+		n.TimerPeriod = n.periods[data&0xF]
 	case 0x400F:
 		if n.Enabled {
 			n.LengthValue = lengthTable[data>>3&0x1F]
@@ -5,34 +5,57 @@ import (
 
 	"gabe565.com/gones/internal/apu"
 	"gabe565.com/gones/internal/cartridge"
+	"gabe565.com/gones/internal/cheat"
 	"gabe565.com/gones/internal/config"
 	"gabe565.com/gones/internal/controller"
 	"gabe565.com/gones/internal/log"
+	"gabe565.com/gones/internal/memory"
 	"gabe565.com/gones/internal/ppu"
 )
 
-func New(conf *config.Config, mapper cartridge.Mapper, ppu *ppu.PPU, apu *apu.APU) *Bus {
+func New(conf *config.Config, mapper cartridge.Mapper, ppu *ppu.PPU, apu *apu.APU, screen controller.Screen) *Bus {
+	gamepads := &controller.Gamepads{}
 	return &Bus{
-		mapper:      mapper,
-		apu:         apu,
-		ppu:         ppu,
-		controller1: controller.NewController(conf, controller.Player1),
-		controller2: controller.NewController(conf, controller.Player2),
+		mapper:   mapper,
+		apu:      apu,
+		ppu:      ppu,
+		gamepads: gamepads,
+		ports:    controller.NewPorts(conf, mapper.Cartridge(), gamepads, screen),
 	}
 }
 
 type Bus struct {
-	CPUVRAM     [0x800]byte `msgpack:"alias:CpuVram"`
-	mapper      cartridge.Mapper
-	apu         *apu.APU
-	ppu         *ppu.PPU
-	controller1 controller.Controller
-	controller2 controller.Controller
-	OpenBus     byte
+	CPUVRAM  [0x800]byte `msgpack:"alias:CpuVram"`
+	mapper   cartridge.Mapper
+	apu      *apu.APU
+	ppu      *ppu.PPU
+	gamepads *controller.Gamepads
+	ports    controller.Ports
+	cheats   *cheat.Engine
+	watcher  memory.Watcher
+	OpenBus  byte
+}
+
+// SetCheats sets the cheats applied to CPU reads. A nil engine disables cheats.
+func (b *Bus) SetCheats(cheats *cheat.Engine) {
+	b.cheats = cheats
+}
+
+// SetWatcher sets a watcher that is notified of every CPU read and write. A nil watcher disables notifications.
+func (b *Bus) SetWatcher(w memory.Watcher) {
+	b.watcher = w
 }
 
 // ReadMem reads a byte from memory.
 func (b *Bus) ReadMem(addr uint16) byte {
+	data := b.readMem(addr)
+	if b.watcher != nil {
+		b.watcher.OnRead(addr, data)
+	}
+	return data
+}
+
+func (b *Bus) readMem(addr uint16) byte {
 	switch {
 	case addr < 0x2000:
 		addr &= 0x07FF
@@ -44,12 +67,10 @@ func (b *Bus) ReadMem(addr uint16) byte {
 		return b.ppu.ReadMem(addr)
 	case 0x4000 <= addr && addr < 0x4016:
 		b.OpenBus = b.apu.ReadMem(addr)
-	case addr == 0x4016:
-		b.OpenBus &^= 0xF
-		b.OpenBus |= b.controller1.Read()
-	case addr == 0x4017:
-		b.OpenBus &^= 0xF
-		b.OpenBus |= b.controller2.Read()
+	case addr == 0x4016, addr == 0x4017:
+		// Devices drive D0-D4. The upper bits are open bus.
+		b.OpenBus &^= 0x1F
+		b.OpenBus |= b.ports.Read(int(addr-0x4016)) & 0x1F
 	case addr <= 0x4018 && addr < 0x4020:
 		// Disabled test registers
 	case 0x4020 <= addr:
@@ -58,23 +79,41 @@ func (b *Bus) ReadMem(addr uint16) byte {
 		slog.Error("Invalid Bus read", "addr", log.HexAddr(addr))
 		return 0
 	}
+	if b.cheats != nil {
+		b.OpenBus = b.cheats.Read(addr, b.OpenBus)
+	}
 	return b.OpenBus
 }
 
-// ReadMemSafe reads a byte from memory, but immediately returns 0xFF for any reads with side effects.
+// ReadMemSafe reads a byte from memory without side effects, and without updating open bus or applying cheats.
+// Registers with read side effects return 0xFF, unless the mapper implements [memory.ReadSafe].
 func (b *Bus) ReadMemSafe(addr uint16) byte {
 	switch {
 	case 0x2001 <= addr && addr < 0x4000,
 		0x4004 <= addr && addr <= 0x4007,
 		0x4015 <= addr && addr <= 0x4017:
 		return 0xFF
+	case addr < 0x2000:
+		return b.CPUVRAM[addr&0x07FF]
+	case addr == 0x2000, addr == 0x4014:
+		return b.ppu.ReadMem(addr)
+	case 0x4000 <= addr && addr < 0x4015:
+		return b.apu.ReadMem(addr)
+	case addr < 0x4020:
+		return b.OpenBus
 	default:
-		return b.ReadMem(addr)
+		if mapper, ok := b.mapper.(memory.ReadSafe); ok {
+			return mapper.ReadMemSafe(addr)
+		}
+		return b.mapper.ReadMem(addr)
 	}
 }
 
 // WriteMem writes a byte to memory.
 func (b *Bus) WriteMem(addr uint16, data byte) {
+	if b.watcher != nil {
+		b.watcher.OnWrite(addr, data)
+	}
 	switch {
 	case addr < 0x2000:
 		addr &= 0x07FF
@@ -89,8 +128,7 @@ func (b *Bus) WriteMem(addr uint16, data byte) {
 	case 0x4000 <= addr && addr <= 0x4013, addr == 0x4015, addr == 0x4017:
 		b.apu.WriteMem(addr, data)
 	case addr == 0x4016:
-		b.controller1.Write(data)
-		b.controller2.Write(data)
+		b.ports.Write(data)
 	case addr <= 0x4018 && addr < 0x4020:
 		// Disabled test registers
 	case 0x4020 <= addr:
@@ -117,8 +155,37 @@ func (b *Bus) WriteMem16(addr uint16, data uint16) {
 }
 
 func (b *Bus) UpdateInput() {
-	b.controller1.UpdateInput()
-	b.controller2.UpdateInput()
+	b.gamepads.Update()
+	b.ports.UpdateInput()
+}
+
+// Ports returns the devices connected to $4016 and $4017.
+func (b *Bus) Ports() controller.Ports { //nolint:ireturn
+	return b.ports
+}
+
+// Buttons returns the pressed buttons for each player.
+// Players 3 and 4 are only set with a four player adapter.
+// Ports without a standard controller report no buttons.
+func (b *Bus) Buttons() [4]byte {
+	var v [4]byte
+	devices := b.ports.Devices()
+	for i, port := range devices[:min(len(devices), len(v))] {
+		if port, ok := port.(controller.ButtonDevice); ok {
+			v[i] = port.Buttons()
+		}
+	}
+	return v
+}
+
+// SetButtons overrides the pressed buttons for each player.
+func (b *Bus) SetButtons(v [4]byte) {
+	devices := b.ports.Devices()
+	for i, port := range devices[:min(len(devices), len(v))] {
+		if port, ok := port.(controller.ButtonDevice); ok {
+			port.SetButtons(v[i])
+		}
+	}
 }
 
 func (b *Bus) SetMapper(m cartridge.Mapper) {
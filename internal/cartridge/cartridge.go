@@ -22,6 +22,11 @@ type Cartridge struct {
 	SRAM    []byte `msgpack:"alias:Sram"`
 	Mirror  Mirror
 	Battery bool `msgpack:"-"`
+	// Disk is the inserted Famicom Disk System disk, or nil for cartridges.
+	// Its sides are saved in state so disk writes survive loads and rewinds.
+	Disk *Disk
+	// NSF is the loaded music file, or nil for cartridges.
+	NSF *NSF `msgpack:"-"`
 }
 
 func New() *Cartridge {
@@ -51,6 +56,23 @@ func FromBytes(b []byte) *Cartridge {
 	return cart
 }
 
+// ReadSRAM reads from PRG-RAM. RAM smaller than the mapper's window is mirrored,
+// and reads return 0 when the cartridge has no RAM.
+func (c *Cartridge) ReadSRAM(addr uint16) byte {
+	if len(c.SRAM) == 0 {
+		return 0
+	}
+	return c.SRAM[int(addr)%len(c.SRAM)]
+}
+
+// WriteSRAM writes to PRG-RAM. Writes are ignored when the cartridge has no RAM.
+func (c *Cartridge) WriteSRAM(addr uint16, data byte) {
+	if len(c.SRAM) == 0 {
+		return
+	}
+	c.SRAM[int(addr)%len(c.SRAM)] = data
+}
+
 func (c *Cartridge) Name() string {
 	return c.name
 }
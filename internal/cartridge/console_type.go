@@ -0,0 +1,17 @@
+package cartridge
+
+//go:generate go tool stringer -type ConsoleType -trimprefix Console -linecomment
+
+// ConsoleType is the console a ROM was made for.
+type ConsoleType byte
+
+const (
+	ConsoleNES          ConsoleType = iota // NES/Famicom
+	ConsoleVsSystem                        // Vs. System
+	ConsolePlayChoice10                    // PlayChoice-10
+	ConsoleFamiclone                       // Famiclone with decimal mode
+	ConsoleEPSM                            // NES/Famicom with EPSM
+
+	// consoleExtended is the basic console type which signals that the extended console type should be used.
+	consoleExtended = ConsoleFamiclone
+)
@@ -0,0 +1,24 @@
+package cartridge
+
+//go:generate go tool stringer -type ExpansionDevice -trimprefix Expansion -linecomment
+
+// ExpansionDevice is the default input device declared by a NES 2.0 header.
+//
+// See [Default Expansion Device].
+//
+// [Default Expansion Device]: https://www.nesdev.org/wiki/NES_2.0#Default_Expansion_Device
+type ExpansionDevice byte
+
+const (
+	ExpansionUnspecified     ExpansionDevice = 0x00 // Unspecified
+	ExpansionStandard        ExpansionDevice = 0x01 // Standard controllers
+	ExpansionFourScore       ExpansionDevice = 0x02 // Four Score
+	ExpansionFourPlayers     ExpansionDevice = 0x03 // Famicom Four Players Adapter
+	ExpansionVsSystem4016    ExpansionDevice = 0x04 // Vs. System ($4016)
+	ExpansionVsSystem4017    ExpansionDevice = 0x05 // Vs. System ($4017)
+	ExpansionVsZapper        ExpansionDevice = 0x07 // Vs. Zapper
+	ExpansionZapper          ExpansionDevice = 0x08 // Zapper
+	ExpansionDoubleZapper    ExpansionDevice = 0x09 // Two Zappers
+	ExpansionArkanoidNES     ExpansionDevice = 0x0F // Arkanoid Vaus (NES)
+	ExpansionArkanoidFamicom ExpansionDevice = 0x10 // Arkanoid Vaus (Famicom)
+)
@@ -0,0 +1,233 @@
+package cartridge
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"gabe565.com/gones/internal/consts"
+	"gabe565.com/gones/internal/database"
+	"gabe565.com/gones/internal/ips"
+)
+
+const (
+	// fdsMapper is the iNES mapper number reserved for the Famicom Disk System.
+	fdsMapper = 20
+	// fdsHeaderSize is the size of the optional fwNES header.
+	fdsHeaderSize = 16
+	// fdsSideSize is the size of one disk side in a .fds image.
+	fdsSideSize = 65500
+	// fdsBIOSSize is the size of the FDS BIOS ROM.
+	fdsBIOSSize = 0x2000
+
+	// fdsLeadIn is the gap before the first block, in bytes.
+	fdsLeadIn = 28300 / 8
+	// fdsBlockGap is the gap after each block, in bytes.
+	fdsBlockGap = 976 / 8
+	// fdsBlockMark is the byte that ends a gap and starts a block.
+	fdsBlockMark = 0x80
+)
+
+//nolint:gochecknoglobals
+var (
+	fdsMagic     = []byte{'F', 'D', 'S', 0x1A}
+	fdsVerify    = []byte("*NINTENDO-HVC*")
+	fdsCRCFiller = []byte{0x4D, 0x62}
+)
+
+var ErrInvalidBIOS = errors.New("invalid FDS BIOS")
+
+// Disk is a Famicom Disk System disk.
+type Disk struct {
+	// original is the unmodified .fds file, used to diff disk writes.
+	original []byte
+	header   []byte
+
+	// Sides holds each side as the drive sees it, with gaps, block marks, and CRCs.
+	Sides [][]byte
+}
+
+// IsFDS reports whether b looks like a .fds disk image, with or without the fwNES header.
+func IsFDS(b []byte) bool {
+	if bytes.HasPrefix(b, fdsMagic) {
+		b = b[min(fdsHeaderSize, len(b)):]
+	}
+	return hasDiskInfo(b)
+}
+
+// hasDiskInfo reports whether b starts with a disk info block.
+func hasDiskInfo(b []byte) bool {
+	return len(b) > len(fdsVerify) && b[0] == 1 && bytes.Equal(b[1:1+len(fdsVerify)], fdsVerify)
+}
+
+// FromFDS loads a .fds disk image. The BIOS is mapped to $E000-$FFFF.
+func FromFDS(r io.Reader, bios []byte) (*Cartridge, error) {
+	if len(bios) != fdsBIOSSize {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidBIOS, fdsBIOSSize, len(bios))
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	disk := &Disk{original: b}
+	if bytes.HasPrefix(b, fdsMagic) {
+		if len(b) < fdsHeaderSize {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidROM, "truncated FDS header")
+		}
+		disk.header = b[:fdsHeaderSize]
+	}
+	if disk.Sides, err = disk.parse(b); err != nil {
+		return nil, err
+	}
+
+	cartridge := New()
+	cartridge.Header.SetMapper(fdsMapper)
+	cartridge.Mirror = Vertical
+	cartridge.PRG = bytes.Clone(bios)
+	cartridge.CHR = make([]byte, consts.CHRChunkSize)
+	cartridge.SRAM = make([]byte, 0x8000)
+	cartridge.Disk = disk
+
+	slog.Debug("Loaded FDS image",
+		"sides", len(disk.Sides),
+		"fwnes", disk.header != nil,
+	)
+
+	hash := md5.Sum(b)
+	cartridge.hash = hex.EncodeToString(hash[:])
+	cartridge.name, _ = database.FindNameByHash(cartridge.hash)
+	return cartridge, nil
+}
+
+// parse splits a .fds image into sides and adds the gaps the drive expects.
+func (d *Disk) parse(b []byte) ([][]byte, error) {
+	b = b[len(d.header):]
+	if len(b) < fdsSideSize || !hasDiskInfo(b) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidROM, "missing FDS disk header")
+	}
+
+	sides := make([][]byte, 0, len(b)/fdsSideSize)
+	for i := 0; i+fdsSideSize <= len(b); i += fdsSideSize {
+		sides = append(sides, addGaps(b[i:i+fdsSideSize]))
+	}
+	return sides, nil
+}
+
+// addGaps converts a side from .fds format to the raw format read by the drive.
+// Parsing stops at the first invalid block type.
+func addGaps(side []byte) []byte {
+	raw := make([]byte, fdsLeadIn, fdsSideSize+fdsSideSize/8)
+	for i := 0; i < len(side); {
+		size := blockSize(side, i)
+		if size == 0 || i+size > len(side) {
+			break
+		}
+		raw = append(raw, fdsBlockMark)
+		raw = append(raw, side[i:i+size]...)
+		raw = append(raw, fdsCRCFiller...)
+		raw = append(raw, make([]byte, fdsBlockGap)...)
+		i += size
+	}
+	if len(raw) < fdsSideSize {
+		raw = append(raw, make([]byte, fdsSideSize-len(raw))...)
+	}
+	return raw
+}
+
+// blockSize returns the size of the block at side[i], or 0 if it is not a valid block.
+// File data blocks use the size from the file header block before them.
+func blockSize(side []byte, i int) int {
+	switch side[i] {
+	case 1:
+		return 56
+	case 2:
+		return 2
+	case 3:
+		return 16
+	case 4:
+		if i < 3 {
+			return 0
+		}
+		return 1 + (int(side[i-3]) | int(side[i-2])<<8)
+	default:
+		return 0
+	}
+}
+
+// Image returns the disk in .fds format, including any writes.
+func (d *Disk) Image() []byte {
+	image := bytes.NewBuffer(make([]byte, 0, len(d.original)))
+	image.Write(d.header)
+	for _, raw := range d.Sides {
+		side := make([]byte, 0, fdsSideSize)
+		var fileHeader []byte
+		for i := 0; i < len(raw); i++ {
+			if raw[i] != fdsBlockMark {
+				continue
+			}
+			i++
+			if i >= len(raw) {
+				break
+			}
+
+			var size int
+			switch raw[i] {
+			case 1:
+				size = 56
+			case 2:
+				size = 2
+			case 3:
+				size = 16
+			case 4:
+				if fileHeader != nil {
+					size = 1 + (int(fileHeader[13]) | int(fileHeader[14])<<8)
+				}
+			}
+			if size == 0 || i+size > len(raw) || len(side)+size > fdsSideSize {
+				break
+			}
+
+			block := raw[i : i+size]
+			if block[0] == 3 {
+				fileHeader = block
+			}
+			side = append(side, block...)
+			// Skip the block and its CRC
+			i += size + len(fdsCRCFiller) - 1
+		}
+		image.Write(side)
+		image.Write(make([]byte, fdsSideSize-len(side)))
+	}
+	return image.Bytes()
+}
+
+// Diff returns an IPS patch of all writes made to the disk, relative to the original .fds file.
+// It returns nil if the disk is unchanged.
+func (d *Disk) Diff() ([]byte, error) {
+	image := d.Image()
+	if bytes.Equal(image, d.original) {
+		return nil, nil
+	}
+	return ips.Diff(d.original, image)
+}
+
+// Patch applies an IPS patch created by [Disk.Diff].
+func (d *Disk) Patch(patch []byte) error {
+	b, err := ips.Apply(d.original, patch)
+	if err != nil {
+		return err
+	}
+
+	sides, err := d.parse(b)
+	if err != nil {
+		return err
+	}
+	d.Sides = sides
+	return nil
+}
@@ -0,0 +1,122 @@
+package cartridge
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// newFDSImage creates a disk side with one 4-byte file.
+func newFDSImage(header bool) []byte {
+	side := make([]byte, fdsSideSize)
+	side[0] = 1
+	copy(side[1:], fdsVerify)
+	// File count
+	side[56] = 2
+	side[57] = 1
+	// File header with a 4-byte size
+	side[58] = 3
+	side[58+13] = 4
+	// File data
+	copy(side[74:], []byte{4, 0xDE, 0xAD, 0xBE, 0xEF})
+
+	if !header {
+		return side
+	}
+	return append([]byte{'F', 'D', 'S', 0x1A, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, side...)
+}
+
+func TestFromFDS(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		header bool
+	}{
+		{"headerless", false},
+		{"fwNES header", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			image := newFDSImage(tt.header)
+			require.True(t, IsFDS(image))
+
+			cart, err := FromFDS(bytes.NewReader(image), make([]byte, fdsBIOSSize))
+			require.NoError(t, err)
+			assert.EqualValues(t, 20, cart.Header.Mapper())
+			assert.Len(t, cart.SRAM, 0x8000)
+			require.Len(t, cart.Disk.Sides, 1)
+
+			raw := cart.Disk.Sides[0]
+			assert.Equal(t, make([]byte, fdsLeadIn), raw[:fdsLeadIn])
+			assert.EqualValues(t, fdsBlockMark, raw[fdsLeadIn])
+			assert.EqualValues(t, 1, raw[fdsLeadIn+1])
+
+			assert.Equal(t, image, cart.Disk.Image())
+			patch, err := cart.Disk.Diff()
+			require.NoError(t, err)
+			assert.Nil(t, patch)
+		})
+	}
+}
+
+func TestFromFDS_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := FromFDS(bytes.NewReader(newFDSImage(false)), nil)
+	require.ErrorIs(t, err, ErrInvalidBIOS)
+
+	_, err = FromFDS(bytes.NewReader(make([]byte, fdsSideSize)), make([]byte, fdsBIOSSize))
+	require.ErrorIs(t, err, ErrInvalidROM)
+}
+
+func TestDisk_Patch(t *testing.T) {
+	t.Parallel()
+
+	cart, err := FromFDS(bytes.NewReader(newFDSImage(true)), make([]byte, fdsBIOSSize))
+	require.NoError(t, err)
+
+	// Overwrite the file data on the raw side
+	raw := cart.Disk.Sides[0]
+	i := bytes.Index(raw, []byte{4, 0xDE, 0xAD, 0xBE, 0xEF})
+	require.NotEqual(t, -1, i)
+	copy(raw[i+1:], []byte{1, 2, 3, 4})
+
+	patch, err := cart.Disk.Diff()
+	require.NoError(t, err)
+	require.NotNil(t, patch)
+
+	reloaded, err := FromFDS(bytes.NewReader(newFDSImage(true)), make([]byte, fdsBIOSSize))
+	require.NoError(t, err)
+	require.NoError(t, reloaded.Disk.Patch(patch))
+	assert.Equal(t, cart.Disk.Sides, reloaded.Disk.Sides)
+}
+
+func TestDisk_State(t *testing.T) {
+	t.Parallel()
+
+	cart, err := FromFDS(bytes.NewReader(newFDSImage(true)), make([]byte, fdsBIOSSize))
+	require.NoError(t, err)
+
+	raw := cart.Disk.Sides[0]
+	i := bytes.Index(raw, []byte{4, 0xDE, 0xAD, 0xBE, 0xEF})
+	require.NotEqual(t, -1, i)
+	copy(raw[i+1:], []byte{1, 2, 3, 4})
+
+	state, err := msgpack.Marshal(cart)
+	require.NoError(t, err)
+
+	reloaded, err := FromFDS(bytes.NewReader(newFDSImage(true)), make([]byte, fdsBIOSSize))
+	require.NoError(t, err)
+	require.NoError(t, msgpack.Unmarshal(state, reloaded))
+	assert.Equal(t, cart.Disk.Sides, reloaded.Disk.Sides)
+
+	// The original image is kept so writes can still be diffed
+	patch, err := reloaded.Disk.Diff()
+	require.NoError(t, err)
+	assert.NotNil(t, patch)
+}
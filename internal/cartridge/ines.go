@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math/bits"
 	"os"
 	"path/filepath"
 	"strings"
@@ -23,15 +24,25 @@ type INESFileHeader struct {
 	Control  [10]byte
 }
 
-func (i INESFileHeader) Mapper() uint8 {
-	return i.Control[1]&0xF0 | i.Control[0]>>4
+// Mapper returns the mapper number. NES 2.0 headers extend it to 12 bits.
+func (i INESFileHeader) Mapper() uint16 {
+	mapper := uint16(i.Control[1]&0xF0 | i.Control[0]>>4)
+	if i.NESv2() {
+		mapper |= uint16(i.Control[2]&0xF) << 8
+	}
+	return mapper
 }
 
-func (i *INESFileHeader) SetMapper(v uint8) {
+// SetMapper sets the mapper number.
+// Bits 8-11 are only written to NES 2.0 headers, so [INESFileHeader.SetNESv2] should be called first.
+func (i *INESFileHeader) SetMapper(v uint16) {
 	i.Control[0] &^= 0xF0
 	i.Control[1] &^= 0xF0
-	i.Control[0] |= v << 4
-	i.Control[1] |= v & 0xF0
+	i.Control[0] |= byte(v) << 4
+	i.Control[1] |= byte(v) & 0xF0
+	if i.NESv2() {
+		i.Control[2] = i.Control[2]&0xF0 | byte(v>>8)&0xF
+	}
 }
 
 func (i INESFileHeader) Mirror() Mirror {
@@ -68,6 +79,13 @@ func (i INESFileHeader) NESv2() bool {
 	return i.Control[1]&0xC == 0x8
 }
 
+func (i *INESFileHeader) SetNESv2(v bool) {
+	i.Control[1] &^= 0xC
+	if v {
+		i.Control[1] |= 0x8
+	}
+}
+
 func (i INESFileHeader) Submapper() uint8 {
 	if i.NESv2() {
 		return i.Control[2] >> 4
@@ -75,6 +93,230 @@ func (i INESFileHeader) Submapper() uint8 {
 	return 0
 }
 
+func (i *INESFileHeader) SetSubmapper(v uint8) {
+	i.Control[2] = i.Control[2]&0xF | v<<4
+}
+
+// PRGSize returns the size of PRG-ROM in bytes.
+func (i INESFileHeader) PRGSize() int {
+	if i.NESv2() {
+		return nes2ROMSize(i.PRGCount, i.Control[3]&0xF, consts.PRGChunkSize)
+	}
+	return int(i.PRGCount) * consts.PRGChunkSize
+}
+
+// SetPRGSize sets the size of PRG-ROM in bytes.
+func (i *INESFileHeader) SetPRGSize(v int) error {
+	lsb, msb, err := i.romSize(v, consts.PRGChunkSize)
+	if err != nil {
+		return fmt.Errorf("prg: %w", err)
+	}
+	i.PRGCount = lsb
+	if i.NESv2() {
+		i.Control[3] = i.Control[3]&0xF0 | msb
+	}
+	return nil
+}
+
+// CHRSize returns the size of CHR-ROM in bytes. A size of 0 means the cartridge uses CHR-RAM.
+func (i INESFileHeader) CHRSize() int {
+	if i.NESv2() {
+		return nes2ROMSize(i.CHRCount, i.Control[3]>>4, consts.CHRChunkSize)
+	}
+	return int(i.CHRCount) * consts.CHRChunkSize
+}
+
+// SetCHRSize sets the size of CHR-ROM in bytes.
+func (i *INESFileHeader) SetCHRSize(v int) error {
+	lsb, msb, err := i.romSize(v, consts.CHRChunkSize)
+	if err != nil {
+		return fmt.Errorf("chr: %w", err)
+	}
+	i.CHRCount = lsb
+	if i.NESv2() {
+		i.Control[3] = i.Control[3]&0xF | msb<<4
+	}
+	return nil
+}
+
+var ErrUnsupportedSize = errors.New("size can not be represented in header")
+
+func nes2ROMSize(lsb, msb byte, chunkSize int) int {
+	if msb == 0xF {
+		// Exponent-multiplier notation
+		exponent := lsb >> 2
+		multiplier := int(lsb&0x3)*2 + 1
+		return (1 << exponent) * multiplier
+	}
+	return (int(msb)<<8 | int(lsb)) * chunkSize
+}
+
+func (i INESFileHeader) romSize(v, chunkSize int) (byte, byte, error) {
+	maxCount := 0xFF
+	if i.NESv2() {
+		maxCount = 0xEFF
+	}
+
+	if count := v / chunkSize; v%chunkSize == 0 && count <= maxCount {
+		return byte(count), byte(count >> 8), nil
+	}
+
+	if i.NESv2() && v > 0 {
+		// Exponent-multiplier notation
+		for multiplier := 1; multiplier <= 7; multiplier += 2 {
+			if v%multiplier != 0 {
+				continue
+			}
+			n := v / multiplier
+			if n&(n-1) != 0 {
+				continue
+			}
+			exponent := bits.TrailingZeros(uint(n))
+			if exponent > 0x3F {
+				break
+			}
+			return byte(exponent<<2 | multiplier/2), 0xF, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("%w: %d", ErrUnsupportedSize, v)
+}
+
+// PRGRAMSize returns the size of volatile PRG-RAM in bytes.
+// iNES headers do not reliably specify a size, so 8 KiB is assumed.
+func (i INESFileHeader) PRGRAMSize() int {
+	if i.NESv2() {
+		return nes2RAMSize(i.Control[4] & 0xF)
+	}
+	return 0x2000
+}
+
+func (i *INESFileHeader) SetPRGRAMSize(v int) error {
+	shift, err := nes2RAMShift(v)
+	if err != nil {
+		return fmt.Errorf("prg-ram: %w", err)
+	}
+	i.Control[4] = i.Control[4]&0xF0 | shift
+	return nil
+}
+
+// PRGNVRAMSize returns the size of battery-backed PRG-RAM in bytes.
+func (i INESFileHeader) PRGNVRAMSize() int {
+	if i.NESv2() {
+		return nes2RAMSize(i.Control[4] >> 4)
+	}
+	return 0
+}
+
+func (i *INESFileHeader) SetPRGNVRAMSize(v int) error {
+	shift, err := nes2RAMShift(v)
+	if err != nil {
+		return fmt.Errorf("prg-nvram: %w", err)
+	}
+	i.Control[4] = i.Control[4]&0xF | shift<<4
+	return nil
+}
+
+// CHRRAMSize returns the size of volatile CHR-RAM in bytes.
+// iNES headers imply 8 KiB of CHR-RAM when there is no CHR-ROM.
+func (i INESFileHeader) CHRRAMSize() int {
+	if i.NESv2() {
+		return nes2RAMSize(i.Control[5] & 0xF)
+	}
+	if i.CHRCount == 0 {
+		return consts.CHRChunkSize
+	}
+	return 0
+}
+
+func (i *INESFileHeader) SetCHRRAMSize(v int) error {
+	shift, err := nes2RAMShift(v)
+	if err != nil {
+		return fmt.Errorf("chr-ram: %w", err)
+	}
+	i.Control[5] = i.Control[5]&0xF0 | shift
+	return nil
+}
+
+// CHRNVRAMSize returns the size of battery-backed CHR-RAM in bytes.
+func (i INESFileHeader) CHRNVRAMSize() int {
+	if i.NESv2() {
+		return nes2RAMSize(i.Control[5] >> 4)
+	}
+	return 0
+}
+
+func (i *INESFileHeader) SetCHRNVRAMSize(v int) error {
+	shift, err := nes2RAMShift(v)
+	if err != nil {
+		return fmt.Errorf("chr-nvram: %w", err)
+	}
+	i.Control[5] = i.Control[5]&0xF | shift<<4
+	return nil
+}
+
+func nes2RAMSize(shift byte) int {
+	if shift == 0 {
+		return 0
+	}
+	return 64 << shift
+}
+
+func nes2RAMShift(v int) (byte, error) {
+	if v == 0 {
+		return 0, nil
+	}
+	for shift := byte(1); shift <= 0xF; shift++ {
+		if 64<<shift == v {
+			return shift, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %d", ErrUnsupportedSize, v)
+}
+
+// Timing returns the CPU/PPU timing mode. iNES headers are always reported as NTSC.
+func (i INESFileHeader) Timing() Timing {
+	if i.NESv2() {
+		return Timing(i.Control[6] & 0x3)
+	}
+	return TimingNTSC
+}
+
+func (i *INESFileHeader) SetTiming(v Timing) {
+	i.Control[6] = i.Control[6]&^0x3 | byte(v)&0x3
+}
+
+// ConsoleType returns the console type.
+// When a NES 2.0 header uses the extended console type, the extended value is returned.
+func (i INESFileHeader) ConsoleType() ConsoleType {
+	v := ConsoleType(i.Control[1] & 0x3)
+	if v == consoleExtended && i.NESv2() {
+		return ConsoleType(i.Control[7] & 0xF)
+	}
+	return v
+}
+
+func (i *INESFileHeader) SetConsoleType(v ConsoleType) {
+	i.Control[1] &^= 0x3
+	if v < consoleExtended {
+		i.Control[1] |= byte(v)
+		return
+	}
+	i.Control[1] |= byte(consoleExtended)
+	i.Control[7] = i.Control[7]&0xF0 | byte(v)&0xF
+}
+
+// ExpansionDevice returns the default expansion device. iNES headers are always reported as unspecified.
+func (i INESFileHeader) ExpansionDevice() ExpansionDevice {
+	if i.NESv2() {
+		return ExpansionDevice(i.Control[9] & 0x3F)
+	}
+	return ExpansionUnspecified
+}
+
+func (i *INESFileHeader) SetExpansionDevice(v ExpansionDevice) {
+	i.Control[9] = i.Control[9]&0xC0 | byte(v)&0x3F
+}
+
 var ErrInvalidROM = errors.New("invalid ROM file")
 
 func FromINESFile(path string) (*Cartridge, error) {
@@ -118,20 +360,28 @@ func FromINES(r io.Reader) (*Cartridge, error) {
 	slog.Debug("Loaded iNES header",
 		"battery", cartridge.Battery,
 		"mapper", header.Mapper(),
+		"submapper", header.Submapper(),
 		"mirror", cartridge.Mirror,
-		"prg", header.PRGCount,
-		"chr", header.CHRCount,
+		"prg", header.PRGSize(),
+		"chr", header.CHRSize(),
+		"prgRAM", header.PRGRAMSize()+header.PRGNVRAMSize(),
+		"chrRAM", header.CHRRAMSize()+header.CHRNVRAMSize(),
+		"nes2", header.NESv2(),
+		"timing", header.Timing(),
 	)
 
-	cartridge.PRG = make([]byte, int(header.PRGCount)*consts.PRGChunkSize)
+	cartridge.SRAM = make([]byte, header.PRGRAMSize()+header.PRGNVRAMSize())
+
+	cartridge.PRG = make([]byte, header.PRGSize())
 	if _, err := io.ReadFull(tr, cartridge.PRG); err != nil {
 		return nil, err
 	}
 
-	if header.CHRCount == 0 {
-		cartridge.CHR = make([]byte, consts.CHRChunkSize)
+	if chrSize := header.CHRSize(); chrSize == 0 {
+		// Mappers expect at least one full pattern table
+		cartridge.CHR = make([]byte, max(header.CHRRAMSize()+header.CHRNVRAMSize(), consts.CHRChunkSize))
 	} else {
-		cartridge.CHR = make([]byte, int(header.CHRCount)*consts.CHRChunkSize)
+		cartridge.CHR = make([]byte, chrSize)
 		if _, err := io.ReadFull(tr, cartridge.CHR); err != nil {
 			return nil, err
 		}
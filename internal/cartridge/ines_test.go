@@ -1,9 +1,12 @@
 package cartridge
 
 import (
+	"bytes"
+	"encoding/binary"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_INESFileHeader_Battery(t *testing.T) {
@@ -68,12 +71,14 @@ func Test_INESFileHeader_Mapper(t *testing.T) {
 	tests := []struct {
 		name   string
 		fields fields
-		want   byte
+		want   uint16
 	}{
 		{"0", fields{}, 0},
 		{"1", fields{[10]byte{0x10}}, 1},
 		{"2", fields{[10]byte{0x20}}, 2},
 		{"40", fields{[10]byte{0x80, 0x20}}, 40},
+		{"nes2 extended", fields{[10]byte{0x80, 0x28, 0x21}}, 0x128},
+		{"ines ignores extended", fields{[10]byte{0x80, 0x20, 0x21}}, 40},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -158,7 +163,7 @@ func TestINESFileHeader_SetMapper(t *testing.T) {
 		Control [10]byte
 	}
 	type args struct {
-		v uint8
+		v uint16
 	}
 	tests := []struct {
 		name   string
@@ -170,6 +175,8 @@ func TestINESFileHeader_SetMapper(t *testing.T) {
 		{"0 to 71", fields{}, args{71}, [10]byte{0x70, 0x40}},
 		{"71 to 0", fields{[10]byte{0x70, 0x40}}, args{0}, [10]byte{}},
 		{"extraneous unchanged", fields{Control: [10]byte{0xff, 0xff, 0xff}}, args{0}, [10]byte{0xf, 0xf, 0xff}},
+		{"nes2 0 to 256", fields{[10]byte{0, 0x8}}, args{256}, [10]byte{0, 0x8, 0x1}},
+		{"nes2 submapper unchanged", fields{[10]byte{0, 0x8, 0x30}}, args{0x171}, [10]byte{0x10, 0x78, 0x31}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -181,3 +188,150 @@ func TestINESFileHeader_SetMapper(t *testing.T) {
 		})
 	}
 }
+
+func TestINESFileHeader_PRGSize(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		header INESFileHeader
+		want   int
+	}{
+		{"ines", INESFileHeader{PRGCount: 2}, 0x8000},
+		{"ines ignores msb", INESFileHeader{PRGCount: 2, Control: [10]byte{3: 0x1}}, 0x8000},
+		{"nes2", INESFileHeader{PRGCount: 2, Control: [10]byte{1: 0x8}}, 0x8000},
+		{"nes2 msb", INESFileHeader{PRGCount: 2, Control: [10]byte{1: 0x8, 3: 0x1}}, 0x102 * 0x4000},
+		{"nes2 exponent", INESFileHeader{PRGCount: 10<<2 | 1, Control: [10]byte{1: 0x8, 3: 0xF}}, 3 << 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.header.PRGSize())
+		})
+	}
+}
+
+func TestINESFileHeader_SetPRGSize(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		nes2    bool
+		v       int
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"ines", false, 0x8000, require.NoError},
+		{"ines too large", false, 0x100 * 0x4000, require.Error},
+		{"ines uneven", false, 0x6000, require.Error},
+		{"nes2 msb", true, 0x102 * 0x4000, require.NoError},
+		{"nes2 exponent", true, 3 << 10, require.NoError},
+		{"nes2 unrepresentable", true, 0x6001, require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var i INESFileHeader
+			i.SetNESv2(tt.nes2)
+			err := i.SetPRGSize(tt.v)
+			tt.wantErr(t, err)
+			if err == nil {
+				assert.Equal(t, tt.v, i.PRGSize())
+			}
+		})
+	}
+}
+
+func TestINESFileHeader_CHRSize(t *testing.T) {
+	t.Parallel()
+	var i INESFileHeader
+	i.SetNESv2(true)
+	require.NoError(t, i.SetPRGSize(0x4000))
+	require.NoError(t, i.SetCHRSize(0x101*0x2000))
+	assert.Equal(t, 0x4000, i.PRGSize())
+	assert.Equal(t, 0x101*0x2000, i.CHRSize())
+	assert.Equal(t, [10]byte{1: 0x8, 3: 0x10}, i.Control)
+}
+
+func TestINESFileHeader_RAMSize(t *testing.T) {
+	t.Parallel()
+	var i INESFileHeader
+	assert.Equal(t, 0x2000, i.PRGRAMSize())
+	assert.Equal(t, 0x2000, i.CHRRAMSize())
+
+	i.SetNESv2(true)
+	assert.Zero(t, i.PRGRAMSize())
+	assert.Zero(t, i.CHRRAMSize())
+
+	require.NoError(t, i.SetPRGRAMSize(0x2000))
+	require.NoError(t, i.SetPRGNVRAMSize(0x8000))
+	require.NoError(t, i.SetCHRRAMSize(0x8000))
+	require.NoError(t, i.SetCHRNVRAMSize(0))
+	require.Error(t, i.SetPRGRAMSize(0x3000))
+	require.Error(t, i.SetCHRRAMSize(64))
+
+	assert.Equal(t, 0x2000, i.PRGRAMSize())
+	assert.Equal(t, 0x8000, i.PRGNVRAMSize())
+	assert.Equal(t, 0x8000, i.CHRRAMSize())
+	assert.Zero(t, i.CHRNVRAMSize())
+	assert.Equal(t, byte(0x97), i.Control[4])
+	assert.Equal(t, byte(0x09), i.Control[5])
+}
+
+func TestINESFileHeader_Timing(t *testing.T) {
+	t.Parallel()
+	i := INESFileHeader{Control: [10]byte{6: 0xFF}}
+	assert.Equal(t, TimingNTSC, i.Timing())
+
+	i.SetNESv2(true)
+	assert.Equal(t, TimingDendy, i.Timing())
+
+	i.SetTiming(TimingPAL)
+	assert.Equal(t, TimingPAL, i.Timing())
+	assert.Equal(t, byte(0xFD), i.Control[6])
+}
+
+func TestINESFileHeader_ConsoleType(t *testing.T) {
+	t.Parallel()
+	var i INESFileHeader
+	i.SetNESv2(true)
+	assert.Equal(t, ConsoleNES, i.ConsoleType())
+
+	i.SetConsoleType(ConsoleVsSystem)
+	assert.Equal(t, ConsoleVsSystem, i.ConsoleType())
+	assert.Equal(t, byte(0x9), i.Control[1])
+
+	i.SetConsoleType(ConsoleEPSM)
+	assert.Equal(t, ConsoleEPSM, i.ConsoleType())
+	assert.Equal(t, byte(0xB), i.Control[1])
+	assert.Equal(t, byte(0x4), i.Control[7])
+}
+
+func TestINESFileHeader_ExpansionDevice(t *testing.T) {
+	t.Parallel()
+	i := INESFileHeader{Control: [10]byte{9: 0xC8}}
+	assert.Equal(t, ExpansionUnspecified, i.ExpansionDevice())
+
+	i.SetNESv2(true)
+	assert.Equal(t, ExpansionZapper, i.ExpansionDevice())
+
+	i.SetExpansionDevice(ExpansionFourScore)
+	assert.Equal(t, ExpansionFourScore, i.ExpansionDevice())
+	assert.Equal(t, byte(0xC2), i.Control[9])
+}
+
+func TestFromINES_NESv2(t *testing.T) {
+	t.Parallel()
+	header := New().Header
+	header.SetMapper(4)
+	require.NoError(t, header.SetPRGSize(0x8000))
+	require.NoError(t, header.SetPRGNVRAMSize(0x8000))
+	require.NoError(t, header.SetCHRRAMSize(0x4000))
+
+	var buf bytes.Buffer
+	require.NoError(t, binary.Write(&buf, binary.LittleEndian, header))
+	buf.Write(make([]byte, 0x8000))
+
+	cart, err := FromINES(&buf)
+	require.NoError(t, err)
+	assert.Len(t, cart.PRG, 0x8000)
+	assert.Len(t, cart.CHR, 0x4000)
+	assert.Len(t, cart.SRAM, 0x8000)
+}
@@ -30,9 +30,106 @@ type MapperIRQ interface {
 	IRQ() bool
 }
 
+// MapperOnPPUWrite is implemented by mappers that snoop CPU writes to PPU registers.
+type MapperOnPPUWrite interface {
+	OnPPUWrite(addr uint16, data byte)
+}
+
+// PPUFetch is the kind of memory read by the PPU while rendering.
+type PPUFetch uint8
+
+const (
+	FetchNametable PPUFetch = iota
+	FetchAttribute
+	FetchBackground
+	FetchSprite
+)
+
+// MapperPPUFetch is implemented by mappers that observe or replace PPU rendering fetches.
+// If ok is false, the PPU reads memory normally.
+type MapperPPUFetch interface {
+	OnPPUFetch(fetch PPUFetch, addr uint16) (data byte, ok bool)
+}
+
+// MapperNametable is implemented by mappers that map nametables themselves instead of using [Cartridge.Mirror].
+// vram is the console's 2 KiB of nametable RAM.
+type MapperNametable interface {
+	ReadNametable(vram []byte, addr uint16) byte
+	WriteNametable(vram []byte, addr uint16, data byte)
+}
+
+// AudioChannel is a set of expansion audio channels.
+type AudioChannel uint16
+
+const (
+	ChannelMMC5Pulse1 AudioChannel = 1 << iota
+	ChannelMMC5Pulse2
+	ChannelMMC5PCM
+	ChannelVRC6Pulse1
+	ChannelVRC6Pulse2
+	ChannelVRC6Sawtooth
+	ChannelSunsoft5BA
+	ChannelSunsoft5BB
+	ChannelSunsoft5BC
+	ChannelFDS
+)
+
+// AudioChip is an expansion audio chip. Each chip has its own volume setting.
+type AudioChip uint8
+
+const (
+	ChipMMC5 AudioChip = iota
+	ChipVRC6
+	ChipSunsoft5B
+	ChipFDS
+	ChipCount
+)
+
+// AudioMix configures how expansion audio is mixed.
+type AudioMix struct {
+	// Channels is the set of enabled channels.
+	Channels AudioChannel
+	// Volume scales the output of each chip.
+	Volume [ChipCount]float32
+}
+
+// NewAudioMix returns a mix of the given channels, with every chip at full volume.
+func NewAudioMix(channels AudioChannel) AudioMix {
+	mix := AudioMix{Channels: channels}
+	for i := range mix.Volume {
+		mix.Volume[i] = 1
+	}
+	return mix
+}
+
+// ExpansionGain is the output level of one volume step of an APU pulse channel,
+// using the linear approximation of the APU mixer.
+// Expansion audio samples are expressed on this scale so that they can be added directly to the APU output.
+const ExpansionGain = 0.00752
+
+// MapperAudio is implemented by mappers with expansion audio.
+// It is detected by the console, clocked once per CPU cycle, and blended into the APU mixer.
+type MapperAudio interface {
+	// StepAudio clocks expansion audio by one CPU cycle.
+	StepAudio()
+	// Audio returns the mixed output of the enabled channels, scaled by the volume of each chip.
+	// A channel at full volume should be about 15*[ExpansionGain], matching the relative loudness of the original hardware.
+	Audio(mix *AudioMix) float32
+}
+
+// MapperDisk is implemented by mappers with a swappable disk.
+type MapperDisk interface {
+	// SwitchSide ejects the disk, then inserts the next side. It returns the side that will be inserted.
+	SwitchSide() int
+}
+
 var ErrUnsupportedMapper = errors.New("unsupported mapper")
 
 func NewMapper(cartridge *Cartridge) (Mapper, error) { //nolint:ireturn,nolintlint
+	if cartridge.NSF != nil {
+		return NewMapperNSF(cartridge), nil
+	}
+
 	switch cartridge.Header.Mapper() {
 	case 0, 2:
 		return NewMapper2(cartridge), nil
@@ -42,8 +139,17 @@ func NewMapper(cartridge *Cartridge) (Mapper, error) { //nolint:ireturn,nolintli
 		return NewMapper3(cartridge), nil
 	case 4:
 		return NewMapper4(cartridge), nil
+	case 5:
+		return NewMapper5(cartridge), nil
 	case 7:
 		return NewMapper7(cartridge), nil
+	case 20:
+		if cartridge.Disk == nil {
+			return nil, fmt.Errorf("%w: 20 requires a disk image", ErrUnsupportedMapper)
+		}
+		return NewMapper20(cartridge), nil
+	case 24, 26:
+		return NewMapper24(cartridge), nil
 	case 69:
 		return NewMapper69(cartridge), nil
 	case 71:
@@ -41,7 +41,7 @@ func (m *Mapper1) ReadMem(addr uint16) byte {
 		return m.cartridge.CHR[m.CHROffsets[bank]+offset]
 	case 0x6000 <= addr && addr < 0x8000:
 		addr -= 0x6000
-		return m.cartridge.SRAM[addr]
+		return m.cartridge.ReadSRAM(addr)
 	case 0x8000 <= addr:
 		addr -= 0x8000
 		bank := addr / consts.PRGChunkSize
@@ -61,7 +61,7 @@ func (m *Mapper1) WriteMem(addr uint16, data byte) {
 		m.cartridge.CHR[m.CHROffsets[bank]+offset] = data
 	case 0x6000 <= addr && addr < 0x8000:
 		addr -= 0x6000
-		m.cartridge.SRAM[addr] = data
+		m.cartridge.WriteSRAM(addr, data)
 	case 0x8000 <= addr:
 		if data>>7&1 == 1 {
 			m.ShiftRegister = 0x10
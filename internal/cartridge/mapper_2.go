@@ -34,7 +34,7 @@ func (m *Mapper2) ReadMem(addr uint16) byte {
 		return m.cartridge.CHR[addr]
 	case 0x6000 <= addr && addr < 0x8000:
 		addr -= 0x6000
-		return m.cartridge.SRAM[addr]
+		return m.cartridge.ReadSRAM(addr)
 	case 0x8000 <= addr && addr < 0xC000:
 		addr := uint(addr)
 		addr -= 0x8000
@@ -57,7 +57,7 @@ func (m *Mapper2) WriteMem(addr uint16, data byte) {
 		m.cartridge.CHR[addr] = data
 	case 0x6000 <= addr && addr < 0x8000:
 		addr -= 0x6000
-		m.cartridge.SRAM[addr] = data
+		m.cartridge.WriteSRAM(addr, data)
 	case 0x8000 <= addr:
 		data := uint(data)
 		data %= m.PRGBanks
@@ -0,0 +1,382 @@
+package cartridge
+
+import (
+	"log/slog"
+
+	"gabe565.com/gones/internal/log"
+)
+
+const (
+	// fdsHeadDelay is the number of CPU cycles for the drive head to return to the start of the disk.
+	fdsHeadDelay = 50000
+	// fdsByteDelay is the number of CPU cycles to transfer one byte.
+	fdsByteDelay = 150
+	// fdsInsertDelay is the number of CPU cycles a disk stays ejected while switching sides.
+	// The BIOS only notices a new disk after it has seen the drive empty.
+	fdsInsertDelay = 1789773
+)
+
+// NewMapper20 creates the Famicom Disk System RAM adapter.
+// Mapper 20 is reserved for disk images, so it requires a cartridge with a [Disk].
+func NewMapper20(cartridge *Cartridge) *Mapper20 {
+	return &Mapper20{
+		cartridge: cartridge,
+		Side:      0,
+		EndOfHead: true,
+		Sound:     NewFDSAudio(),
+	}
+}
+
+type Mapper20 struct {
+	cartridge *Cartridge
+
+	DiskRegEnabled  bool
+	SoundRegEnabled bool
+
+	TimerReload   uint16
+	TimerCounter  uint16
+	TimerRepeat   bool
+	TimerEnabled  bool
+	TimerIRQ      bool
+	DiskIRQ       bool
+	DiskIRQEnable bool
+
+	// Side is the inserted disk side, or -1 when the drive is empty
+	Side        int
+	NextSide    int
+	InsertDelay int
+
+	MotorOn          bool
+	ResetTransfer    bool
+	ReadMode         bool
+	CRCControl       bool
+	PrevCRCControl   bool
+	TransferStart    bool
+	TransferComplete bool
+	ReadData         byte
+	WriteData        byte
+
+	Position  int
+	Delay     int
+	EndOfHead bool
+	Scanning  bool
+	GapEnded  bool
+	CRC       uint16
+
+	Sound FDSAudio
+}
+
+func (m *Mapper20) Cartridge() *Cartridge { return m.cartridge }
+
+func (m *Mapper20) SetCartridge(c *Cartridge) { m.cartridge = c }
+
+func (m *Mapper20) IRQ() bool { return m.TimerIRQ || m.DiskIRQ }
+
+func (m *Mapper20) StepAudio() {
+	if m.SoundRegEnabled {
+		m.Sound.step()
+	}
+}
+
+func (m *Mapper20) Audio(mix *AudioMix) float32 {
+	return m.Sound.output(mix.Channels) * mix.Volume[ChipFDS]
+}
+
+// Sides returns the number of disk sides.
+func (m *Mapper20) Sides() int { return len(m.cartridge.Disk.Sides) }
+
+// SwitchSide ejects the disk, then inserts the next side after a delay.
+// It returns the side that will be inserted.
+func (m *Mapper20) SwitchSide() int {
+	if m.Side != -1 {
+		m.NextSide = (m.Side + 1) % m.Sides()
+	} else {
+		m.NextSide = (m.NextSide + 1) % m.Sides()
+	}
+	m.Side = -1
+	m.InsertDelay = fdsInsertDelay
+	return m.NextSide
+}
+
+func (m *Mapper20) OnCPUStep(cycles uint) {
+	for range cycles {
+		m.stepTimer()
+		m.stepDrive()
+	}
+}
+
+func (m *Mapper20) ReadMem(addr uint16) byte {
+	switch {
+	case addr < 0x2000:
+		return m.cartridge.CHR[addr]
+	case 0x4030 <= addr && addr <= 0x4033:
+		if !m.DiskRegEnabled {
+			return 0
+		}
+		return m.readRegister(addr)
+	case 0x4040 <= addr && addr <= 0x4092:
+		if !m.SoundRegEnabled {
+			return 0
+		}
+		return m.Sound.Read(addr)
+	case 0x4020 <= addr && addr < 0x6000:
+		// open bus
+		return 0
+	case 0x6000 <= addr && addr < 0xE000:
+		return m.cartridge.ReadSRAM(addr - 0x6000)
+	case 0xE000 <= addr:
+		return m.cartridge.PRG[addr-0xE000]
+	default:
+		slog.Error("Invalid mapper 20 read", "addr", log.HexAddr(addr))
+		return 0
+	}
+}
+
+// ReadMemSafe reads memory without acknowledging disk or timer IRQs.
+func (m *Mapper20) ReadMemSafe(addr uint16) byte {
+	if m.DiskRegEnabled && 0x4030 <= addr && addr <= 0x4033 {
+		return m.peekRegister(addr)
+	}
+	return m.ReadMem(addr)
+}
+
+func (m *Mapper20) WriteMem(addr uint16, data byte) {
+	switch {
+	case addr < 0x2000:
+		m.cartridge.CHR[addr] = data
+	case addr == 0x4023:
+		m.DiskRegEnabled = data&1 != 0
+		m.SoundRegEnabled = data&2 != 0
+		if !m.DiskRegEnabled {
+			m.TimerEnabled = false
+			m.TimerIRQ = false
+			m.DiskIRQ = false
+		}
+	case 0x4020 <= addr && addr <= 0x4026:
+		if m.DiskRegEnabled {
+			m.writeRegister(addr, data)
+		}
+	case 0x4040 <= addr && addr <= 0x408A:
+		if m.SoundRegEnabled {
+			m.Sound.Write(addr, data)
+		}
+	case 0x4020 <= addr && addr < 0x6000:
+		// Unmapped
+	case 0x6000 <= addr && addr < 0xE000:
+		m.cartridge.WriteSRAM(addr-0x6000, data)
+	case 0xE000 <= addr:
+		// BIOS ROM
+	default:
+		slog.Error("Invalid mapper 20 write", "addr", log.HexAddr(addr))
+	}
+}
+
+func (m *Mapper20) readRegister(addr uint16) byte {
+	data := m.peekRegister(addr)
+	switch addr {
+	case 0x4030:
+		m.TimerIRQ = false
+		fallthrough
+	case 0x4031:
+		m.TransferComplete = false
+		m.DiskIRQ = false
+	}
+	return data
+}
+
+// peekRegister reads a disk register like readRegister, without acknowledging IRQs.
+func (m *Mapper20) peekRegister(addr uint16) byte {
+	switch addr {
+	case 0x4030:
+		var data byte
+		if m.TimerIRQ {
+			data |= 1
+		}
+		if m.TransferComplete {
+			data |= 2
+		}
+		return data
+	case 0x4031:
+		return m.ReadData
+	case 0x4032:
+		var data byte
+		if m.Side == -1 {
+			// Disk not inserted, not ready, and write protected
+			data |= 0b111
+		} else if !m.Scanning {
+			data |= 0b10
+		}
+		return data
+	case 0x4033:
+		// Battery is good
+		return 0x80
+	default:
+		return 0
+	}
+}
+
+func (m *Mapper20) writeRegister(addr uint16, data byte) {
+	switch addr {
+	case 0x4020:
+		m.TimerReload = m.TimerReload&0xFF00 | uint16(data)
+	case 0x4021:
+		m.TimerReload = uint16(data)<<8 | m.TimerReload&0xFF
+	case 0x4022:
+		m.TimerRepeat = data&1 != 0
+		m.TimerEnabled = data&2 != 0
+		if m.TimerEnabled {
+			m.TimerCounter = m.TimerReload
+		} else {
+			m.TimerIRQ = false
+		}
+	case 0x4024:
+		m.WriteData = data
+		m.TransferComplete = false
+		m.DiskIRQ = false
+	case 0x4025:
+		m.DiskIRQ = false
+		m.MotorOn = data&0x01 != 0
+		m.ResetTransfer = data&0x02 != 0
+		m.ReadMode = data&0x04 != 0
+		if data&0x08 != 0 {
+			m.cartridge.Mirror = Horizontal
+		} else {
+			m.cartridge.Mirror = Vertical
+		}
+		m.CRCControl = data&0x10 != 0
+		m.TransferStart = data&0x40 != 0
+		m.DiskIRQEnable = data&0x80 != 0
+	}
+}
+
+func (m *Mapper20) stepTimer() {
+	if !m.TimerEnabled {
+		return
+	}
+	if m.TimerCounter == 0 {
+		m.TimerIRQ = true
+		m.TimerCounter = m.TimerReload
+		if !m.TimerRepeat {
+			m.TimerEnabled = false
+		}
+	} else {
+		m.TimerCounter--
+	}
+}
+
+// stepDrive advances the disk drive by one CPU cycle.
+// A byte is read or written every fdsByteDelay cycles while the motor is on.
+func (m *Mapper20) stepDrive() {
+	if m.Side == -1 {
+		if m.InsertDelay > 0 {
+			m.InsertDelay--
+			if m.InsertDelay == 0 {
+				m.Side = m.NextSide
+			}
+		}
+	}
+
+	if m.Side == -1 || !m.MotorOn {
+		m.EndOfHead = true
+		m.Scanning = false
+		return
+	}
+
+	if m.ResetTransfer && !m.Scanning {
+		return
+	}
+
+	if m.EndOfHead {
+		m.Delay = fdsHeadDelay
+		m.EndOfHead = false
+		m.Position = 0
+		m.GapEnded = false
+		return
+	}
+
+	if m.Delay > 0 {
+		m.Delay--
+		return
+	}
+
+	m.Scanning = true
+	side := m.cartridge.Disk.Sides[m.Side]
+
+	if m.ReadMode {
+		data := side[m.Position]
+		if !m.PrevCRCControl {
+			m.updateCRC(data)
+		}
+
+		irq := m.DiskIRQEnable
+		if !m.TransferStart {
+			m.GapEnded = false
+			m.CRC = 0
+		} else if data != 0 && !m.GapEnded {
+			// The block mark ends the gap, but is not transferred
+			m.GapEnded = true
+			irq = false
+		}
+
+		if m.GapEnded {
+			m.TransferComplete = true
+			m.ReadData = data
+			if irq {
+				m.DiskIRQ = true
+			}
+		}
+	} else {
+		var data byte
+		if !m.CRCControl {
+			m.TransferComplete = true
+			data = m.WriteData
+			if m.DiskIRQEnable {
+				m.DiskIRQ = true
+			}
+		}
+
+		if !m.TransferStart {
+			data = 0
+		}
+
+		if m.CRCControl {
+			if !m.PrevCRCControl {
+				m.updateCRC(0)
+				m.updateCRC(0)
+			}
+			data = byte(m.CRC)
+			m.CRC >>= 8
+		} else {
+			m.updateCRC(data)
+		}
+
+		// Writes land behind the read head
+		if m.Position >= 2 {
+			side[m.Position-2] = data
+		}
+		m.GapEnded = false
+	}
+	m.PrevCRCControl = m.CRCControl
+
+	m.Position++
+	if m.Position >= len(side) {
+		m.MotorOn = false
+		m.DiskIRQ = false
+	} else {
+		m.Delay = fdsByteDelay
+	}
+}
+
+// updateCRC adds a byte to the running CRC-16/KERMIT used by the disk blocks.
+func (m *Mapper20) updateCRC(data byte) {
+	for n := range 8 {
+		carry := m.CRC&1 != 0
+		m.CRC >>= 1
+		if carry {
+			m.CRC ^= 0x8408
+		}
+		if data>>n&1 != 0 {
+			m.CRC ^= 0x8000
+		}
+	}
+}
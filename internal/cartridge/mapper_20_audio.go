@@ -0,0 +1,239 @@
+package cartridge
+
+// fdsGain scales the 6-bit FDS output so that full volume is about 2.4 times as loud as a full volume APU pulse.
+const fdsGain = 2.4 * 15 / 63 * ExpansionGain
+
+//nolint:gochecknoglobals
+var (
+	// fdsMasterVolume is the master volume multiplier for each $4089 setting, out of 36.
+	fdsMasterVolume = [...]uint32{36, 24, 17, 14}
+	// fdsModSteps is the mod counter adjustment for each mod table value.
+	// Value 4 resets the counter instead.
+	fdsModSteps = [...]int8{0, 1, 2, 4, 0, -4, -2, -1}
+)
+
+// FDSAudio is the Famicom Disk System wavetable channel, with a 64-step 6-bit waveform
+// and a frequency modulation unit.
+type FDSAudio struct {
+	Wave      [64]byte
+	WaveWrite bool
+	WaveHalt  bool
+	WavePos   byte
+	WaveAcc   uint16
+	Volume    byte
+
+	EnvelopeHalt  bool
+	EnvelopeSpeed byte
+	VolumeEnv     FDSEnvelope
+
+	Mod      [64]byte
+	ModPos   byte
+	ModHalt  bool
+	ModAcc   uint16
+	ModEnv   FDSEnvelope
+	ModCount int8
+	// ModPitch is the pitch adjustment calculated from the mod counter and gain
+	ModPitch int32
+}
+
+func NewFDSAudio() FDSAudio {
+	return FDSAudio{EnvelopeSpeed: 0xE8}
+}
+
+// Read reads the wavetable ($4040-$407F) or envelope gains ($4090, $4092).
+func (a *FDSAudio) Read(addr uint16) byte {
+	switch {
+	case addr < 0x4080:
+		return a.Wave[addr-0x4040] | 0x40
+	case addr == 0x4090:
+		return a.VolumeEnv.Gain | 0x40
+	case addr == 0x4092:
+		return a.ModEnv.Gain | 0x40
+	default:
+		return 0
+	}
+}
+
+// Write writes the wavetable ($4040-$407F) or a sound register ($4080-$408A).
+func (a *FDSAudio) Write(addr uint16, data byte) {
+	switch addr {
+	case 0x4080:
+		a.VolumeEnv.write(data, a.EnvelopeSpeed)
+	case 0x4082:
+		a.VolumeEnv.Frequency = a.VolumeEnv.Frequency&0xF00 | uint16(data)
+	case 0x4083:
+		a.VolumeEnv.Frequency = uint16(data&0xF)<<8 | a.VolumeEnv.Frequency&0xFF
+		a.WaveHalt = data&0x80 != 0
+		a.EnvelopeHalt = data&0x40 != 0
+		if a.WaveHalt {
+			a.WavePos = 0
+			a.WaveAcc = 0
+		}
+		if a.EnvelopeHalt {
+			a.VolumeEnv.reset(a.EnvelopeSpeed)
+			a.ModEnv.reset(a.EnvelopeSpeed)
+		}
+	case 0x4084:
+		a.ModEnv.write(data, a.EnvelopeSpeed)
+	case 0x4085:
+		a.setModCounter(int(data & 0x7F))
+		a.updateModPitch()
+	case 0x4086:
+		a.ModEnv.Frequency = a.ModEnv.Frequency&0xF00 | uint16(data)
+	case 0x4087:
+		a.ModEnv.Frequency = uint16(data&0xF)<<8 | a.ModEnv.Frequency&0xFF
+		a.ModHalt = data&0x80 != 0
+		if a.ModHalt {
+			a.ModAcc = 0
+		}
+	case 0x4088:
+		// The mod table is written 2 entries at a time, and only while the mod unit is halted
+		if a.ModHalt {
+			a.Mod[a.ModPos] = data & 7
+			a.Mod[(a.ModPos+1)&0x3F] = data & 7
+			a.ModPos = (a.ModPos + 2) & 0x3F
+		}
+	case 0x4089:
+		a.Volume = data & 3
+		a.WaveWrite = data&0x80 != 0
+	case 0x408A:
+		a.EnvelopeSpeed = data
+		a.VolumeEnv.reset(a.EnvelopeSpeed)
+		a.ModEnv.reset(a.EnvelopeSpeed)
+	default:
+		if addr < 0x4080 && a.WaveWrite {
+			a.Wave[addr-0x4040] = data & 0x3F
+		}
+	}
+}
+
+func (a *FDSAudio) step() {
+	if !a.WaveHalt && !a.EnvelopeHalt {
+		a.VolumeEnv.step(a.EnvelopeSpeed)
+		if a.ModEnv.step(a.EnvelopeSpeed) {
+			a.updateModPitch()
+		}
+	}
+
+	if !a.ModHalt && a.ModEnv.Frequency != 0 {
+		prev := a.ModAcc
+		a.ModAcc += a.ModEnv.Frequency
+		if a.ModAcc < prev {
+			if step := a.Mod[a.ModPos]; step == 4 {
+				a.setModCounter(0)
+			} else {
+				a.setModCounter(int(a.ModCount) + int(fdsModSteps[step]))
+			}
+			a.ModPos = (a.ModPos + 1) & 0x3F
+			a.updateModPitch()
+		}
+	}
+
+	if a.WaveHalt || a.WaveWrite {
+		return
+	}
+	if pitch := int32(a.VolumeEnv.Frequency) + a.ModPitch; pitch > 0 {
+		prev := a.WaveAcc
+		a.WaveAcc += uint16(pitch)
+		if a.WaveAcc < prev {
+			a.WavePos = (a.WavePos + 1) & 0x3F
+		}
+	}
+}
+
+// setModCounter sets the 7-bit signed mod counter, wrapping values outside of its range.
+func (a *FDSAudio) setModCounter(v int) {
+	switch {
+	case v >= 64:
+		v -= 128
+	case v < -64:
+		v += 128
+	}
+	a.ModCount = int8(v)
+}
+
+// updateModPitch recalculates the pitch adjustment, including the hardware's unusual rounding.
+func (a *FDSAudio) updateModPitch() {
+	temp := int32(a.ModCount) * int32(a.ModEnv.Gain)
+	remainder := temp & 0xF
+	temp >>= 4
+	if remainder > 0 && temp&0x80 == 0 {
+		if a.ModCount < 0 {
+			temp--
+		} else {
+			temp += 2
+		}
+	}
+
+	switch {
+	case temp >= 192:
+		temp -= 256
+	case temp < -64:
+		temp += 256
+	}
+
+	temp *= int32(a.VolumeEnv.Frequency)
+	remainder = temp & 0x3F
+	temp >>= 6
+	if remainder >= 32 {
+		temp++
+	}
+	a.ModPitch = temp
+}
+
+func (a *FDSAudio) output(enabled AudioChannel) float32 {
+	if enabled&ChannelFDS == 0 {
+		return 0
+	}
+	level := uint32(min(a.VolumeEnv.Gain, 32)) * fdsMasterVolume[a.Volume]
+	return float32(uint32(a.Wave[a.WavePos])*level/1152) * fdsGain
+}
+
+// FDSEnvelope is an FDS volume or mod envelope, along with the frequency of the unit it controls.
+type FDSEnvelope struct {
+	Frequency uint16
+
+	Disabled bool
+	Increase bool
+	Speed    byte
+	Gain     byte
+	Timer    uint32
+}
+
+func (e *FDSEnvelope) write(data, masterSpeed byte) {
+	e.Speed = data & 0x3F
+	e.Increase = data&0x40 != 0
+	e.Disabled = data&0x80 != 0
+	e.reset(masterSpeed)
+	if e.Disabled {
+		e.Gain = e.Speed
+	}
+}
+
+func (e *FDSEnvelope) reset(masterSpeed byte) {
+	e.Timer = 8 * (uint32(e.Speed) + 1) * uint32(masterSpeed)
+}
+
+// step clocks the envelope by one CPU cycle. It returns true when the gain changes.
+func (e *FDSEnvelope) step(masterSpeed byte) bool {
+	if e.Disabled || masterSpeed == 0 {
+		return false
+	}
+	if e.Timer > 0 {
+		e.Timer--
+	}
+	if e.Timer != 0 {
+		return false
+	}
+
+	e.reset(masterSpeed)
+	switch {
+	case e.Increase && e.Gain < 32:
+		e.Gain++
+	case !e.Increase && e.Gain > 0:
+		e.Gain--
+	default:
+		return false
+	}
+	return true
+}
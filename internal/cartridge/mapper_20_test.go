@@ -0,0 +1,120 @@
+package cartridge
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMapper20(t *testing.T) *Mapper20 {
+	cart, err := FromFDS(bytes.NewReader(newFDSImage(false)), make([]byte, fdsBIOSSize))
+	require.NoError(t, err)
+	m := NewMapper20(cart)
+	m.WriteMem(0x4023, 0b11)
+	return m
+}
+
+func TestMapper20_Memory(t *testing.T) {
+	t.Parallel()
+
+	m := newMapper20(t)
+	m.WriteMem(0x6000, 1)
+	m.WriteMem(0xDFFF, 2)
+	m.WriteMem(0x1FFF, 3)
+	m.WriteMem(0xE000, 4)
+	assert.EqualValues(t, 1, m.ReadMem(0x6000))
+	assert.EqualValues(t, 2, m.ReadMem(0xDFFF))
+	assert.EqualValues(t, 3, m.ReadMem(0x1FFF))
+	assert.EqualValues(t, 0, m.ReadMem(0xE000), "BIOS is read-only")
+
+	m.WriteMem(0x4025, 0x08)
+	assert.Equal(t, Horizontal, m.cartridge.Mirror)
+}
+
+func TestMapper20_Timer(t *testing.T) {
+	t.Parallel()
+
+	m := newMapper20(t)
+	m.WriteMem(0x4020, 2)
+	m.WriteMem(0x4021, 0)
+	m.WriteMem(0x4022, 0b11)
+	m.OnCPUStep(2)
+	assert.False(t, m.IRQ())
+	m.OnCPUStep(1)
+	assert.True(t, m.IRQ())
+
+	assert.EqualValues(t, 1, m.ReadMemSafe(0x4030)&1)
+	assert.True(t, m.IRQ(), "safe reads have no side effects")
+	assert.EqualValues(t, 1, m.ReadMem(0x4030)&1)
+	assert.False(t, m.IRQ(), "reading $4030 acknowledges the IRQ")
+
+	m.OnCPUStep(3)
+	assert.True(t, m.IRQ(), "repeat reloads the timer")
+
+	m.WriteMem(0x4023, 0)
+	assert.False(t, m.IRQ(), "disabling disk registers stops the timer")
+}
+
+func TestMapper20_Drive(t *testing.T) {
+	t.Parallel()
+
+	m := newMapper20(t)
+	assert.EqualValues(t, 0b10, m.ReadMem(0x4032)&0b111, "inserted, not scanning")
+
+	// Motor on, read mode, transfer start, disk IRQ
+	m.WriteMem(0x4025, 0b1100_0101)
+	m.OnCPUStep(fdsHeadDelay + 1)
+	for !m.IRQ() {
+		m.OnCPUStep(1)
+	}
+	assert.EqualValues(t, 0, m.ReadMem(0x4032)&0b111, "inserted and scanning")
+	assert.EqualValues(t, 1, m.ReadMem(0x4031), "first byte after the block mark")
+	assert.False(t, m.IRQ())
+
+	m.OnCPUStep(fdsByteDelay + 1)
+	assert.True(t, m.IRQ())
+	assert.EqualValues(t, '*', m.ReadMem(0x4031))
+
+	side := m.SwitchSide()
+	assert.Zero(t, side)
+	assert.EqualValues(t, 0b111, m.ReadMem(0x4032)&0b111, "ejected")
+	m.OnCPUStep(fdsInsertDelay)
+	assert.Zero(t, m.Side)
+}
+
+func TestMapper20_Audio(t *testing.T) {
+	t.Parallel()
+
+	m := newMapper20(t)
+
+	// Square wave in the wavetable
+	m.WriteMem(0x4089, 0x80)
+	for i := range uint16(64) {
+		var v byte
+		if i < 32 {
+			v = 63
+		}
+		m.WriteMem(0x4040+i, v)
+	}
+	m.WriteMem(0x4089, 0)
+	assert.EqualValues(t, 63|0x40, m.ReadMem(0x4040))
+
+	// Full volume, envelope disabled
+	m.WriteMem(0x4080, 0x80|32)
+	m.WriteMem(0x4082, 0xFF)
+	m.WriteMem(0x4083, 0x0F)
+	m.StepAudio()
+	assert.InDelta(t, 63*fdsGain, audio(m, ChannelFDS), 0.0001)
+	assert.Zero(t, audio(m, ChannelVRC6Pulse1))
+
+	for range 600 {
+		m.StepAudio()
+	}
+	assert.Zero(t, audio(m, ChannelFDS))
+
+	// Halting the wave resets the position
+	m.WriteMem(0x4083, 0x80)
+	assert.Zero(t, m.Sound.WavePos)
+}
@@ -0,0 +1,142 @@
+package cartridge
+
+import (
+	"log/slog"
+
+	"gabe565.com/gones/internal/log"
+)
+
+// NewMapper24 creates a Konami VRC6 mapper.
+// Mapper 26 is the same board with the A0 and A1 address lines swapped.
+func NewMapper24(cartridge *Cartridge) *Mapper24 {
+	return &Mapper24{
+		cartridge: cartridge,
+		swapped:   cartridge.Header.Mapper() == 26,
+	}
+}
+
+type Mapper24 struct {
+	cartridge *Cartridge
+	swapped   bool
+
+	PRGBank16  int
+	PRGBank8   int
+	CHRBanks   [8]int
+	RAMEnabled bool
+
+	IRQCounter VRCIRQ
+	Sound      VRC6Audio
+}
+
+func (m *Mapper24) Cartridge() *Cartridge { return m.cartridge }
+
+func (m *Mapper24) SetCartridge(c *Cartridge) { m.cartridge = c }
+
+func (m *Mapper24) OnCPUStep(cycles uint) { m.IRQCounter.Step(cycles) }
+
+func (m *Mapper24) IRQ() bool { return m.IRQCounter.Pending }
+
+func (m *Mapper24) StepAudio() { m.Sound.step() }
+
+func (m *Mapper24) Audio(mix *AudioMix) float32 {
+	return m.Sound.output(mix.Channels) * mix.Volume[ChipVRC6]
+}
+
+func (m *Mapper24) ReadMem(addr uint16) byte {
+	switch {
+	case addr < 0x2000:
+		bank := addr / 0x400
+		offset := m.CHRBanks[bank]*0x400 + int(addr%0x400)
+		return m.cartridge.CHR[offset%len(m.cartridge.CHR)]
+	case 0x6000 <= addr && addr < 0x8000:
+		if m.RAMEnabled {
+			return m.cartridge.ReadSRAM(addr - 0x6000)
+		}
+		// open bus
+		return 0
+	case 0x8000 <= addr && addr < 0xC000:
+		offset := m.PRGBank16*0x4000 + int(addr-0x8000)
+		return m.cartridge.PRG[offset%len(m.cartridge.PRG)]
+	case 0xC000 <= addr && addr < 0xE000:
+		offset := m.PRGBank8*0x2000 + int(addr-0xC000)
+		return m.cartridge.PRG[offset%len(m.cartridge.PRG)]
+	case 0xE000 <= addr:
+		return m.cartridge.PRG[len(m.cartridge.PRG)-0x2000+int(addr-0xE000)]
+	default:
+		slog.Error("Invalid mapper 24 read", "addr", log.HexAddr(addr))
+		return 0
+	}
+}
+
+func (m *Mapper24) WriteMem(addr uint16, data byte) {
+	switch {
+	case addr < 0x2000:
+		bank := addr / 0x400
+		offset := m.CHRBanks[bank]*0x400 + int(addr%0x400)
+		m.cartridge.CHR[offset%len(m.cartridge.CHR)] = data
+	case 0x6000 <= addr && addr < 0x8000:
+		if m.RAMEnabled {
+			m.cartridge.WriteSRAM(addr-0x6000, data)
+		}
+	case 0x8000 <= addr:
+		m.writeRegister(m.register(addr), data)
+	default:
+		slog.Error("Invalid mapper 24 write", "addr", log.HexAddr(addr))
+	}
+}
+
+// register returns the register selected by a CPU address, undoing the address line swap of mapper 26.
+func (m *Mapper24) register(addr uint16) uint16 {
+	addr &= 0xF003
+	if m.swapped {
+		addr = addr&0xF000 | addr&1<<1 | addr&2>>1
+	}
+	return addr
+}
+
+func (m *Mapper24) writeRegister(reg uint16, data byte) {
+	switch reg & 0xF000 {
+	case 0x8000:
+		m.PRGBank16 = int(data & 0xF)
+	case 0x9000:
+		if reg == 0x9003 {
+			m.Sound.WriteControl(data)
+		} else {
+			m.Sound.Pulse[0].Write(reg&3, data)
+		}
+	case 0xA000:
+		m.Sound.Pulse[1].Write(reg&3, data)
+	case 0xB000:
+		if reg == 0xB003 {
+			// Only the banking mode used by licensed games is supported
+			m.RAMEnabled = data&0x80 != 0
+			switch data >> 2 & 3 {
+			case 0:
+				m.cartridge.Mirror = Vertical
+			case 1:
+				m.cartridge.Mirror = Horizontal
+			case 2:
+				m.cartridge.Mirror = SingleLower
+			case 3:
+				m.cartridge.Mirror = SingleUpper
+			}
+		} else {
+			m.Sound.Sawtooth.Write(reg&3, data)
+		}
+	case 0xC000:
+		m.PRGBank8 = int(data & 0x1F)
+	case 0xD000:
+		m.CHRBanks[reg&3] = int(data)
+	case 0xE000:
+		m.CHRBanks[4+reg&3] = int(data)
+	case 0xF000:
+		switch reg & 3 {
+		case 0:
+			m.IRQCounter.Latch = data
+		case 1:
+			m.IRQCounter.WriteControl(data)
+		case 2:
+			m.IRQCounter.Ack()
+		}
+	}
+}
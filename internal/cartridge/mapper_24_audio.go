@@ -0,0 +1,149 @@
+package cartridge
+
+// VRC6Audio is the VRC6 expansion audio: two pulse channels with 8 duty cycles, and a sawtooth channel.
+type VRC6Audio struct {
+	Pulse    [2]VRC6Pulse
+	Sawtooth VRC6Sawtooth
+
+	Halt bool
+	// Shift divides all channel periods by a power of 2
+	Shift byte
+}
+
+// WriteControl writes the frequency control register ($9003).
+func (a *VRC6Audio) WriteControl(data byte) {
+	a.Halt = data&1 != 0
+	switch {
+	case data&4 != 0:
+		a.Shift = 8
+	case data&2 != 0:
+		a.Shift = 4
+	default:
+		a.Shift = 0
+	}
+}
+
+func (a *VRC6Audio) step() {
+	if a.Halt {
+		return
+	}
+	a.Pulse[0].step(a.Shift)
+	a.Pulse[1].step(a.Shift)
+	a.Sawtooth.step(a.Shift)
+}
+
+func (a *VRC6Audio) output(enabled AudioChannel) float32 {
+	var out byte
+	if enabled&ChannelVRC6Pulse1 != 0 {
+		out += a.Pulse[0].output()
+	}
+	if enabled&ChannelVRC6Pulse2 != 0 {
+		out += a.Pulse[1].output()
+	}
+	if enabled&ChannelVRC6Sawtooth != 0 {
+		out += a.Sawtooth.output()
+	}
+	return float32(out) * ExpansionGain
+}
+
+// VRC6Pulse is a VRC6 pulse channel. It has a 4-bit volume and no envelope or length counter.
+type VRC6Pulse struct {
+	Enabled bool
+	// Mode outputs a constant volume, ignoring the duty cycle
+	Mode   bool
+	Duty   byte
+	Volume byte
+
+	TimerPeriod uint16
+	TimerValue  uint16
+	DutyValue   byte
+}
+
+// Write writes to one of the channel's 3 registers.
+func (p *VRC6Pulse) Write(reg uint16, data byte) {
+	switch reg {
+	case 0:
+		p.Mode = data&0x80 != 0
+		p.Duty = data >> 4 & 7
+		p.Volume = data & 0xF
+	case 1:
+		p.TimerPeriod = p.TimerPeriod&0xF00 | uint16(data)
+	case 2:
+		p.Enabled = data&0x80 != 0
+		p.TimerPeriod = uint16(data&0xF)<<8 | p.TimerPeriod&0xFF
+		if !p.Enabled {
+			p.DutyValue = 15
+		}
+	}
+}
+
+func (p *VRC6Pulse) step(shift byte) {
+	if !p.Enabled {
+		return
+	}
+	if p.TimerValue == 0 {
+		p.TimerValue = p.TimerPeriod >> shift
+		p.DutyValue = (p.DutyValue - 1) & 0xF
+	} else {
+		p.TimerValue--
+	}
+}
+
+func (p *VRC6Pulse) output() byte {
+	if !p.Enabled || !p.Mode && p.DutyValue > p.Duty {
+		return 0
+	}
+	return p.Volume
+}
+
+// VRC6Sawtooth is the VRC6 sawtooth channel. An accumulator is increased by Rate every other clock,
+// and reset every 14 clocks.
+type VRC6Sawtooth struct {
+	Enabled bool
+	Rate    byte
+
+	TimerPeriod uint16
+	TimerValue  uint16
+	Step        byte
+	Accumulator byte
+}
+
+// Write writes to one of the channel's 3 registers.
+func (s *VRC6Sawtooth) Write(reg uint16, data byte) {
+	switch reg {
+	case 0:
+		s.Rate = data & 0x3F
+	case 1:
+		s.TimerPeriod = s.TimerPeriod&0xF00 | uint16(data)
+	case 2:
+		s.Enabled = data&0x80 != 0
+		s.TimerPeriod = uint16(data&0xF)<<8 | s.TimerPeriod&0xFF
+		if !s.Enabled {
+			s.Step = 0
+			s.Accumulator = 0
+		}
+	}
+}
+
+func (s *VRC6Sawtooth) step(shift byte) {
+	if !s.Enabled {
+		return
+	}
+	if s.TimerValue != 0 {
+		s.TimerValue--
+		return
+	}
+	s.TimerValue = s.TimerPeriod >> shift
+	s.Step++
+	switch {
+	case s.Step == 14:
+		s.Step = 0
+		s.Accumulator = 0
+	case s.Step%2 == 0:
+		s.Accumulator += s.Rate
+	}
+}
+
+func (s *VRC6Sawtooth) output() byte {
+	return s.Accumulator >> 3
+}
@@ -0,0 +1,128 @@
+package cartridge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMapper24Cart(mapper uint16) *Cartridge {
+	cart := New()
+	cart.Header.SetMapper(mapper)
+	cart.PRG = make([]byte, 16*0x2000)
+	for i := range len(cart.PRG) / 0x2000 {
+		cart.PRG[i*0x2000] = byte(i)
+	}
+	cart.CHR = make([]byte, 16*0x400)
+	for i := range len(cart.CHR) / 0x400 {
+		cart.CHR[i*0x400] = byte(i)
+	}
+	return cart
+}
+
+func TestMapper24_Banks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		mapper uint16
+		chr    uint16
+	}{
+		{"mapper 24", 24, 0xD001},
+		{"mapper 26", 26, 0xD002},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := NewMapper24(newMapper24Cart(tt.mapper))
+			m.WriteMem(0x8000, 2)
+			m.WriteMem(0xC000, 9)
+			m.WriteMem(tt.chr, 7)
+
+			assert.EqualValues(t, 4, m.ReadMem(0x8000))
+			assert.EqualValues(t, 5, m.ReadMem(0xA000))
+			assert.EqualValues(t, 9, m.ReadMem(0xC000))
+			assert.EqualValues(t, 15, m.ReadMem(0xE000))
+			assert.EqualValues(t, 7, m.ReadMem(0x0400))
+		})
+	}
+}
+
+func TestMapper24_Mirror(t *testing.T) {
+	t.Parallel()
+
+	m := NewMapper24(newMapper24Cart(24))
+	m.WriteMem(0xB003, 0x24)
+	assert.Equal(t, Horizontal, m.cartridge.Mirror)
+	assert.False(t, m.RAMEnabled)
+
+	m.WriteMem(0xB003, 0xAC)
+	assert.Equal(t, SingleUpper, m.cartridge.Mirror)
+	m.WriteMem(0x6000, 0x12)
+	assert.EqualValues(t, 0x12, m.ReadMem(0x6000))
+}
+
+func TestVRCIRQ(t *testing.T) {
+	t.Parallel()
+
+	t.Run("cycle mode", func(t *testing.T) {
+		t.Parallel()
+		var v VRCIRQ
+		v.Latch = 0xFD
+		v.WriteControl(0b111)
+		v.Step(2)
+		assert.False(t, v.Pending)
+		v.Step(1)
+		assert.True(t, v.Pending)
+		assert.EqualValues(t, 0xFD, v.Counter)
+
+		v.Ack()
+		assert.False(t, v.Pending)
+		assert.True(t, v.Enabled, "ack restores enable from the E bit")
+	})
+
+	t.Run("scanline mode", func(t *testing.T) {
+		t.Parallel()
+		var v VRCIRQ
+		v.Latch = 0xFE
+		v.WriteControl(0b010)
+		v.Step(113)
+		assert.EqualValues(t, 0xFE, v.Counter)
+		v.Step(1)
+		assert.EqualValues(t, 0xFF, v.Counter)
+		v.Step(114)
+		assert.True(t, v.Pending)
+
+		v.Ack()
+		assert.False(t, v.Enabled)
+	})
+}
+
+func TestMapper24_Audio(t *testing.T) {
+	t.Parallel()
+
+	m := NewMapper24(newMapper24Cart(24))
+	all := ChannelVRC6Pulse1 | ChannelVRC6Pulse2 | ChannelVRC6Sawtooth
+	assert.Zero(t, audio(m, all))
+
+	// Constant volume pulse
+	m.WriteMem(0x9000, 0x8F)
+	m.WriteMem(0x9002, 0x80)
+	m.StepAudio()
+	assert.InDelta(t, 15*ExpansionGain, audio(m, all), 0.0001)
+	assert.Zero(t, audio(m, ChannelVRC6Pulse2))
+
+	// Sawtooth
+	m.WriteMem(0xB000, 0x20)
+	m.WriteMem(0xB002, 0x80)
+	for range 4 {
+		m.StepAudio()
+	}
+	assert.EqualValues(t, 0x40, m.Sound.Sawtooth.Accumulator)
+	assert.InDelta(t, 8*ExpansionGain, audio(m, ChannelVRC6Sawtooth), 0.0001)
+
+	// Halt
+	m.WriteMem(0x9003, 1)
+	m.StepAudio()
+	assert.EqualValues(t, 0x40, m.Sound.Sawtooth.Accumulator)
+}
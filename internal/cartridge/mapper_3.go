@@ -35,7 +35,7 @@ func (m *Mapper3) ReadMem(addr uint16) byte {
 		return m.cartridge.CHR[addr]
 	case 0x6000 <= addr && addr < 0x8000:
 		addr -= 0x6000
-		return m.cartridge.SRAM[addr]
+		return m.cartridge.ReadSRAM(addr)
 	case 0x8000 <= addr && addr < 0xC000:
 		addr := uint(addr)
 		addr -= 0x8000
@@ -60,7 +60,7 @@ func (m *Mapper3) WriteMem(addr uint16, data byte) {
 		m.cartridge.CHR[addr] = data
 	case 0x6000 <= addr && addr < 0x8000:
 		addr -= 0x6000
-		m.cartridge.SRAM[addr] = data
+		m.cartridge.WriteSRAM(addr, data)
 	case 0x8000 <= addr:
 		m.CHRBank = uint(data & 3)
 	default:
@@ -71,7 +71,7 @@ func (m *Mapper4) ReadMem(addr uint16) byte {
 		return m.cartridge.CHR[m.CHROffsets[bank]+offset]
 	case 0x6000 <= addr && addr < 0x8000:
 		addr -= 0x6000
-		return m.cartridge.SRAM[addr]
+		return m.cartridge.ReadSRAM(addr)
 	case 0x8000 <= addr:
 		addr -= 0x8000
 		bank := addr / 0x2000
@@ -91,7 +91,7 @@ func (m *Mapper4) WriteMem(addr uint16, data byte) {
 		m.cartridge.CHR[m.CHROffsets[bank]+offset] = data
 	case 0x6000 <= addr && addr < 0x8000:
 		addr -= 0x6000
-		m.cartridge.SRAM[addr] = data
+		m.cartridge.WriteSRAM(addr, data)
 	case 0x8000 <= addr && addr < 0xA000:
 		if addr%2 == 0 {
 			// Bank select
@@ -0,0 +1,426 @@
+package cartridge
+
+import (
+	"log/slog"
+
+	"gabe565.com/gones/internal/log"
+)
+
+// mmc5IdleCycles is how many CPU cycles the PPU can go without fetching before MMC5 considers the frame finished.
+// The longest gap while rendering is the sprite fetch window, which is about 21 CPU cycles.
+const mmc5IdleCycles = 32
+
+func NewMapper5(cartridge *Cartridge) *Mapper5 {
+	if !cartridge.Header.NESv2() && len(cartridge.SRAM) < 0x10000 {
+		// iNES headers can't describe boards with more than 8 KiB of PRG-RAM
+		cartridge.SRAM = make([]byte, 0x10000)
+	}
+	mapper := &Mapper5{
+		cartridge: cartridge,
+		PRGMode:   3,
+		CHRMode:   3,
+	}
+	mapper.PRGRegs[4] = 0xFF
+	mapper.updatePRG()
+	mapper.updateCHR()
+	return mapper
+}
+
+type Mapper5 struct {
+	cartridge *Cartridge
+
+	PRGMode    byte
+	PRGRegs    [5]byte
+	PRGOffsets [5]int
+	PRGROM     [5]bool
+	RAMProtect [2]byte
+
+	CHRMode     byte
+	CHRUpper    byte
+	CHRRegs     [12]uint16
+	SpriteCHR   [8]int
+	BgCHR       [8]int
+	LastCHRBg   bool
+	TallSprites bool
+
+	ExRAMMode  byte
+	ExRAM      [0x400]byte
+	Nametables byte
+	FillTile   byte
+	FillAttr   byte
+
+	SplitMode   byte
+	SplitScroll byte
+	SplitBank   byte
+
+	IRQTarget  byte
+	IRQEnabled bool
+	IRQPending bool
+	InFrame    bool
+	Scanline   byte
+	Idle       uint
+
+	Multiplicand byte
+	Multiplier   byte
+
+	// Tile is the index of the next background tile fetched on the current scanline
+	Tile       byte
+	TileSplit  bool
+	TileExAttr byte
+
+	Sound MMC5Audio
+}
+
+func (m *Mapper5) Cartridge() *Cartridge { return m.cartridge }
+
+func (m *Mapper5) SetCartridge(c *Cartridge) { m.cartridge = c }
+
+func (m *Mapper5) OnCPUStep(cycles uint) {
+	m.Idle += cycles
+	if m.Idle > mmc5IdleCycles {
+		m.InFrame = false
+	}
+}
+
+func (m *Mapper5) OnScanline() {
+	m.Tile = 0
+	if !m.InFrame {
+		m.InFrame = true
+		m.Scanline = 0
+		return
+	}
+	m.Scanline++
+	if m.Scanline == m.IRQTarget {
+		m.IRQPending = true
+	}
+}
+
+func (m *Mapper5) IRQ() bool {
+	return m.IRQEnabled && m.IRQPending || m.Sound.irq()
+}
+
+func (m *Mapper5) OnPPUWrite(addr uint16, data byte) {
+	switch addr {
+	case 0x2000:
+		m.TallSprites = data&0x20 != 0
+	case 0x2001:
+		if data&0x18 == 0 {
+			m.InFrame = false
+		}
+	}
+}
+
+func (m *Mapper5) StepAudio() { m.Sound.step() }
+
+func (m *Mapper5) Audio(mix *AudioMix) float32 {
+	return m.Sound.output(mix.Channels) * mix.Volume[ChipMMC5]
+}
+
+func (m *Mapper5) ReadMem(addr uint16) byte {
+	switch {
+	case addr < 0x2000:
+		if m.LastCHRBg {
+			return m.readCHR(&m.BgCHR, addr)
+		}
+		return m.readCHR(&m.SpriteCHR, addr)
+	case addr == 0x5010, addr == 0x5015:
+		return m.Sound.Read(addr)
+	case addr == 0x5204:
+		data := m.irqStatus()
+		m.IRQPending = false
+		return data
+	case addr == 0x5205:
+		return byte(uint16(m.Multiplicand) * uint16(m.Multiplier))
+	case addr == 0x5206:
+		return byte(uint16(m.Multiplicand) * uint16(m.Multiplier) >> 8)
+	case 0x5C00 <= addr && addr < 0x6000:
+		if m.ExRAMMode >= 2 {
+			return m.ExRAM[addr-0x5C00]
+		}
+		// open bus
+		return 0
+	case 0x6000 <= addr:
+		data, rom := m.readPRG(addr)
+		if rom && 0x8000 <= addr && addr < 0xC000 {
+			m.Sound.readPRG(data)
+		}
+		return data
+	case 0x5000 <= addr:
+		// open bus
+		return 0
+	default:
+		slog.Error("Invalid mapper 5 read", "addr", log.HexAddr(addr))
+		return 0
+	}
+}
+
+// ReadMemSafe reads memory without acknowledging IRQs or sending PRG reads to the PCM channel.
+func (m *Mapper5) ReadMemSafe(addr uint16) byte {
+	switch {
+	case addr == 0x5010:
+		return m.Sound.Peek(addr)
+	case addr == 0x5204:
+		return m.irqStatus()
+	case 0x6000 <= addr:
+		data, _ := m.readPRG(addr)
+		return data
+	default:
+		return m.ReadMem(addr)
+	}
+}
+
+// irqStatus returns the value of $5204.
+func (m *Mapper5) irqStatus() byte {
+	var data byte
+	if m.IRQPending {
+		data |= 0x80
+	}
+	if m.InFrame {
+		data |= 0x40
+	}
+	return data
+}
+
+// readPRG reads $6000-$FFFF. rom reports whether the address is mapped to PRG-ROM.
+func (m *Mapper5) readPRG(addr uint16) (data byte, rom bool) {
+	slot := (addr - 0x6000) / 0x2000
+	offset := m.PRGOffsets[slot] + int(addr%0x2000)
+	if !m.PRGROM[slot] {
+		return m.cartridge.ReadSRAM(uint16(offset)), false
+	}
+	return m.cartridge.PRG[offset], true
+}
+
+func (m *Mapper5) WriteMem(addr uint16, data byte) {
+	switch {
+	case addr < 0x2000:
+		if m.LastCHRBg {
+			m.writeCHR(&m.BgCHR, addr, data)
+		} else {
+			m.writeCHR(&m.SpriteCHR, addr, data)
+		}
+	case 0x5000 <= addr && addr <= 0x5015:
+		m.Sound.Write(addr, data)
+	case addr == 0x5100:
+		m.PRGMode = data & 3
+		m.updatePRG()
+	case addr == 0x5101:
+		m.CHRMode = data & 3
+		m.updateCHR()
+	case addr == 0x5102, addr == 0x5103:
+		m.RAMProtect[addr-0x5102] = data & 3
+	case addr == 0x5104:
+		m.ExRAMMode = data & 3
+	case addr == 0x5105:
+		m.Nametables = data
+	case addr == 0x5106:
+		m.FillTile = data
+	case addr == 0x5107:
+		m.FillAttr = data & 3
+	case 0x5113 <= addr && addr <= 0x5117:
+		m.PRGRegs[addr-0x5113] = data
+		m.updatePRG()
+	case 0x5120 <= addr && addr <= 0x512B:
+		i := addr - 0x5120
+		m.CHRRegs[i] = uint16(m.CHRUpper)<<8 | uint16(data)
+		m.LastCHRBg = i >= 8
+		m.updateCHR()
+	case addr == 0x5130:
+		m.CHRUpper = data & 3
+	case addr == 0x5200:
+		m.SplitMode = data
+	case addr == 0x5201:
+		m.SplitScroll = data
+	case addr == 0x5202:
+		m.SplitBank = data
+	case addr == 0x5203:
+		m.IRQTarget = data
+	case addr == 0x5204:
+		m.IRQEnabled = data&0x80 != 0
+	case addr == 0x5205:
+		m.Multiplicand = data
+	case addr == 0x5206:
+		m.Multiplier = data
+	case 0x5C00 <= addr && addr < 0x6000:
+		switch m.ExRAMMode {
+		case 0, 1:
+			// Writes are only possible while rendering, otherwise 0 is written
+			if !m.InFrame {
+				data = 0
+			}
+			m.ExRAM[addr-0x5C00] = data
+		case 2:
+			m.ExRAM[addr-0x5C00] = data
+		}
+	case 0x6000 <= addr:
+		slot := (addr - 0x6000) / 0x2000
+		if !m.PRGROM[slot] && m.RAMProtect == [2]byte{2, 1} {
+			m.cartridge.WriteSRAM(uint16(m.PRGOffsets[slot]+int(addr%0x2000)), data)
+		}
+	case 0x5000 <= addr:
+		//
+	default:
+		slog.Error("Invalid mapper 5 write", "addr", log.HexAddr(addr))
+	}
+}
+
+func (m *Mapper5) OnPPUFetch(fetch PPUFetch, addr uint16) (byte, bool) {
+	m.Idle = 0
+	switch fetch {
+	case FetchNametable:
+		col := m.Tile
+		m.Tile++
+		m.TileSplit = m.inSplit(col)
+		if m.TileSplit {
+			y := m.splitY()
+			return m.ExRAM[y/8*32+uint16(col%32)], true
+		}
+		if m.ExRAMMode == 1 {
+			m.TileExAttr = m.ExRAM[addr&0x3FF]
+		}
+	case FetchAttribute:
+		if m.TileSplit {
+			y, col := m.splitY(), uint16((m.Tile-1)%32)
+			attr := m.ExRAM[0x3C0+y/32*8+col/4]
+			shift := y/16&1*4 + col/2&1*2
+			return attr >> shift & 3 * 0x55, true
+		}
+		if m.ExRAMMode == 1 {
+			return m.TileExAttr >> 6 * 0x55, true
+		}
+	case FetchBackground:
+		switch {
+		case m.TileSplit:
+			offset := int(m.SplitBank)*0x1000 + int(addr&0xFF8) + int(m.splitY()%8)
+			return m.cartridge.CHR[offset%len(m.cartridge.CHR)], true
+		case m.ExRAMMode == 1:
+			bank := int(m.CHRUpper)<<6 | int(m.TileExAttr&0x3F)
+			offset := bank*0x1000 + int(addr&0xFFF)
+			return m.cartridge.CHR[offset%len(m.cartridge.CHR)], true
+		case m.TallSprites:
+			return m.readCHR(&m.BgCHR, addr), true
+		}
+	case FetchSprite:
+		if m.TallSprites {
+			return m.readCHR(&m.SpriteCHR, addr), true
+		}
+	}
+	return 0, false
+}
+
+func (m *Mapper5) ReadNametable(vram []byte, addr uint16) byte {
+	offset := addr & 0x3FF
+	switch nt := m.nametable(addr); nt {
+	case 0, 1:
+		return vram[uint16(nt)*0x400+offset]
+	case 2:
+		if m.ExRAMMode < 2 {
+			return m.ExRAM[offset]
+		}
+		return 0
+	default:
+		if offset >= 0x3C0 {
+			return m.FillAttr * 0x55
+		}
+		return m.FillTile
+	}
+}
+
+func (m *Mapper5) WriteNametable(vram []byte, addr uint16, data byte) {
+	offset := addr & 0x3FF
+	switch nt := m.nametable(addr); nt {
+	case 0, 1:
+		vram[uint16(nt)*0x400+offset] = data
+	case 2:
+		if m.ExRAMMode < 2 {
+			m.ExRAM[offset] = data
+		}
+	}
+}
+
+// nametable returns the source for a nametable address.
+// 0 and 1 are the console's VRAM pages, 2 is ExRAM, and 3 is fill mode.
+func (m *Mapper5) nametable(addr uint16) byte {
+	return m.Nametables >> (addr >> 10 & 3 * 2) & 3
+}
+
+func (m *Mapper5) inSplit(col byte) bool {
+	if m.SplitMode&0x80 == 0 || m.ExRAMMode >= 2 {
+		return false
+	}
+	count := m.SplitMode & 0x1F
+	if m.SplitMode&0x40 != 0 {
+		return col >= count
+	}
+	return col < count
+}
+
+func (m *Mapper5) splitY() uint16 {
+	return (uint16(m.SplitScroll) + uint16(m.Scanline)) % 240
+}
+
+func (m *Mapper5) updatePRG() {
+	// $6000 is always RAM
+	m.setPRG(0, m.PRGRegs[0]&0x7F)
+	switch m.PRGMode {
+	case 0:
+		bank := m.PRGRegs[4]&0x7C | 0x80
+		m.setPRG(1, bank)
+		m.setPRG(2, bank|1)
+		m.setPRG(3, bank|2)
+		m.setPRG(4, bank|3)
+	case 1:
+		bank := m.PRGRegs[2] & 0xFE
+		m.setPRG(1, bank)
+		m.setPRG(2, bank|1)
+		bank = m.PRGRegs[4]&0x7E | 0x80
+		m.setPRG(3, bank)
+		m.setPRG(4, bank|1)
+	case 2:
+		bank := m.PRGRegs[2] & 0xFE
+		m.setPRG(1, bank)
+		m.setPRG(2, bank|1)
+		m.setPRG(3, m.PRGRegs[3])
+		m.setPRG(4, m.PRGRegs[4]|0x80)
+	case 3:
+		m.setPRG(1, m.PRGRegs[1])
+		m.setPRG(2, m.PRGRegs[2])
+		m.setPRG(3, m.PRGRegs[3])
+		m.setPRG(4, m.PRGRegs[4]|0x80)
+	}
+}
+
+// setPRG maps an 8 KiB bank into a slot. Bit 7 of the bank selects ROM, otherwise PRG-RAM is mapped.
+func (m *Mapper5) setPRG(slot int, bank byte) {
+	m.PRGROM[slot] = bank&0x80 != 0
+	if m.PRGROM[slot] {
+		m.PRGOffsets[slot] = int(bank&0x7F) * 0x2000 % len(m.cartridge.PRG)
+	} else {
+		m.PRGOffsets[slot] = int(bank&7) * 0x2000
+	}
+}
+
+func (m *Mapper5) updateCHR() {
+	size := 8 >> m.CHRMode
+	for slot := range 8 {
+		// Each bank uses the last register in its group
+		reg := slot | (size - 1)
+		m.SpriteCHR[slot] = m.chrOffset(m.CHRRegs[reg], size, slot)
+		// Background banks repeat in both pattern tables
+		m.BgCHR[slot] = m.chrOffset(m.CHRRegs[8+reg&3], size, slot)
+	}
+}
+
+// chrOffset returns the CHR offset of a 1 KiB slot within a bank of size KiB.
+func (m *Mapper5) chrOffset(bank uint16, size, slot int) int {
+	offset := (int(bank)*size + slot%size) * 0x400
+	return offset % len(m.cartridge.CHR)
+}
+
+func (m *Mapper5) readCHR(banks *[8]int, addr uint16) byte {
+	return m.cartridge.CHR[banks[addr/0x400]+int(addr%0x400)]
+}
+
+func (m *Mapper5) writeCHR(banks *[8]int, addr uint16, data byte) {
+	m.cartridge.CHR[banks[addr/0x400]+int(addr%0x400)] = data
+}
@@ -0,0 +1,230 @@
+package cartridge
+
+const (
+	// mmc5FrameCycles is the number of CPU cycles between envelope and length counter clocks (240 Hz).
+	mmc5FrameCycles = 7457
+
+	// mmc5PCMGain scales 8-bit PCM to roughly the range of the APU DMC.
+	mmc5PCMGain = 0.00335 / 2
+)
+
+//nolint:gochecknoglobals
+var (
+	lengthTable = [...]byte{
+		10, 254, 20, 2, 40, 4, 80, 6, 160, 8, 60, 10, 14, 12, 26, 14,
+		12, 16, 24, 18, 48, 20, 96, 22, 192, 24, 72, 26, 16, 28, 32, 30,
+	}
+	pulseDutyTable = [...][8]byte{
+		{0, 1, 0, 0, 0, 0, 0, 0},
+		{0, 1, 1, 0, 0, 0, 0, 0},
+		{0, 1, 1, 1, 1, 0, 0, 0},
+		{1, 0, 0, 1, 1, 1, 1, 1},
+	}
+)
+
+// MMC5Audio is the MMC5 expansion audio: two pulse channels without sweep units, and an 8-bit PCM channel.
+type MMC5Audio struct {
+	Pulse [2]MMC5Pulse
+	Cycle uint
+
+	PCM           byte
+	PCMRead       bool
+	PCMIRQEnabled bool
+	PCMIRQPending bool
+}
+
+func (a *MMC5Audio) Write(addr uint16, data byte) {
+	switch {
+	case addr <= 0x5007:
+		a.Pulse[(addr-0x5000)/4].Write(addr%4, data)
+	case addr == 0x5010:
+		a.PCMRead = data&1 == 1
+		a.PCMIRQEnabled = data&0x80 != 0
+	case addr == 0x5011:
+		// Writing 0 has no effect
+		if !a.PCMRead && data != 0 {
+			a.PCM = data
+		}
+	case addr == 0x5015:
+		a.Pulse[0].SetEnabled(data&1 != 0)
+		a.Pulse[1].SetEnabled(data&2 != 0)
+	}
+}
+
+// Read reads the PCM status ($5010) or the pulse status ($5015). Reading $5010 acknowledges the PCM IRQ.
+func (a *MMC5Audio) Read(addr uint16) byte {
+	data := a.Peek(addr)
+	if addr == 0x5010 {
+		a.PCMIRQPending = false
+	}
+	return data
+}
+
+// Peek reads a register like [MMC5Audio.Read], without acknowledging the PCM IRQ.
+func (a *MMC5Audio) Peek(addr uint16) byte {
+	var data byte
+	switch addr {
+	case 0x5010:
+		if a.PCMIRQPending {
+			data |= 0x80
+		}
+		if a.PCMRead {
+			data |= 1
+		}
+	case 0x5015:
+		if a.Pulse[0].LengthValue > 0 {
+			data |= 1
+		}
+		if a.Pulse[1].LengthValue > 0 {
+			data |= 2
+		}
+	}
+	return data
+}
+
+// readPRG is called when the CPU reads $8000-$BFFF. In read mode, the value is sent to the PCM channel.
+func (a *MMC5Audio) readPRG(data byte) {
+	if !a.PCMRead {
+		return
+	}
+	if data == 0 {
+		a.PCMIRQPending = true
+	} else {
+		a.PCM = data
+	}
+}
+
+func (a *MMC5Audio) irq() bool {
+	return a.PCMIRQEnabled && a.PCMIRQPending
+}
+
+func (a *MMC5Audio) step() {
+	a.Cycle++
+	if a.Cycle%2 == 0 {
+		a.Pulse[0].stepTimer()
+		a.Pulse[1].stepTimer()
+	}
+	if a.Cycle%mmc5FrameCycles == 0 {
+		for i := range a.Pulse {
+			a.Pulse[i].stepEnvelope()
+			a.Pulse[i].stepLength()
+		}
+	}
+}
+
+func (a *MMC5Audio) output(enabled AudioChannel) float32 {
+	var pulse byte
+	if enabled&ChannelMMC5Pulse1 != 0 {
+		pulse += a.Pulse[0].output()
+	}
+	if enabled&ChannelMMC5Pulse2 != 0 {
+		pulse += a.Pulse[1].output()
+	}
+	out := float32(pulse) * ExpansionGain
+	if enabled&ChannelMMC5PCM != 0 {
+		out += float32(a.PCM) * mmc5PCMGain
+	}
+	return out
+}
+
+// MMC5Pulse is an MMC5 pulse channel. It behaves like an APU pulse channel,
+// except that it has no sweep unit and is not silenced at high frequencies.
+type MMC5Pulse struct {
+	Enabled bool
+
+	DutyMode  byte
+	DutyValue byte
+
+	EnvelopeEnabled bool
+	EnvelopePeriod  byte
+	EnvelopeLoop    bool
+	EnvelopeStart   bool
+	EnvelopeVol     byte
+	EnvelopeValue   byte
+
+	Volume byte
+
+	LengthEnabled bool
+	LengthValue   byte
+
+	TimerPeriod uint16
+	TimerValue  uint16
+}
+
+// Write writes to one of the channel's 4 registers.
+func (p *MMC5Pulse) Write(reg uint16, data byte) {
+	switch reg {
+	case 0:
+		p.DutyMode = data >> 6 & 3
+		p.LengthEnabled = data>>5&1 == 0
+		p.EnvelopeLoop = data>>5&1 == 1
+		p.EnvelopeEnabled = data>>4&1 == 0
+		p.Volume = data & 0xF
+		p.EnvelopePeriod = data & 0xF
+	case 2:
+		p.TimerPeriod = p.TimerPeriod&0x700 | uint16(data)
+	case 3:
+		if p.Enabled {
+			p.LengthValue = lengthTable[data>>3&0x1F]
+		}
+		p.TimerPeriod = uint16(data)&0x7<<8 | p.TimerPeriod&0xFF
+		p.EnvelopeStart = true
+		p.DutyValue = 0
+	}
+}
+
+func (p *MMC5Pulse) SetEnabled(v bool) {
+	p.Enabled = v
+	if !v {
+		p.LengthValue = 0
+	}
+}
+
+func (p *MMC5Pulse) stepTimer() {
+	if p.TimerValue == 0 {
+		p.TimerValue = p.TimerPeriod
+		p.DutyValue++
+		p.DutyValue %= 8
+	} else {
+		p.TimerValue--
+	}
+}
+
+func (p *MMC5Pulse) stepEnvelope() {
+	switch {
+	case p.EnvelopeStart:
+		p.EnvelopeVol = 15
+		p.EnvelopeValue = p.EnvelopePeriod
+		p.EnvelopeStart = false
+	case p.EnvelopeValue > 0:
+		p.EnvelopeValue--
+	default:
+		if p.EnvelopeVol > 0 {
+			p.EnvelopeVol--
+		} else if p.EnvelopeLoop {
+			p.EnvelopeVol = 15
+		}
+		p.EnvelopeValue = p.EnvelopePeriod
+	}
+}
+
+func (p *MMC5Pulse) stepLength() {
+	if p.LengthEnabled && p.LengthValue > 0 {
+		p.LengthValue--
+	}
+}
+
+func (p *MMC5Pulse) output() byte {
+	switch {
+	case !p.Enabled:
+		return 0
+	case p.LengthValue == 0:
+		return 0
+	case pulseDutyTable[p.DutyMode][p.DutyValue] == 0:
+		return 0
+	case p.EnvelopeEnabled:
+		return p.EnvelopeVol
+	default:
+		return p.Volume
+	}
+}
@@ -0,0 +1,182 @@
+package cartridge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMapper5Cart() *Cartridge {
+	cart := New()
+	cart.PRG = make([]byte, 16*0x2000)
+	for i := range len(cart.PRG) / 0x2000 {
+		cart.PRG[i*0x2000] = byte(i)
+	}
+	cart.CHR = make([]byte, 64*0x400)
+	for i := range len(cart.CHR) / 0x400 {
+		cart.CHR[i*0x400] = byte(i)
+	}
+	return cart
+}
+
+// audio returns a mapper's expansion audio output for the given channels at full volume.
+func audio(m MapperAudio, channels AudioChannel) float32 {
+	mix := NewAudioMix(channels)
+	return m.Audio(&mix)
+}
+
+func TestMapper5_PRG(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		mode  byte
+		regs  map[uint16]byte
+		banks [4]byte
+	}{
+		{"power on", 3, nil, [4]byte{0, 0, 0, 15}},
+		{"mode 0", 0, map[uint16]byte{0x5117: 0x84}, [4]byte{4, 5, 6, 7}},
+		{"mode 1", 1, map[uint16]byte{0x5115: 0x82, 0x5117: 0x8A}, [4]byte{2, 3, 10, 11}},
+		{"mode 2", 2, map[uint16]byte{0x5115: 0x84, 0x5116: 0x89, 0x5117: 0x8C}, [4]byte{4, 5, 9, 12}},
+		{"mode 3", 3, map[uint16]byte{0x5114: 0x81, 0x5115: 0x82, 0x5116: 0x83, 0x5117: 0x84}, [4]byte{1, 2, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			m := NewMapper5(newMapper5Cart())
+			m.WriteMem(0x5100, tt.mode)
+			for addr, data := range tt.regs {
+				m.WriteMem(addr, data)
+			}
+			for i, bank := range tt.banks {
+				assert.Equal(t, bank, m.ReadMem(0x8000+uint16(i)*0x2000), "slot %d", i)
+			}
+		})
+	}
+}
+
+func TestMapper5_PRGRAM(t *testing.T) {
+	t.Parallel()
+
+	m := NewMapper5(newMapper5Cart())
+	m.WriteMem(0x5114, 0x02)
+
+	m.WriteMem(0x8000, 0x12)
+	assert.Zero(t, m.ReadMem(0x8000), "writes are protected by default")
+
+	m.WriteMem(0x5102, 2)
+	m.WriteMem(0x5103, 1)
+	m.WriteMem(0x8000, 0x12)
+	assert.EqualValues(t, 0x12, m.ReadMem(0x8000))
+
+	m.WriteMem(0x5113, 0x02)
+	assert.EqualValues(t, 0x12, m.ReadMem(0x6000), "RAM bank is shared with $6000")
+}
+
+func TestMapper5_CHR(t *testing.T) {
+	t.Parallel()
+
+	m := NewMapper5(newMapper5Cart())
+	m.WriteMem(0x5101, 1)
+	m.WriteMem(0x5123, 2)
+	m.WriteMem(0x5127, 3)
+	m.WriteMem(0x512B, 5)
+	assert.EqualValues(t, 20, m.ReadMem(0x0000), "last written bank set is used")
+
+	m.OnPPUWrite(0x2000, 0x20)
+	data, ok := m.OnPPUFetch(FetchSprite, 0x1000)
+	assert.True(t, ok)
+	assert.EqualValues(t, 12, data)
+	data, ok = m.OnPPUFetch(FetchBackground, 0x1000)
+	assert.True(t, ok)
+	assert.EqualValues(t, 20, data)
+}
+
+func TestMapper5_Nametables(t *testing.T) {
+	t.Parallel()
+
+	m := NewMapper5(newMapper5Cart())
+	vram := make([]byte, 0x800)
+	m.WriteMem(0x5105, 0b11_10_01_00)
+	m.WriteMem(0x5106, 0x42)
+	m.WriteMem(0x5107, 2)
+
+	m.WriteNametable(vram, 0x2000, 1)
+	m.WriteNametable(vram, 0x2400, 2)
+	m.WriteNametable(vram, 0x2800, 3)
+	assert.Equal(t, []byte{1, 2}, []byte{vram[0], vram[0x400]})
+	assert.EqualValues(t, 3, m.ExRAM[0])
+	assert.EqualValues(t, 3, m.ReadNametable(vram, 0x2800))
+	assert.EqualValues(t, 0x42, m.ReadNametable(vram, 0x2C00))
+	assert.EqualValues(t, 0xAA, m.ReadNametable(vram, 0x2FC0))
+}
+
+func TestMapper5_IRQ(t *testing.T) {
+	t.Parallel()
+
+	m := NewMapper5(newMapper5Cart())
+	m.WriteMem(0x5203, 3)
+	m.WriteMem(0x5204, 0x80)
+
+	// Pre-render line starts the frame
+	m.OnScanline()
+	assert.EqualValues(t, 0x40, m.ReadMem(0x5204))
+
+	for range 2 {
+		m.OnScanline()
+		assert.False(t, m.IRQ())
+	}
+	m.OnScanline()
+	assert.True(t, m.IRQ())
+	assert.EqualValues(t, 0xC0, m.ReadMemSafe(0x5204))
+	assert.True(t, m.IRQ(), "safe reads have no side effects")
+	assert.EqualValues(t, 0xC0, m.ReadMem(0x5204))
+	assert.False(t, m.IRQ(), "reading status acknowledges the IRQ")
+
+	m.OnCPUStep(mmc5IdleCycles + 1)
+	assert.Zero(t, m.ReadMem(0x5204), "idle PPU ends the frame")
+}
+
+func TestMapper5_Multiplier(t *testing.T) {
+	t.Parallel()
+
+	m := NewMapper5(newMapper5Cart())
+	m.WriteMem(0x5205, 0xFE)
+	m.WriteMem(0x5206, 0x13)
+	assert.EqualValues(t, 0x12DA, uint16(m.ReadMem(0x5206))<<8|uint16(m.ReadMem(0x5205)))
+}
+
+func TestMapper5_Audio(t *testing.T) {
+	t.Parallel()
+
+	m := NewMapper5(newMapper5Cart())
+	m.WriteMem(0x5015, 1)
+	m.WriteMem(0x5000, 0xBF)
+	m.WriteMem(0x5002, 0x10)
+	m.WriteMem(0x5003, 0x08)
+	assert.EqualValues(t, 1, m.ReadMem(0x5015))
+
+	var loud bool
+	for range 0x100 {
+		m.StepAudio()
+		if audio(m, ChannelMMC5Pulse1) != 0 {
+			loud = true
+		}
+		assert.Zero(t, audio(m, ChannelMMC5Pulse2))
+	}
+	assert.True(t, loud)
+
+	m.WriteMem(0x5011, 0x80)
+	assert.InDelta(t, 0x80*mmc5PCMGain, audio(m, ChannelMMC5PCM), 0.0001)
+
+	m.WriteMem(0x5114, 0x80)
+	m.WriteMem(0x5010, 0x81)
+	m.ReadMemSafe(0x8000)
+	assert.False(t, m.IRQ(), "safe reads are not sent to the PCM channel")
+	m.ReadMem(0x8000)
+	assert.True(t, m.IRQ(), "reading 0 in PCM read mode raises an IRQ")
+	assert.EqualValues(t, 0x81, m.ReadMemSafe(0x5010))
+	assert.True(t, m.IRQ())
+	assert.EqualValues(t, 0x81, m.ReadMem(0x5010))
+	assert.False(t, m.IRQ())
+}
@@ -12,6 +12,7 @@ func NewMapper69(cartridge *Cartridge) *Mapper69 {
 		cartridge: cartridge,
 		PRGCount:  byte(prgCount),
 		PRGBanks:  [5]int{0, 0, 0, 0, prgCount - 1},
+		Sound:     NewSunsoft5B(),
 	}
 	return mapper
 }
@@ -31,6 +32,8 @@ type Mapper69 struct {
 	IRQCounterEnabled bool   `msgpack:"alias:IrqCounterEnable"`
 	IRQCounter        uint16 `msgpack:"alias:IrqCounter"`
 	IRQPending        bool   `msgpack:"alias:IrqPending"`
+
+	Sound Sunsoft5B
 }
 
 func (m *Mapper69) Cartridge() *Cartridge { return m.cartridge }
@@ -49,6 +52,12 @@ func (m *Mapper69) OnCPUStep(cycles uint) {
 
 func (m *Mapper69) IRQ() bool { return m.IRQPending }
 
+func (m *Mapper69) StepAudio() { m.Sound.step() }
+
+func (m *Mapper69) Audio(mix *AudioMix) float32 {
+	return m.Sound.output(mix.Channels) * mix.Volume[ChipSunsoft5B]
+}
+
 func (m *Mapper69) ReadMem(addr uint16) byte {
 	switch {
 	case addr < 0x2000:
@@ -61,7 +70,7 @@ func (m *Mapper69) ReadMem(addr uint16) byte {
 		// PRG/SRAM banks
 		if m.RAMSelect {
 			if m.RAMEnabled {
-				return m.cartridge.SRAM[addr-0x6000]
+				return m.cartridge.ReadSRAM(addr - 0x6000)
 			}
 			// open bus
 			return 0
@@ -84,7 +93,7 @@ func (m *Mapper69) WriteMem(addr uint16, data byte) {
 	case 0x6000 <= addr && addr < 0x8000:
 		// SRAM register
 		if m.RAMSelect && m.RAMEnabled {
-			m.cartridge.SRAM[addr-0x6000] = data
+			m.cartridge.WriteSRAM(addr-0x6000, data)
 		}
 	case 0x8000 <= addr && addr < 0xA000:
 		// Command register
@@ -92,6 +101,12 @@ func (m *Mapper69) WriteMem(addr uint16, data byte) {
 	case 0xA000 <= addr && addr < 0xC000:
 		// Parameter register
 		m.runCommand(data)
+	case 0xC000 <= addr && addr < 0xE000:
+		// Audio register select
+		m.Sound.Select(data)
+	case 0xE000 <= addr:
+		// Audio register write
+		m.Sound.Write(data)
 	default:
 		slog.Error("Invalid mapper 69 write", "addr", log.HexAddr(addr))
 	}
@@ -0,0 +1,206 @@
+package cartridge
+
+import "math"
+
+const (
+	// sunsoft5BDivider is the number of CPU cycles per tone, noise, and envelope clock.
+	sunsoft5BDivider = 16
+	// sunsoft5BGain scales a channel at full volume to roughly the level of a full volume APU pulse.
+	sunsoft5BGain = 15 * ExpansionGain
+)
+
+// sunsoft5BLevels maps 5-bit volumes to output levels. The DAC is logarithmic, with 1.5 dB per step.
+//
+//nolint:gochecknoglobals
+var sunsoft5BLevels [32]float32
+
+func init() { //nolint:gochecknoinits
+	for i := 1; i < len(sunsoft5BLevels); i++ {
+		sunsoft5BLevels[i] = float32(math.Pow(10, float64(i-31)*1.5/20))
+	}
+}
+
+// Sunsoft5B is the Sunsoft 5B expansion audio, a YM2149 variant with 3 square channels,
+// a noise generator, and an envelope generator.
+type Sunsoft5B struct {
+	Register byte
+	Divider  byte
+
+	Channels [3]Sunsoft5BChannel
+	// Mixer disables tone (bits 0-2) and noise (bits 3-5) for each channel
+	Mixer byte
+
+	NoisePeriod  byte
+	NoiseCounter byte
+	NoiseShift   uint32
+	NoiseHalf    bool
+
+	Envelope Sunsoft5BEnvelope
+}
+
+func NewSunsoft5B() Sunsoft5B {
+	return Sunsoft5B{NoiseShift: 1}
+}
+
+// Select writes the register select port ($C000-$DFFF).
+func (a *Sunsoft5B) Select(data byte) {
+	a.Register = data & 0xF
+}
+
+// Write writes to the selected register ($E000-$FFFF).
+func (a *Sunsoft5B) Write(data byte) {
+	switch a.Register {
+	case 0x0, 0x2, 0x4:
+		ch := &a.Channels[a.Register/2]
+		ch.Period = ch.Period&0xF00 | uint16(data)
+	case 0x1, 0x3, 0x5:
+		ch := &a.Channels[a.Register/2]
+		ch.Period = uint16(data&0xF)<<8 | ch.Period&0xFF
+	case 0x6:
+		a.NoisePeriod = data & 0x1F
+	case 0x7:
+		a.Mixer = data
+	case 0x8, 0x9, 0xA:
+		a.Channels[a.Register-0x8].Volume = data & 0x1F
+	case 0xB:
+		a.Envelope.Period = a.Envelope.Period&0xFF00 | uint16(data)
+	case 0xC:
+		a.Envelope.Period = uint16(data)<<8 | a.Envelope.Period&0xFF
+	case 0xD:
+		a.Envelope.Shape = data & 0xF
+		a.Envelope.restart()
+	}
+}
+
+func (a *Sunsoft5B) step() {
+	a.Divider++
+	if a.Divider < sunsoft5BDivider {
+		return
+	}
+	a.Divider = 0
+
+	for i := range a.Channels {
+		a.Channels[i].step()
+	}
+
+	// Noise is clocked at half rate
+	a.NoiseHalf = !a.NoiseHalf
+	if a.NoiseHalf {
+		a.NoiseCounter++
+		if a.NoiseCounter >= max(a.NoisePeriod, 1) {
+			a.NoiseCounter = 0
+			bit := (a.NoiseShift ^ a.NoiseShift>>3) & 1
+			a.NoiseShift = a.NoiseShift>>1 | bit<<16
+		}
+	}
+
+	a.Envelope.step()
+}
+
+func (a *Sunsoft5B) output(enabled AudioChannel) float32 {
+	var out float32
+	for i, ch := range a.Channels {
+		if enabled&(ChannelSunsoft5BA<<i) == 0 {
+			continue
+		}
+		tone := ch.Tone || a.Mixer>>i&1 != 0
+		noise := a.NoiseShift&1 != 0 || a.Mixer>>(i+3)&1 != 0
+		if !tone || !noise {
+			continue
+		}
+
+		var level byte
+		switch {
+		case ch.Volume&0x10 != 0:
+			level = a.Envelope.value()
+		case ch.Volume != 0:
+			level = ch.Volume*2 + 1
+		}
+		out += sunsoft5BLevels[level]
+	}
+	return out * sunsoft5BGain
+}
+
+// Sunsoft5BChannel is a Sunsoft 5B square channel.
+type Sunsoft5BChannel struct {
+	Period  uint16
+	Counter uint16
+	Tone    bool
+	// Volume is a 4-bit volume, or bit 4 to use the envelope
+	Volume byte
+}
+
+func (c *Sunsoft5BChannel) step() {
+	c.Counter++
+	if c.Counter >= max(c.Period, 1) {
+		c.Counter = 0
+		c.Tone = !c.Tone
+	}
+}
+
+// Sunsoft5BEnvelope is the Sunsoft 5B envelope generator. It ramps through 32 levels,
+// and the shape controls whether it stops, holds, restarts, or reverses after each ramp.
+type Sunsoft5BEnvelope struct {
+	Period  uint16
+	Counter uint16
+	Shape   byte
+	Step    byte
+	Attack  bool
+	Holding bool
+}
+
+const (
+	envelopeHold = 1 << iota
+	envelopeAlternate
+	envelopeAttack
+	envelopeContinue
+)
+
+func (e *Sunsoft5BEnvelope) restart() {
+	e.Counter = 0
+	e.Step = 0
+	e.Attack = e.Shape&envelopeAttack != 0
+	e.Holding = false
+}
+
+func (e *Sunsoft5BEnvelope) step() {
+	e.Counter++
+	if e.Counter < max(e.Period, 1) {
+		return
+	}
+	e.Counter = 0
+	if e.Holding {
+		return
+	}
+
+	e.Step++
+	if e.Step < 32 {
+		return
+	}
+	switch {
+	case e.Shape&envelopeContinue == 0:
+		e.Holding = true
+	case e.Shape&envelopeHold != 0:
+		e.Holding = true
+		e.Step = 31
+		if e.Shape&envelopeAlternate != 0 {
+			e.Attack = !e.Attack
+		}
+	default:
+		e.Step = 0
+		if e.Shape&envelopeAlternate != 0 {
+			e.Attack = !e.Attack
+		}
+	}
+}
+
+func (e *Sunsoft5BEnvelope) value() byte {
+	switch {
+	case e.Holding && e.Shape&envelopeContinue == 0:
+		return 0
+	case e.Attack:
+		return e.Step
+	default:
+		return 31 - e.Step
+	}
+}
@@ -0,0 +1,73 @@
+package cartridge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newMapper69() *Mapper69 {
+	cart := New()
+	cart.PRG = make([]byte, 8*0x2000)
+	cart.CHR = make([]byte, 8*0x400)
+	return NewMapper69(cart)
+}
+
+func writeSunsoft5B(m *Mapper69, reg, data byte) {
+	m.WriteMem(0xC000, reg)
+	m.WriteMem(0xE000, data)
+}
+
+func TestMapper69_Audio(t *testing.T) {
+	t.Parallel()
+
+	m := newMapper69()
+	all := ChannelSunsoft5BA | ChannelSunsoft5BB | ChannelSunsoft5BC
+
+	// Channel A tone only, full volume, period 1
+	writeSunsoft5B(m, 0x7, 0b111_110)
+	writeSunsoft5B(m, 0x0, 1)
+	writeSunsoft5B(m, 0x8, 0xF)
+	assert.Zero(t, audio(m, all))
+
+	for range sunsoft5BDivider {
+		m.StepAudio()
+	}
+	assert.InDelta(t, sunsoft5BGain, audio(m, all), 0.0001)
+	assert.Zero(t, audio(m, ChannelSunsoft5BB))
+
+	for range sunsoft5BDivider {
+		m.StepAudio()
+	}
+	assert.Zero(t, audio(m, all))
+}
+
+func TestSunsoft5BEnvelope(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		shape byte
+		first byte
+		after byte
+	}{
+		{"decay once", 0b0000, 31, 0},
+		{"attack once", 0b0100, 0, 0},
+		{"decay repeat", 0b1000, 31, 31},
+		{"decay then high", 0b1011, 31, 31},
+		{"attack and hold", 0b1101, 0, 31},
+		{"triangle", 0b1110, 0, 31},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			e := Sunsoft5BEnvelope{Shape: tt.shape}
+			e.restart()
+			assert.Equal(t, tt.first, e.value())
+			for range 32 {
+				e.step()
+			}
+			assert.Equal(t, tt.after, e.value())
+		})
+	}
+}
@@ -26,9 +26,7 @@ func (m *Mapper7) ReadMem(addr uint16) byte {
 	case addr < 0x2000:
 		return m.cartridge.CHR[addr&0x1FFF]
 	case 0x6000 <= addr && addr < 0x8000:
-		addr := uint(addr)
-		addr -= 0x6000
-		return m.cartridge.SRAM[addr]
+		return m.cartridge.ReadSRAM(addr - 0x6000)
 	case 0x8000 <= addr:
 		addr := uint(addr)
 		addr -= 0x8000
@@ -46,9 +44,7 @@ func (m *Mapper7) WriteMem(addr uint16, data byte) {
 	case addr < 0x2000:
 		m.cartridge.CHR[addr%0x1FFF] = data
 	case 0x6000 <= addr && addr < 0x8000:
-		addr := uint(addr)
-		addr -= 0x6000
-		m.cartridge.SRAM[addr] = data
+		m.cartridge.WriteSRAM(addr-0x6000, data)
 	case 0x8000 <= addr:
 		switch data >> 4 & 1 {
 		case 0:
@@ -0,0 +1,375 @@
+package cartridge
+
+import (
+	"log/slog"
+
+	"gabe565.com/gones/internal/log"
+)
+
+const (
+	// nsfDriverAddr is where the player driver is mapped. $4100-$41FF is unused by the APU and expansion chips.
+	nsfDriverAddr = 0x4100
+	// nsfRTIAddr is the RTI instruction that handles stray NMIs and IRQs.
+	nsfRTIAddr = 0x412D
+
+	nsfRegSong   = 0x41F0
+	nsfRegRegion = 0x41F1
+	// nsfRegPlay reads bit 7 set when the play routine is due. Reading it clears the flag.
+	nsfRegPlay = 0x41F2
+)
+
+// nsfDriver initializes the APU, calls the init routine with the song in A and the region in X,
+// then waits for the play flag and calls the play routine once per period.
+// The init and play addresses are patched in by [MapperNSF.ReadMem].
+//
+//nolint:gochecknoglobals
+var nsfDriver = [...]byte{
+	0x78,       // $4100 SEI
+	0xD8,       // $4101 CLD
+	0xA2, 0xFF, // $4102 LDX #$FF
+	0x9A,       // $4104 TXS
+	0xA9, 0x00, // $4105 LDA #$00
+	0xA2, 0x13, // $4107 LDX #$13
+	0x9D, 0x00, 0x40, // $4109 STA $4000,X
+	0xCA,       // $410C DEX
+	0x10, 0xFA, // $410D BPL $4109
+	0xA9, 0x0F, // $410F LDA #$0F
+	0x8D, 0x15, 0x40, // $4111 STA $4015
+	0xA9, 0x40, // $4114 LDA #$40
+	0x8D, 0x17, 0x40, // $4116 STA $4017
+	0xAD, 0xF0, 0x41, // $4119 LDA $41F0
+	0xAE, 0xF1, 0x41, // $411C LDX $41F1
+	0x20, 0x00, 0x00, // $411F JSR init
+	0x2C, 0xF2, 0x41, // $4122 BIT $41F2
+	0x10, 0xFB, // $4125 BPL $4122
+	0x20, 0x00, 0x00, // $4127 JSR play
+	0x4C, 0x22, 0x41, // $412A JMP $4122
+	0x40, // $412D RTI
+}
+
+// NewMapperNSF creates the player for an NSF file.
+// [MapperNSF.Start] must be called before the driver runs, with the timing of the console's region.
+func NewMapperNSF(cartridge *Cartridge) *MapperNSF {
+	return &MapperNSF{
+		cartridge: cartridge,
+		nsf:       cartridge.NSF,
+	}
+}
+
+// MapperNSF plays an NSF file. It maps a small driver that calls the file's init and play routines,
+// NSF bankswitching, and the expansion audio chips requested by the file.
+type MapperNSF struct {
+	cartridge *Cartridge
+	nsf       *NSF
+
+	Song byte
+	PAL  bool
+
+	// Banks are the 4 KiB banks mapped to $8000-$FFFF
+	Banks [8]byte
+
+	PlayPeriod float64
+	PlayTimer  float64
+	PlayReady  bool
+
+	MMC5         MMC5Audio
+	ExRAM        [0x400]byte
+	Multiplicand byte
+	Multiplier   byte
+
+	VRC6      VRC6Audio
+	Sunsoft5B Sunsoft5B
+	FDS       FDSAudio
+}
+
+func (m *MapperNSF) Cartridge() *Cartridge { return m.cartridge }
+
+func (m *MapperNSF) SetCartridge(c *Cartridge) {
+	m.cartridge = c
+	m.nsf = c.NSF
+}
+
+// Songs returns the number of songs in the file.
+func (m *MapperNSF) Songs() int { return m.nsf.Songs }
+
+// NSF returns the loaded file.
+func (m *MapperNSF) NSF() *NSF { return m.nsf }
+
+// Start resets the mapper to play a 0-based song.
+// The console must be reset afterward to run the driver.
+func (m *MapperNSF) Start(song int, pal bool, cpuFrequency float64) {
+	m.Song = byte(song) //nolint:gosec
+	m.PAL = pal
+
+	speed := m.nsf.SpeedNTSC
+	if pal {
+		speed = m.nsf.SpeedPAL
+	}
+	m.PlayPeriod = float64(speed) * cpuFrequency / 1e6
+	m.PlayTimer = m.PlayPeriod
+	m.PlayReady = false
+
+	m.MMC5 = MMC5Audio{}
+	m.ExRAM = [0x400]byte{}
+	m.Multiplicand, m.Multiplier = 0xFF, 0xFF
+	m.VRC6 = VRC6Audio{}
+	m.Sunsoft5B = NewSunsoft5B()
+	m.FDS = NewFDSAudio()
+
+	clear(m.cartridge.SRAM)
+	switch {
+	case m.nsf.Bankswitched:
+		for i, bank := range m.nsf.Banks {
+			m.writeBank(i, bank)
+		}
+		if m.fds() {
+			// $6000-$7FFF start with the same banks as $E000-$FFFF
+			m.writeBank(-2, m.nsf.Banks[6])
+			m.writeBank(-1, m.nsf.Banks[7])
+		}
+	case m.fds():
+		// PRG starts at $6000, so $E000-$FFFF are banks 8 and 9
+		copy(m.cartridge.SRAM, m.cartridge.PRG)
+		m.Banks[6], m.Banks[7] = 8, 9
+	default:
+		for i := range m.Banks {
+			m.Banks[i] = byte(i)
+		}
+	}
+}
+
+// Chips returns the expansion audio chips that can be played.
+func (m *MapperNSF) Chips() NSFChip { return m.nsf.Chips & NSFChipSupported }
+
+// Channels returns the expansion audio channels of every playable chip.
+func (m *MapperNSF) Channels() AudioChannel {
+	var channels AudioChannel
+	chips := m.Chips()
+	if chips&NSFChipMMC5 != 0 {
+		channels |= ChannelMMC5Pulse1 | ChannelMMC5Pulse2 | ChannelMMC5PCM
+	}
+	if chips&NSFChipVRC6 != 0 {
+		channels |= ChannelVRC6Pulse1 | ChannelVRC6Pulse2 | ChannelVRC6Sawtooth
+	}
+	if chips&NSFChipSunsoft5B != 0 {
+		channels |= ChannelSunsoft5BA | ChannelSunsoft5BB | ChannelSunsoft5BC
+	}
+	if chips&NSFChipFDS != 0 {
+		channels |= ChannelFDS
+	}
+	return channels
+}
+
+func (m *MapperNSF) fds() bool { return m.nsf.Chips&NSFChipFDS != 0 }
+
+func (m *MapperNSF) OnCPUStep(cycles uint) {
+	m.PlayTimer -= float64(cycles)
+	if m.PlayTimer <= 0 {
+		m.PlayTimer += m.PlayPeriod
+		m.PlayReady = true
+	}
+}
+
+func (m *MapperNSF) StepAudio() {
+	chips := m.Chips()
+	if chips&NSFChipMMC5 != 0 {
+		m.MMC5.step()
+	}
+	if chips&NSFChipVRC6 != 0 {
+		m.VRC6.step()
+	}
+	if chips&NSFChipSunsoft5B != 0 {
+		m.Sunsoft5B.step()
+	}
+	if chips&NSFChipFDS != 0 {
+		m.FDS.step()
+	}
+}
+
+func (m *MapperNSF) Audio(mix *AudioMix) float32 {
+	var out float32
+	chips := m.Chips()
+	if chips&NSFChipMMC5 != 0 {
+		out += m.MMC5.output(mix.Channels) * mix.Volume[ChipMMC5]
+	}
+	if chips&NSFChipVRC6 != 0 {
+		out += m.VRC6.output(mix.Channels) * mix.Volume[ChipVRC6]
+	}
+	if chips&NSFChipSunsoft5B != 0 {
+		out += m.Sunsoft5B.output(mix.Channels) * mix.Volume[ChipSunsoft5B]
+	}
+	if chips&NSFChipFDS != 0 {
+		out += m.FDS.output(mix.Channels) * mix.Volume[ChipFDS]
+	}
+	return out
+}
+
+func (m *MapperNSF) ReadMem(addr uint16) byte {
+	chips := m.Chips()
+	switch {
+	case addr < 0x2000:
+		return m.cartridge.CHR[addr]
+	case chips&NSFChipFDS != 0 && 0x4040 <= addr && addr <= 0x4092:
+		return m.FDS.Read(addr)
+	case nsfDriverAddr <= addr && addr <= nsfRTIAddr:
+		return m.readDriver(addr)
+	case addr == nsfRegSong:
+		return m.Song
+	case addr == nsfRegRegion:
+		if m.PAL {
+			return 1
+		}
+		return 0
+	case addr == nsfRegPlay:
+		if m.PlayReady {
+			m.PlayReady = false
+			return 0x80
+		}
+		return 0
+	case chips&NSFChipMMC5 != 0 && (addr == 0x5010 || addr == 0x5015):
+		return m.MMC5.Read(addr)
+	case chips&NSFChipMMC5 != 0 && addr == 0x5205:
+		return byte(uint16(m.Multiplicand) * uint16(m.Multiplier))
+	case chips&NSFChipMMC5 != 0 && addr == 0x5206:
+		return byte(uint16(m.Multiplicand) * uint16(m.Multiplier) >> 8)
+	case chips&NSFChipMMC5 != 0 && 0x5C00 <= addr && addr < 0x5FF6:
+		return m.ExRAM[addr-0x5C00]
+	case 0x4020 <= addr && addr < 0x6000:
+		// open bus
+		return 0
+	case addr < 0x6000:
+		slog.Error("Invalid mapper NSF read", "addr", log.HexAddr(addr))
+		return 0
+	case addr >= 0xFFFA:
+		return m.readVector(addr)
+	case m.fds() && addr < 0xE000, addr < 0x8000:
+		return m.cartridge.ReadSRAM(addr - 0x6000)
+	default:
+		data := m.readPRG(addr)
+		if chips&NSFChipMMC5 != 0 && addr < 0xC000 {
+			m.MMC5.readPRG(data)
+		}
+		return data
+	}
+}
+
+// ReadMemSafe reads memory without acknowledging the play timer or MMC5 PCM IRQ, or sending PRG reads to the PCM channel.
+func (m *MapperNSF) ReadMemSafe(addr uint16) byte {
+	chips := m.Chips()
+	switch {
+	case addr == nsfRegPlay:
+		if m.PlayReady {
+			return 0x80
+		}
+		return 0
+	case chips&NSFChipMMC5 != 0 && addr == 0x5010:
+		return m.MMC5.Peek(addr)
+	case chips&NSFChipMMC5 != 0 && !m.fds() && 0x8000 <= addr && addr < 0xC000:
+		return m.readPRG(addr)
+	default:
+		return m.ReadMem(addr)
+	}
+}
+
+func (m *MapperNSF) WriteMem(addr uint16, data byte) {
+	chips := m.Chips()
+	switch {
+	case addr < 0x2000:
+		m.cartridge.CHR[addr] = data
+	case chips&NSFChipFDS != 0 && 0x4040 <= addr && addr <= 0x408A:
+		m.FDS.Write(addr, data)
+	case chips&NSFChipMMC5 != 0 && 0x5000 <= addr && addr <= 0x5015:
+		m.MMC5.Write(addr, data)
+	case chips&NSFChipMMC5 != 0 && addr == 0x5205:
+		m.Multiplicand = data
+	case chips&NSFChipMMC5 != 0 && addr == 0x5206:
+		m.Multiplier = data
+	case chips&NSFChipMMC5 != 0 && 0x5C00 <= addr && addr < 0x5FF6:
+		m.ExRAM[addr-0x5C00] = data
+	case 0x5FF6 <= addr && addr <= 0x5FFF:
+		if m.nsf.Bankswitched {
+			m.writeBank(int(addr)-0x5FF8, data)
+		}
+	case 0x4020 <= addr && addr < 0x6000:
+		// Unmapped
+	case addr < 0x6000:
+		slog.Error("Invalid mapper NSF write", "addr", log.HexAddr(addr))
+	case m.fds() && addr < 0xE000, addr < 0x8000:
+		m.cartridge.WriteSRAM(addr-0x6000, data)
+	case chips&NSFChipVRC6 != 0 && 0x9000 <= addr && addr < 0xC000:
+		m.writeVRC6(addr, data)
+	case chips&NSFChipSunsoft5B != 0 && 0xC000 <= addr && addr < 0xE000:
+		m.Sunsoft5B.Select(data)
+	case chips&NSFChipSunsoft5B != 0 && 0xE000 <= addr:
+		m.Sunsoft5B.Write(data)
+	default:
+		// ROM
+	}
+}
+
+// writeBank maps a PRG bank to a 4 KiB slot. Slots -2 and -1 are $6000 and $7000.
+// When the FDS chip is present, slots below $E000 are RAM, so the bank is copied instead.
+func (m *MapperNSF) writeBank(slot int, bank byte) {
+	if m.fds() && slot < 6 {
+		offset := (slot + 2) * nsfBankSize
+		copy(m.cartridge.SRAM[offset:offset+nsfBankSize], m.prgBank(bank))
+		return
+	}
+	if slot >= 0 {
+		m.Banks[slot] = bank
+	}
+}
+
+func (m *MapperNSF) prgBank(bank byte) []byte {
+	count := len(m.cartridge.PRG) / nsfBankSize
+	offset := int(bank) % count * nsfBankSize
+	return m.cartridge.PRG[offset : offset+nsfBankSize]
+}
+
+func (m *MapperNSF) readPRG(addr uint16) byte {
+	slot := (addr - 0x8000) / nsfBankSize
+	return m.prgBank(m.Banks[slot])[addr%nsfBankSize]
+}
+
+func (m *MapperNSF) readDriver(addr uint16) byte {
+	switch addr {
+	case 0x4120:
+		return byte(m.nsf.InitAddr)
+	case 0x4121:
+		return byte(m.nsf.InitAddr >> 8)
+	case 0x4128:
+		return byte(m.nsf.PlayAddr)
+	case 0x4129:
+		return byte(m.nsf.PlayAddr >> 8)
+	default:
+		return nsfDriver[addr-nsfDriverAddr]
+	}
+}
+
+// readVector points reset at the driver, and NMI and IRQ at an RTI.
+func (m *MapperNSF) readVector(addr uint16) byte {
+	switch addr {
+	case 0xFFFC:
+		return byte(nsfDriverAddr & 0xFF)
+	case 0xFFFD:
+		return nsfDriverAddr >> 8
+	case 0xFFFA, 0xFFFE:
+		return byte(nsfRTIAddr & 0xFF)
+	default:
+		return nsfRTIAddr >> 8
+	}
+}
+
+func (m *MapperNSF) writeVRC6(addr uint16, data byte) {
+	reg := addr & 0xF003
+	switch {
+	case reg == 0x9003:
+		m.VRC6.WriteControl(data)
+	case reg&0xF000 == 0x9000:
+		m.VRC6.Pulse[0].Write(reg&3, data)
+	case reg&0xF000 == 0xA000:
+		m.VRC6.Pulse[1].Write(reg&3, data)
+	case reg&0xF000 == 0xB000 && reg != 0xB003:
+		m.VRC6.Sawtooth.Write(reg&3, data)
+	}
+}
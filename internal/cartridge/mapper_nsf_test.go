@@ -0,0 +1,106 @@
+package cartridge
+
+import (
+	"bytes"
+	"testing"
+
+	"gabe565.com/gones/internal/consts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMapperNSF(t *testing.T, chips NSFChip, banks [8]byte) *MapperNSF {
+	cart, err := FromNSF(bytes.NewReader(newNSFFile(chips, banks)))
+	require.NoError(t, err)
+	mapper, err := NewMapper(cart)
+	require.NoError(t, err)
+	require.IsType(t, &MapperNSF{}, mapper)
+	m := mapper.(*MapperNSF)
+	m.Start(m.NSF().StartSong, false, consts.CPUFrequency)
+	return m
+}
+
+func TestMapperNSF_Driver(t *testing.T) {
+	t.Parallel()
+
+	m := newMapperNSF(t, 0, [8]byte{})
+	assert.EqualValues(t, 0x00, m.ReadMem(0xFFFC))
+	assert.EqualValues(t, 0x41, m.ReadMem(0xFFFD))
+	for _, vector := range []uint16{0xFFFA, 0xFFFE} {
+		addr := uint16(m.ReadMem(vector)) | uint16(m.ReadMem(vector+1))<<8
+		assert.EqualValues(t, 0x40, m.ReadMem(addr), "NMI and IRQ point at RTI")
+	}
+	assert.EqualValues(t, 0x78, m.ReadMem(0x4100))
+
+	// JSR operands are patched with the init and play addresses
+	assert.EqualValues(t, 0x03, m.ReadMem(0x4120))
+	assert.EqualValues(t, 0x80, m.ReadMem(0x4121))
+	assert.EqualValues(t, 0x06, m.ReadMem(0x4128))
+	assert.EqualValues(t, 0x80, m.ReadMem(0x4129))
+
+	assert.EqualValues(t, 1, m.ReadMem(nsfRegSong))
+	assert.EqualValues(t, 0, m.ReadMem(nsfRegRegion))
+
+	m.Start(2, true, 1_000_000)
+	assert.EqualValues(t, 2, m.ReadMem(nsfRegSong))
+	assert.EqualValues(t, 1, m.ReadMem(nsfRegRegion))
+
+	m.OnCPUStep(nsfSpeedPAL - 1)
+	assert.Zero(t, m.ReadMem(nsfRegPlay))
+	m.OnCPUStep(1)
+	assert.EqualValues(t, 0x80, m.ReadMemSafe(nsfRegPlay))
+	assert.EqualValues(t, 0x80, m.ReadMem(nsfRegPlay), "safe reads have no side effects")
+	assert.Zero(t, m.ReadMem(nsfRegPlay), "reading clears the play flag")
+}
+
+func TestMapperNSF_Banks(t *testing.T) {
+	t.Parallel()
+
+	m := newMapperNSF(t, 0, [8]byte{})
+	assert.EqualValues(t, 0xAA, m.ReadMem(0x8000))
+	assert.EqualValues(t, 0xBB, m.ReadMem(0x9000))
+	m.WriteMem(0x5FF8, 1)
+	assert.EqualValues(t, 0xAA, m.ReadMem(0x8000), "banks are fixed unless the file is bankswitched")
+
+	m = newMapperNSF(t, 0, [8]byte{1, 0})
+	assert.EqualValues(t, 0xBB, m.ReadMem(0x8000))
+	assert.EqualValues(t, 0xAA, m.ReadMem(0x9000))
+	m.WriteMem(0x5FF8, 0)
+	assert.EqualValues(t, 0xAA, m.ReadMem(0x8000))
+
+	m.WriteMem(0x6000, 0x12)
+	assert.EqualValues(t, 0x12, m.ReadMem(0x6000))
+	m.WriteMem(0x8000, 0x12)
+	assert.EqualValues(t, 0xAA, m.ReadMem(0x8000), "ROM is read-only")
+
+	m = newMapperNSF(t, NSFChipFDS, [8]byte{1, 0})
+	assert.EqualValues(t, 0xBB, m.ReadMem(0x8000))
+	m.WriteMem(0x8000, 0x12)
+	assert.EqualValues(t, 0x12, m.ReadMem(0x8000), "FDS NSFs run from RAM")
+	m.WriteMem(0x5FF6, 1)
+	assert.EqualValues(t, 0xBB, m.ReadMem(0x6000))
+}
+
+func TestMapperNSF_Audio(t *testing.T) {
+	t.Parallel()
+
+	m := newMapperNSF(t, NSFChipVRC6|NSFChipMMC5, [8]byte{})
+	assert.Equal(t, ChannelMMC5Pulse1|ChannelMMC5Pulse2|ChannelMMC5PCM|
+		ChannelVRC6Pulse1|ChannelVRC6Pulse2|ChannelVRC6Sawtooth, m.Channels())
+
+	// VRC6 pulse 1 in digitized mode at full volume
+	m.WriteMem(0x9000, 0x8F)
+	m.WriteMem(0x9002, 0x80)
+	m.StepAudio()
+	assert.Positive(t, audio(m, ChannelVRC6Pulse1))
+	assert.Zero(t, audio(m, ChannelMMC5PCM))
+
+	// MMC5 PCM
+	m.WriteMem(0x5011, 0x80)
+	assert.Positive(t, audio(m, ChannelMMC5PCM))
+
+	// Sunsoft 5B registers are ROM without the chip
+	m.WriteMem(0xC000, 0x08)
+	m.WriteMem(0xE000, 0x0F)
+	assert.Zero(t, m.Sunsoft5B.Channels[0].Volume)
+}
@@ -0,0 +1,353 @@
+package cartridge
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"gabe565.com/gones/internal/consts"
+)
+
+const (
+	nsfHeaderSize = 0x80
+	// nsfBankSize is the size of each bank in $8000-$FFFF.
+	nsfBankSize = 0x1000
+
+	// nsfSpeedNTSC is the default NTSC play routine period in microseconds.
+	nsfSpeedNTSC = 16639
+	// nsfSpeedPAL is the default PAL play routine period in microseconds.
+	nsfSpeedPAL = 19997
+)
+
+// NSFChip is a set of expansion audio chips requested by an NSF.
+type NSFChip uint8
+
+const (
+	NSFChipVRC6 NSFChip = 1 << iota
+	NSFChipVRC7
+	NSFChipFDS
+	NSFChipMMC5
+	NSFChipN163
+	NSFChipSunsoft5B
+
+	// NSFChipSupported is the set of chips that can be played.
+	NSFChipSupported = NSFChipVRC6 | NSFChipFDS | NSFChipMMC5 | NSFChipSunsoft5B
+)
+
+//nolint:gochecknoglobals
+var (
+	nsfMagic  = []byte{'N', 'E', 'S', 'M', 0x1A}
+	nsfeMagic = []byte{'N', 'S', 'F', 'E'}
+)
+
+// NSF is an NES Sound Format file. Both NSF and NSFe files are supported.
+type NSF struct {
+	Title     string
+	Artist    string
+	Copyright string
+
+	Songs int
+	// StartSong is the 0-based song to play first
+	StartSong int
+
+	LoadAddr uint16
+	InitAddr uint16
+	PlayAddr uint16
+	Data     []byte
+
+	Banks        [8]byte
+	Bankswitched bool
+
+	// SpeedNTSC and SpeedPAL are the play routine periods in microseconds
+	SpeedNTSC uint16
+	SpeedPAL  uint16
+	PAL       bool
+
+	Chips NSFChip
+
+	// Lengths, Fades, and Labels are optional per-song metadata from NSFe or NSF2 files
+	Lengths []time.Duration
+	Fades   []time.Duration
+	Labels  []string
+}
+
+// IsNSF reports whether b looks like an NSF or NSFe file.
+func IsNSF(b []byte) bool {
+	return bytes.HasPrefix(b, nsfMagic) || bytes.HasPrefix(b, nsfeMagic)
+}
+
+// Length returns the length of a song, or 0 if it is unknown.
+func (n *NSF) Length(song int) time.Duration {
+	if song < len(n.Lengths) {
+		return n.Lengths[song]
+	}
+	return 0
+}
+
+// Fade returns the fade out time of a song, or -1 if it is unknown.
+func (n *NSF) Fade(song int) time.Duration {
+	if song < len(n.Fades) {
+		return n.Fades[song]
+	}
+	return -1
+}
+
+// Label returns the title of a song, or an empty string if it is unknown.
+func (n *NSF) Label(song int) string {
+	if song < len(n.Labels) {
+		return n.Labels[song]
+	}
+	return ""
+}
+
+// FromNSF loads an NSF or NSFe file.
+// The program is run by a small driver provided by [MapperNSF].
+func FromNSF(r io.Reader) (*Cartridge, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var nsf *NSF
+	switch {
+	case bytes.HasPrefix(b, nsfMagic):
+		nsf, err = parseNSF(b)
+	case bytes.HasPrefix(b, nsfeMagic):
+		nsf, err = parseNSFe(b[len(nsfeMagic):])
+	default:
+		err = fmt.Errorf("%w: %s", ErrInvalidROM, "missing NSF header")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if nsf.Songs == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidROM, "NSF has no songs")
+	}
+	if nsf.Chips&NSFChipFDS != 0 {
+		if nsf.LoadAddr < 0x6000 {
+			return nil, fmt.Errorf("%w: FDS NSF load address $%04X is below $6000", ErrInvalidROM, nsf.LoadAddr)
+		}
+	} else if nsf.LoadAddr < 0x8000 {
+		return nil, fmt.Errorf("%w: NSF load address $%04X is below $8000", ErrInvalidROM, nsf.LoadAddr)
+	}
+	if unsupported := nsf.Chips &^ NSFChipSupported; unsupported != 0 {
+		slog.Warn("NSF requests unsupported expansion audio", "chips", fmt.Sprintf("%#02x", byte(unsupported)))
+	}
+
+	slog.Debug("Loaded NSF",
+		"songs", nsf.Songs,
+		"load", fmt.Sprintf("$%04X", nsf.LoadAddr),
+		"init", fmt.Sprintf("$%04X", nsf.InitAddr),
+		"play", fmt.Sprintf("$%04X", nsf.PlayAddr),
+		"bankswitched", nsf.Bankswitched,
+		"chips", fmt.Sprintf("%#02x", byte(nsf.Chips)),
+	)
+
+	cartridge := New()
+	cartridge.Header.SetNESv2(true)
+	if nsf.PAL {
+		cartridge.Header.SetTiming(TimingPAL)
+	}
+	cartridge.PRG = nsf.prg()
+	cartridge.CHR = make([]byte, consts.CHRChunkSize)
+	if nsf.Chips&NSFChipFDS != 0 {
+		cartridge.SRAM = make([]byte, 0x8000)
+	}
+	cartridge.NSF = nsf
+
+	hash := md5.Sum(b)
+	cartridge.hash = hex.EncodeToString(hash[:])
+	cartridge.name = nsf.Title
+	return cartridge, nil
+}
+
+// prg returns the program data aligned to 4 KiB banks.
+// Bank 0 starts at $8000 for plain NSFs, $6000 for plain FDS NSFs, or the start of the load address's bank if bankswitched.
+func (n *NSF) prg() []byte {
+	var padding int
+	switch {
+	case n.Bankswitched:
+		padding = int(n.LoadAddr & (nsfBankSize - 1))
+	case n.Chips&NSFChipFDS != 0:
+		padding = int(n.LoadAddr) - 0x6000
+	default:
+		padding = int(n.LoadAddr) - 0x8000
+	}
+
+	size := padding + len(n.Data)
+	size += (nsfBankSize - size%nsfBankSize) % nsfBankSize
+	prg := make([]byte, size)
+	copy(prg[padding:], n.Data)
+	return prg
+}
+
+func parseNSF(b []byte) (*NSF, error) {
+	if len(b) < nsfHeaderSize {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidROM, "truncated NSF header")
+	}
+
+	header := b[:nsfHeaderSize]
+	nsf := &NSF{
+		Songs:     int(header[0x06]),
+		StartSong: max(int(header[0x07])-1, 0),
+		LoadAddr:  binary.LittleEndian.Uint16(header[0x08:]),
+		InitAddr:  binary.LittleEndian.Uint16(header[0x0A:]),
+		PlayAddr:  binary.LittleEndian.Uint16(header[0x0C:]),
+		Title:     nsfString(header[0x0E:0x2E]),
+		Artist:    nsfString(header[0x2E:0x4E]),
+		Copyright: nsfString(header[0x4E:0x6E]),
+		SpeedNTSC: binary.LittleEndian.Uint16(header[0x6E:]),
+		SpeedPAL:  binary.LittleEndian.Uint16(header[0x78:]),
+		PAL:       header[0x7A]&3 == 1,
+		Chips:     NSFChip(header[0x7B]),
+	}
+	copy(nsf.Banks[:], header[0x70:0x78])
+	nsf.Bankswitched = nsf.Banks != [8]byte{}
+
+	nsf.Data = b[nsfHeaderSize:]
+	// NSF2 files may be followed by NSFe metadata chunks
+	if size := int(header[0x7D]) | int(header[0x7E])<<8 | int(header[0x7F])<<16; header[0x05] >= 2 && size != 0 {
+		if size > len(nsf.Data) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidROM, "truncated NSF program")
+		}
+		metadata := nsf.Data[size:]
+		nsf.Data = nsf.Data[:size]
+		if err := nsf.parseChunks(metadata, true); err != nil {
+			return nil, err
+		}
+	}
+
+	nsf.setDefaultSpeeds()
+	return nsf, nil
+}
+
+func parseNSFe(b []byte) (*NSF, error) {
+	nsf := &NSF{}
+	if err := nsf.parseChunks(b, false); err != nil {
+		return nil, err
+	}
+	if nsf.Data == nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidROM, "NSFe is missing a DATA chunk")
+	}
+	nsf.setDefaultSpeeds()
+	return nsf, nil
+}
+
+// parseChunks reads NSFe chunks. If metadata is true, the chunks follow an NSF2 header,
+// so INFO and DATA are not allowed.
+func (n *NSF) parseChunks(b []byte, metadata bool) error {
+	var hasInfo bool
+	for len(b) >= 8 {
+		size := int(binary.LittleEndian.Uint32(b))
+		id := string(b[4:8])
+		b = b[8:]
+		if size > len(b) {
+			return fmt.Errorf("%w: truncated NSFe chunk %q", ErrInvalidROM, id)
+		}
+		data := b[:size]
+		b = b[size:]
+
+		switch id {
+		case "INFO":
+			if metadata || len(data) < 9 {
+				return fmt.Errorf("%w: %s", ErrInvalidROM, "invalid NSFe INFO chunk")
+			}
+			hasInfo = true
+			n.LoadAddr = binary.LittleEndian.Uint16(data[0:])
+			n.InitAddr = binary.LittleEndian.Uint16(data[2:])
+			n.PlayAddr = binary.LittleEndian.Uint16(data[4:])
+			n.PAL = data[6]&3 == 1
+			n.Chips = NSFChip(data[7])
+			n.Songs = int(data[8])
+			if len(data) > 9 {
+				n.StartSong = int(data[9])
+			}
+		case "DATA":
+			if metadata {
+				return fmt.Errorf("%w: %s", ErrInvalidROM, "unexpected NSFe DATA chunk")
+			}
+			n.Data = data
+		case "BANK":
+			copy(n.Banks[:], data)
+			n.Bankswitched = n.Banks != [8]byte{}
+		case "RATE":
+			if len(data) >= 2 {
+				n.SpeedNTSC = binary.LittleEndian.Uint16(data[0:])
+			}
+			if len(data) >= 4 {
+				n.SpeedPAL = binary.LittleEndian.Uint16(data[2:])
+			}
+		case "auth":
+			fields := nsfStrings(data)
+			for i, field := range []*string{&n.Title, &n.Artist, &n.Copyright} {
+				if i < len(fields) {
+					*field = fields[i]
+				}
+			}
+		case "time", "fade":
+			durations := make([]time.Duration, 0, len(data)/4)
+			for i := 0; i+4 <= len(data); i += 4 {
+				ms := int32(binary.LittleEndian.Uint32(data[i:])) //nolint:gosec
+				durations = append(durations, time.Duration(ms)*time.Millisecond)
+			}
+			if id == "time" {
+				n.Lengths = durations
+			} else {
+				n.Fades = durations
+			}
+		case "tlbl":
+			n.Labels = nsfStrings(data)
+		case "NEND":
+			b = nil
+		default:
+			// Chunks starting with an uppercase letter are required to play the file
+			if id[0] >= 'A' && id[0] <= 'Z' {
+				return fmt.Errorf("%w: unsupported NSFe chunk %q", ErrInvalidROM, id)
+			}
+		}
+	}
+
+	if !metadata && !hasInfo {
+		return fmt.Errorf("%w: %s", ErrInvalidROM, "NSFe is missing an INFO chunk")
+	}
+	return nil
+}
+
+func (n *NSF) setDefaultSpeeds() {
+	if n.SpeedNTSC == 0 {
+		n.SpeedNTSC = nsfSpeedNTSC
+	}
+	if n.SpeedPAL == 0 {
+		n.SpeedPAL = nsfSpeedPAL
+	}
+}
+
+// nsfString decodes a null-terminated header string.
+func nsfString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i != -1 {
+		b = b[:i]
+	}
+	s := strings.TrimSpace(string(b))
+	if s == "<?>" {
+		return ""
+	}
+	return s
+}
+
+// nsfStrings decodes a list of null-terminated strings.
+func nsfStrings(b []byte) []string {
+	b = bytes.TrimSuffix(b, []byte{0})
+	parts := bytes.Split(b, []byte{0})
+	s := make([]string, 0, len(parts))
+	for _, part := range parts {
+		s = append(s, nsfString(part))
+	}
+	return s
+}
@@ -0,0 +1,152 @@
+package cartridge
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newNSFFile creates an NSF with 3 songs loaded at $8000 and 4 KiB of data.
+func newNSFFile(chips NSFChip, banks [8]byte) []byte {
+	header := make([]byte, nsfHeaderSize)
+	copy(header, nsfMagic)
+	header[0x05] = 1
+	header[0x06] = 3
+	header[0x07] = 2
+	binary.LittleEndian.PutUint16(header[0x08:], 0x8000)
+	binary.LittleEndian.PutUint16(header[0x0A:], 0x8003)
+	binary.LittleEndian.PutUint16(header[0x0C:], 0x8006)
+	copy(header[0x0E:], "Title")
+	copy(header[0x2E:], "<?>")
+	copy(header[0x70:], banks[:])
+	header[0x7B] = byte(chips)
+
+	data := make([]byte, 2*nsfBankSize)
+	data[0] = 0xAA
+	data[nsfBankSize] = 0xBB
+	return append(header, data...)
+}
+
+// nsfeChunk encodes an NSFe chunk.
+func nsfeChunk(id string, data []byte) []byte {
+	b := binary.LittleEndian.AppendUint32(nil, uint32(len(data))) //nolint:gosec
+	b = append(b, id...)
+	return append(b, data...)
+}
+
+func TestFromNSF(t *testing.T) {
+	t.Parallel()
+
+	file := newNSFFile(NSFChipVRC6|NSFChipN163, [8]byte{})
+	require.True(t, IsNSF(file))
+
+	cart, err := FromNSF(bytes.NewReader(file))
+	require.NoError(t, err)
+	require.NotNil(t, cart.NSF)
+	assert.Equal(t, "Title", cart.Name())
+	assert.Len(t, cart.PRG, 2*nsfBankSize)
+	assert.Equal(t, TimingNTSC, cart.Header.Timing())
+
+	nsf := cart.NSF
+	assert.Equal(t, 3, nsf.Songs)
+	assert.Equal(t, 1, nsf.StartSong)
+	assert.EqualValues(t, 0x8003, nsf.InitAddr)
+	assert.EqualValues(t, 0x8006, nsf.PlayAddr)
+	assert.Empty(t, nsf.Artist)
+	assert.False(t, nsf.Bankswitched)
+	assert.EqualValues(t, nsfSpeedNTSC, nsf.SpeedNTSC)
+	assert.EqualValues(t, nsfSpeedPAL, nsf.SpeedPAL)
+	assert.Equal(t, NSFChipVRC6|NSFChipN163, nsf.Chips)
+
+	_, err = FromNSF(bytes.NewReader(file[:0x40]))
+	require.ErrorIs(t, err, ErrInvalidROM)
+}
+
+func TestFromNSF_NSFe(t *testing.T) {
+	t.Parallel()
+
+	info := []byte{0x00, 0x80, 0x03, 0x80, 0x06, 0x80, 1, byte(NSFChipFDS), 2, 1}
+	lengths := binary.LittleEndian.AppendUint32(nil, 90000)
+	lengths = binary.LittleEndian.AppendUint32(lengths, 1500)
+
+	file := bytes.Clone(nsfeMagic)
+	file = append(file, nsfeChunk("INFO", info)...)
+	file = append(file, nsfeChunk("DATA", []byte{1, 2, 3})...)
+	file = append(file, nsfeChunk("auth", []byte("Game\x00Artist\x00\x00"))...)
+	file = append(file, nsfeChunk("time", lengths)...)
+	file = append(file, nsfeChunk("tlbl", []byte("One\x00Two\x00"))...)
+	file = append(file, nsfeChunk("psfx", []byte{0})...)
+	file = append(file, nsfeChunk("NEND", nil)...)
+
+	cart, err := FromNSF(bytes.NewReader(file))
+	require.NoError(t, err)
+	nsf := cart.NSF
+	assert.Equal(t, "Game", nsf.Title)
+	assert.Equal(t, "Artist", nsf.Artist)
+	assert.True(t, nsf.PAL)
+	assert.Equal(t, TimingPAL, cart.Header.Timing())
+	assert.Equal(t, 2, nsf.Songs)
+	assert.Equal(t, 1, nsf.StartSong)
+	assert.Equal(t, 90*time.Second, nsf.Length(0))
+	assert.Equal(t, 1500*time.Millisecond, nsf.Length(1))
+	assert.EqualValues(t, -1, nsf.Fade(0))
+	assert.Equal(t, "Two", nsf.Label(1))
+	assert.Len(t, cart.SRAM, 0x8000, "FDS NSFs get RAM from $6000-$DFFF")
+
+	unsupported := bytes.Clone(file)
+	unsupported = append(unsupported[:len(unsupported)-8], nsfeChunk("VRC7", nil)...)
+	_, err = FromNSF(bytes.NewReader(unsupported))
+	require.ErrorIs(t, err, ErrInvalidROM)
+}
+
+func TestFromNSF_NSF2(t *testing.T) {
+	t.Parallel()
+
+	file := newNSFFile(0, [8]byte{})
+	file[0x05] = 2
+	file[0x7E] = 0x20 // 8 KiB of program data
+	file = append(file, nsfeChunk("fade", binary.LittleEndian.AppendUint32(nil, 2000))...)
+
+	cart, err := FromNSF(bytes.NewReader(file))
+	require.NoError(t, err)
+	assert.Len(t, cart.NSF.Data, 2*nsfBankSize)
+	assert.Equal(t, 2*time.Second, cart.NSF.Fade(0))
+	assert.Zero(t, cart.NSF.Length(0))
+}
+
+func TestFromNSF_LoadAddr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		chips   NSFChip
+		addr    uint16
+		padding int
+		wantErr bool
+	}{
+		{"plain", 0, 0x8000, 0, false},
+		{"plain below $8000", 0, 0x7000, 0, true},
+		{"fds", NSFChipFDS, 0x6000, 0, false},
+		{"fds at $8000", NSFChipFDS, 0x8000, 0x2000, false},
+		{"fds below $6000", NSFChipFDS, 0x5000, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			file := newNSFFile(tt.chips, [8]byte{})
+			binary.LittleEndian.PutUint16(file[0x08:], tt.addr)
+
+			cart, err := FromNSF(bytes.NewReader(file))
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidROM)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, byte(0xAA), cart.PRG[tt.padding])
+		})
+	}
+}
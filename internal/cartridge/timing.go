@@ -0,0 +1,13 @@
+package cartridge
+
+//go:generate go tool stringer -type Timing -trimprefix Timing -linecomment
+
+// Timing is the CPU/PPU timing mode declared by a NES 2.0 header.
+type Timing byte
+
+const (
+	TimingNTSC  Timing = iota // NTSC
+	TimingPAL                 // PAL
+	TimingMulti               // Multiple-region
+	TimingDendy               // Dendy
+)
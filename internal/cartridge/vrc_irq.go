@@ -0,0 +1,59 @@
+package cartridge
+
+// vrcPrescaler is the number of prescaler steps per scanline. The prescaler is decremented by 3 every CPU cycle.
+const vrcPrescaler = 341
+
+// VRCIRQ is the IRQ counter used by Konami VRC mappers.
+// In cycle mode it counts CPU cycles. In scanline mode a prescaler scales CPU cycles to scanlines,
+// which avoids watching the PPU.
+type VRCIRQ struct {
+	Latch          byte
+	Counter        byte
+	Prescaler      int
+	Enabled        bool
+	EnableAfterAck bool
+	CycleMode      bool
+	Pending        bool
+}
+
+func (v *VRCIRQ) WriteControl(data byte) {
+	v.EnableAfterAck = data&1 != 0
+	v.Enabled = data&2 != 0
+	v.CycleMode = data&4 != 0
+	v.Pending = false
+	if v.Enabled {
+		v.Counter = v.Latch
+		v.Prescaler = vrcPrescaler
+	}
+}
+
+func (v *VRCIRQ) Ack() {
+	v.Pending = false
+	v.Enabled = v.EnableAfterAck
+}
+
+func (v *VRCIRQ) Step(cycles uint) {
+	if !v.Enabled {
+		return
+	}
+	for range cycles {
+		if v.CycleMode {
+			v.clock()
+			continue
+		}
+		v.Prescaler -= 3
+		if v.Prescaler <= 0 {
+			v.Prescaler += vrcPrescaler
+			v.clock()
+		}
+	}
+}
+
+func (v *VRCIRQ) clock() {
+	if v.Counter == 0xFF {
+		v.Counter = v.Latch
+		v.Pending = true
+	} else {
+		v.Counter++
+	}
+}
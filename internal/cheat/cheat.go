@@ -0,0 +1,124 @@
+// Package cheat applies Game Genie and raw RAM cheats to CPU reads.
+package cheat
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gabe565.com/gones/internal/genie"
+)
+
+// Cheat is a single entry in a cheat file.
+//
+// Code is either a Game Genie code, or a raw code in the form "AAAA:VV" or "AAAA?CC:VV".
+// Raw codes below $8000 freeze RAM to the value, and raw codes at $8000 or above patch ROM like a Game Genie code.
+type Cheat struct {
+	Name    string `toml:"name,omitempty"`
+	Code    string `toml:"code"`
+	Enabled bool   `toml:"enabled"`
+}
+
+// Patch replaces a CPU read at an address.
+type Patch struct {
+	Address uint16
+	Value   byte
+	// Compare is the value that must be read for the patch to apply, or -1 to always apply.
+	Compare int
+}
+
+var ErrInvalidCode = errors.New("invalid cheat code")
+
+// Parse decodes a Game Genie or raw cheat code.
+func Parse(code string) (Patch, error) {
+	code = strings.TrimSpace(code)
+	if !strings.Contains(code, ":") {
+		decoded, err := genie.Decode(code)
+		if err != nil {
+			return Patch{}, err
+		}
+		return Patch{
+			Address: uint16(decoded.Address),
+			Value:   byte(decoded.Replace),
+			Compare: decoded.Compare,
+		}, nil
+	}
+
+	addrStr, valueStr, _ := strings.Cut(code, ":")
+	addrStr, compareStr, hasCompare := strings.Cut(addrStr, "?")
+
+	addr, err := strconv.ParseUint(strings.TrimPrefix(addrStr, "$"), 16, 16)
+	if err != nil {
+		return Patch{}, fmt.Errorf("%w %q: address: %w", ErrInvalidCode, code, err)
+	}
+
+	value, err := strconv.ParseUint(valueStr, 16, 8)
+	if err != nil {
+		return Patch{}, fmt.Errorf("%w %q: value: %w", ErrInvalidCode, code, err)
+	}
+
+	patch := Patch{Address: uint16(addr), Value: byte(value), Compare: -1}
+	if hasCompare {
+		compare, err := strconv.ParseUint(compareStr, 16, 8)
+		if err != nil {
+			return Patch{}, fmt.Errorf("%w %q: compare: %w", ErrInvalidCode, code, err)
+		}
+		patch.Compare = int(compare)
+	}
+
+	if patch.Address < 0x2000 {
+		// Internal RAM is mirrored every 2 KiB
+		patch.Address &= 0x7FF
+	}
+	return patch, nil
+}
+
+// Engine applies enabled cheats to CPU reads.
+type Engine struct {
+	// patches holds every patch for an address in file order.
+	// Game Genie codes often share an address, with a different compare value for each PRG bank.
+	patches map[uint16][]Patch
+	count   int
+}
+
+// New parses the enabled cheats. Cheats that fail to parse are returned as a joined error,
+// and the remaining cheats are still applied.
+func New(cheats []Cheat) (*Engine, error) {
+	e := &Engine{patches: make(map[uint16][]Patch, len(cheats))}
+	var errs []error
+	for _, cheat := range cheats {
+		if !cheat.Enabled {
+			continue
+		}
+
+		patch, err := Parse(cheat.Code)
+		if err != nil {
+			if cheat.Name != "" {
+				err = fmt.Errorf("%s: %w", cheat.Name, err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		e.patches[patch.Address] = append(e.patches[patch.Address], patch)
+		e.count++
+	}
+	return e, errors.Join(errs...)
+}
+
+// Len returns the number of active patches.
+func (e *Engine) Len() int {
+	return e.count
+}
+
+// Read returns the value the CPU should see when data is read from addr.
+// The first patch at addr whose compare value matches is applied.
+// RAM mirrors must be folded into $0000-$07FF before calling Read.
+func (e *Engine) Read(addr uint16, data byte) byte {
+	for _, patch := range e.patches[addr] {
+		if patch.Compare == -1 || byte(patch.Compare) == data {
+			return patch.Value
+		}
+	}
+	return data
+}
@@ -0,0 +1,94 @@
+package cheat
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		code    string
+		want    Patch
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"genie 6", "SXIOPO", Patch{Address: 0x91D9, Value: 0xAD, Compare: -1}, require.NoError},
+		{"genie 8", "YEUZUGAA", Patch{Address: 0xACB3, Value: 0x07, Compare: 0x00}, require.NoError},
+		{"freeze", "075A:09", Patch{Address: 0x075A, Value: 0x09, Compare: -1}, require.NoError},
+		{"freeze mirror", "$175A:09", Patch{Address: 0x075A, Value: 0x09, Compare: -1}, require.NoError},
+		{"sram", "6010:FF", Patch{Address: 0x6010, Value: 0xFF, Compare: -1}, require.NoError},
+		{"rom compare", "ACB3?00:07", Patch{Address: 0xACB3, Value: 0x07, Compare: 0x00}, require.NoError},
+		{"invalid genie", "SXIOP", Patch{}, require.Error},
+		{"invalid address", "XYZ:01", Patch{}, require.Error},
+		{"invalid value", "0000:100", Patch{}, require.Error},
+		{"invalid compare", "8000?G:01", Patch{}, require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Parse(tt.code)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEngine_Read(t *testing.T) {
+	t.Parallel()
+	e, err := New([]Cheat{
+		{Code: "SXIOPO", Enabled: true},
+		{Code: "YEUZUGAA", Enabled: true},
+		{Code: "075A:09", Enabled: true},
+		{Code: "0100:01", Enabled: false},
+		{Name: "Broken", Code: "?", Enabled: true},
+	})
+	require.Error(t, err)
+	assert.Equal(t, 3, e.Len())
+
+	assert.Equal(t, byte(0xAD), e.Read(0x91D9, 0x12))
+	assert.Equal(t, byte(0x07), e.Read(0xACB3, 0x00))
+	assert.Equal(t, byte(0x01), e.Read(0xACB3, 0x01), "compare mismatch")
+	assert.Equal(t, byte(0x09), e.Read(0x075A, 0x02))
+	assert.Equal(t, byte(0x02), e.Read(0x0100, 0x02), "disabled")
+}
+
+func TestEngine_Read_SharedAddress(t *testing.T) {
+	t.Parallel()
+	e, err := New([]Cheat{
+		{Code: "ACB3?00:07", Enabled: true},
+		{Code: "ACB3?01:08", Enabled: true},
+		{Code: "ACB3?01:09", Enabled: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, e.Len())
+
+	assert.Equal(t, byte(0x07), e.Read(0xACB3, 0x00))
+	assert.Equal(t, byte(0x08), e.Read(0xACB3, 0x01), "first matching patch wins")
+	assert.Equal(t, byte(0x02), e.Read(0xACB3, 0x02), "compare mismatch")
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "cheats.toml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+[[cheat]]
+name = "Infinite lives"
+code = "SXIOPO"
+enabled = true
+
+[[cheat]]
+code = "075A:09"
+`), 0o600))
+
+	cheats, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Cheat{
+		{Name: "Infinite lives", Code: "SXIOPO", Enabled: true},
+		{Code: "075A:09"},
+	}, cheats)
+}
@@ -0,0 +1,26 @@
+package cheat
+
+import (
+	"os"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// File is the format of a per-game cheat file.
+type File struct {
+	Cheats []Cheat `toml:"cheat"`
+}
+
+// Load reads a cheat file.
+func Load(path string) ([]Cheat, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f File
+	if err := toml.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+	return f.Cheats, nil
+}
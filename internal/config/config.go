@@ -7,15 +7,19 @@ import (
 	"runtime"
 	"time"
 
+	"gabe565.com/gones/internal/cartridge"
 	"gabe565.com/gones/internal/consts"
+	"gabe565.com/gones/internal/controller/device"
+	"gabe565.com/gones/internal/region"
 )
 
 type Config struct {
-	UI    UI    `toml:"ui"`
-	State State `toml:"state"`
-	Input Input `toml:"input"`
-	Audio Audio `toml:"audio"`
-	Debug Debug `toml:"debug,omitempty"`
+	UI        UI        `toml:"ui"`
+	State     State     `toml:"state"`
+	Input     Input     `toml:"input"`
+	Audio     Audio     `toml:"audio"`
+	Emulation Emulation `toml:"emulation"`
+	Debug     Debug     `toml:"debug,omitempty"`
 }
 
 type UI struct {
@@ -25,6 +29,21 @@ type UI struct {
 	Palette           string   `toml:"palette" comment:"Palette (.pal) file to use. An embedded palette will be used when blank."`
 	RemoveSpriteLimit bool     `toml:"remove_sprite_limit" comment:"Removes the original hardware's 8 horizontal sprite limitation. When enabled, sprites will no longer flicker."`
 	Overscan          Overscan `toml:"overscan,inline" comment:"Change the number of rows/cols of overscan."`
+	NTSC              NTSC     `toml:"ntsc" comment:"NTSC composite video filter. Decodes the PPU's video signal like a TV, including artifact colors and dot crawl."`
+	Shader            []string `toml:"shader" comment:"Kage shaders to apply to the screen, in order. Shaders are loaded from the shaders directory in the config directory, and the .kage extension is optional. Built-in shaders: bilinear, sharp-bilinear, scanlines, mask, crt."`
+	Uniforms          Uniforms `toml:"uniforms" comment:"Uniform variables for each shader, keyed by the shader's name. Numbers are passed as floats, and lists of numbers as float arrays."`
+}
+
+// Uniforms are shader uniform variables, keyed by the shader's name and then the variable's name.
+type Uniforms map[string]map[string]any
+
+type NTSC struct {
+	Enabled    bool    `toml:"enabled" comment:"Enables the NTSC filter. The palette file is ignored when enabled."`
+	Resolution int     `toml:"resolution" comment:"Horizontal output pixels per NES pixel (between 1 and 8)."`
+	Hue        float64 `toml:"hue" comment:"Hue rotation in degrees."`
+	Saturation float64 `toml:"saturation" comment:"Color saturation multiplier (between 0 and 2)."`
+	Sharpness  float64 `toml:"sharpness" comment:"Luma sharpness (between -1 and 1). Sharper images have more visible dot crawl."`
+	Artifacts  float64 `toml:"artifacts" comment:"Strength of artifact colors and dot crawl (between 0 and 1)."`
 }
 
 type Overscan struct {
@@ -42,21 +61,39 @@ type State struct {
 	Resume           bool     `toml:"resume" comment:"Automatically resumes the previous game state."`
 	AutosaveInterval Duration `toml:"autosave_interval" comment:"If resume is enabled, the game state will be saved regularly at the configured interval."`
 	UndoStateCount   int      `toml:"undo_state_count" comment:"Number of undo states to keep in memory."`
+	Rewind           Rewind   `toml:"rewind" comment:"Rewind buffer. Hold the rewind key to step the game backwards."`
+}
+
+type Rewind struct {
+	Enabled  bool     `toml:"enabled" comment:"Enables the rewind buffer."`
+	Duration Duration `toml:"duration" comment:"Maximum amount of gameplay that can be rewound."`
+	Interval uint16   `toml:"interval" comment:"Number of frames between snapshots. Lower values rewind more smoothly, but use more memory and CPU."`
 }
 
 type Input struct {
-	Reset             Key      `toml:"reset" comment:"Key to reset the game (must be held)."`
-	ResetHold         Duration `toml:"reset_hold" comment:"Time the reset button must be held."`
-	State1Save        Key      `toml:"state1_save" comment:"Key to save the game state (separate from auto resume state)."`
-	State1Load        Key      `toml:"state1_load" comment:"Key to load the last save state."`
-	StateUndoModifier Key      `toml:"state_undo_modifier" comment:"Hold this key and press the save/load state key, and the action will be undone."`
-	FastForward       Key      `toml:"fast_forward" comment:"Key to fast-forward the game (must be held)."`
-	FastForwardRate   uint8    `toml:"fast_forward_rate" comment:"Fast-forward rate multiplier."`
-	Fullscreen        Key      `toml:"fullscreen" comment:"Key to toggle fullscreen."`
-	Screenshot        Key      `toml:"screenshot" comment:"Key to take a screenshot."`
-	TurboDutyCycle    uint16   `toml:"turbo_duty_cycle" comment:"Frame duty cycle when turbo key is held (minimum: 2)."`
-	Player1           Keymap   `toml:"player1" comment:"Player 1 keymap."`
-	Player2           Keymap   `toml:"player2" comment:"Player 2 keymap."`
+	Reset             Key            `toml:"reset" comment:"Key to reset the game (must be held)."`
+	ResetHold         Duration       `toml:"reset_hold" comment:"Time the reset button must be held."`
+	State1Save        Key            `toml:"state1_save" comment:"Key to save the game state (separate from auto resume state)."`
+	State1Load        Key            `toml:"state1_load" comment:"Key to load the last save state."`
+	StateUndoModifier Key            `toml:"state_undo_modifier" comment:"Hold this key and press the save/load state key, and the action will be undone."`
+	FastForward       Key            `toml:"fast_forward" comment:"Key to fast-forward the game (must be held)."`
+	FastForwardRate   uint8          `toml:"fast_forward_rate" comment:"Fast-forward rate multiplier."`
+	Rewind            Key            `toml:"rewind" comment:"Key to rewind the game (must be held)."`
+	Fullscreen        Key            `toml:"fullscreen" comment:"Key to toggle fullscreen."`
+	Screenshot        Key            `toml:"screenshot" comment:"Key to take a screenshot."`
+	Record            Key            `toml:"record" comment:"Key to start or stop recording video and audio. Recordings are saved to the recordings directory in the config directory."`
+	DumpAudio         Key            `toml:"dump_audio" comment:"Key to start or stop dumping raw APU output to WAV files. Dumps are saved to the recordings directory in the config directory."`
+	FDSSwitchSide     Key            `toml:"fds_switch_side" comment:"Key to eject the Famicom Disk System disk and insert the next side."`
+	TurboDutyCycle    uint16         `toml:"turbo_duty_cycle" comment:"Frame duty cycle when turbo key is held (minimum: 2)."`
+	Port1             device.Type    `toml:"port1" comment:"Device plugged into controller port 1. One of: auto, controller, zapper, vaus, vaus_famicom, none. Auto uses the NES 2.0 header, then falls back to a controller."`
+	Port2             device.Type    `toml:"port2" comment:"Device plugged into controller port 2. One of: auto, controller, zapper, vaus, vaus_famicom, none."`
+	FourPlayer        device.Adapter `toml:"four_player" comment:"Four player adapter. One of: auto, none, four_score, hori. Four Score is the NES adapter, and Hori is the Famicom 4 Players Adapter. Auto uses the NES 2.0 header."`
+	ZapperTrigger     MouseButton    `toml:"zapper_trigger" comment:"Mouse button that pulls the Zapper trigger. The Zapper is aimed with the mouse cursor."`
+	VausFire          MouseButton    `toml:"vaus_fire" comment:"Mouse button that presses the Arkanoid Vaus fire button. The paddle is turned by moving the mouse left and right."`
+	Player1           Keymap         `toml:"player1" comment:"Player 1 keymap."`
+	Player2           Keymap         `toml:"player2" comment:"Player 2 keymap."`
+	Player3           Keymap         `toml:"player3" comment:"Player 3 keymap. Only used with a four player adapter."`
+	Player4           Keymap         `toml:"player4" comment:"Player 4 keymap. Only used with a four player adapter."`
 }
 
 func (i Input) ResetHoldFrames() int {
@@ -68,10 +105,30 @@ func (i Input) ResetHoldFrames() int {
 }
 
 type Audio struct {
-	Enabled    bool          `toml:"enabled" comment:"Enables audio output."`
-	Volume     float64       `toml:"volume" comment:"Output volume (between 0 and 1)."`
-	Channels   AudioChannels `toml:"channels" comment:"Toggles specific audio channels."`
-	BufferSize Bytes         `toml:"buffer_size" comment:"Audio buffer size. Try increasing this if audio pops or stutters."`
+	Enabled    bool            `toml:"enabled" comment:"Enables audio output."`
+	Volume     float64         `toml:"volume" comment:"Output volume (between 0 and 1)."`
+	Channels   AudioChannels   `toml:"channels" comment:"Toggles specific audio channels."`
+	Expansion  ExpansionVolume `toml:"expansion" comment:"Volume of each cartridge expansion audio chip (between 0 and 2). At 1, chips are mixed at their original hardware loudness."`
+	BufferSize Bytes           `toml:"buffer_size" comment:"Audio buffer size. Try increasing this if audio pops or stutters."`
+	Latency    Duration        `toml:"latency" comment:"Target amount of audio to keep buffered. Playback is sped up or slowed down by up to 0.5% to stay near this target, which prevents pops when the frame rate drifts. Must be less than the buffer size. Set to 0 to disable."`
+	Stems      bool            `toml:"stems" comment:"When dumping audio, also write Square 1, Square 2, Triangle, Noise, and DMC to separate WAV files. Each channel is mixed on its own, so the stems do not sum exactly to the mix."`
+}
+
+type ExpansionVolume struct {
+	MMC5      float64 `toml:"mmc5"`
+	VRC6      float64 `toml:"vrc6"`
+	Sunsoft5B float64 `toml:"sunsoft_5b"`
+	FDS       float64 `toml:"fds"`
+}
+
+// ExpansionMix returns the enabled mapper expansion audio channels and the volume of each chip.
+func (a Audio) ExpansionMix() cartridge.AudioMix {
+	mix := cartridge.AudioMix{Channels: a.Channels.Expansion()}
+	mix.Volume[cartridge.ChipMMC5] = float32(a.Expansion.MMC5)
+	mix.Volume[cartridge.ChipVRC6] = float32(a.Expansion.VRC6)
+	mix.Volume[cartridge.ChipSunsoft5B] = float32(a.Expansion.Sunsoft5B)
+	mix.Volume[cartridge.ChipFDS] = float32(a.Expansion.FDS)
+	return mix
 }
 
 type AudioChannels struct {
@@ -80,11 +137,78 @@ type AudioChannels struct {
 	Square2  bool `toml:"square_2"`
 	Noise    bool `toml:"noise"`
 	PCM      bool `toml:"pcm"`
+
+	MMC5Pulse1 bool `toml:"mmc5_pulse_1"`
+	MMC5Pulse2 bool `toml:"mmc5_pulse_2"`
+	MMC5PCM    bool `toml:"mmc5_pcm"`
+
+	VRC6Pulse1   bool `toml:"vrc6_pulse_1"`
+	VRC6Pulse2   bool `toml:"vrc6_pulse_2"`
+	VRC6Sawtooth bool `toml:"vrc6_sawtooth"`
+
+	Sunsoft5BA bool `toml:"sunsoft_5b_a"`
+	Sunsoft5BB bool `toml:"sunsoft_5b_b"`
+	Sunsoft5BC bool `toml:"sunsoft_5b_c"`
+
+	FDS bool `toml:"fds"`
+}
+
+// Expansion returns the enabled mapper expansion audio channels.
+func (a AudioChannels) Expansion() cartridge.AudioChannel {
+	var channels cartridge.AudioChannel
+	if a.MMC5Pulse1 {
+		channels |= cartridge.ChannelMMC5Pulse1
+	}
+	if a.MMC5Pulse2 {
+		channels |= cartridge.ChannelMMC5Pulse2
+	}
+	if a.MMC5PCM {
+		channels |= cartridge.ChannelMMC5PCM
+	}
+	if a.VRC6Pulse1 {
+		channels |= cartridge.ChannelVRC6Pulse1
+	}
+	if a.VRC6Pulse2 {
+		channels |= cartridge.ChannelVRC6Pulse2
+	}
+	if a.VRC6Sawtooth {
+		channels |= cartridge.ChannelVRC6Sawtooth
+	}
+	if a.Sunsoft5BA {
+		channels |= cartridge.ChannelSunsoft5BA
+	}
+	if a.Sunsoft5BB {
+		channels |= cartridge.ChannelSunsoft5BB
+	}
+	if a.Sunsoft5BC {
+		channels |= cartridge.ChannelSunsoft5BC
+	}
+	if a.FDS {
+		channels |= cartridge.ChannelFDS
+	}
+	return channels
+}
+
+type Emulation struct {
+	Region region.Region `toml:"region" comment:"Console region timing. One of: auto, ntsc, pal, dendy. Auto uses the NES 2.0 header, then the region in the game's name."`
 }
 
 type Debug struct {
-	Enabled bool `toml:"enabled"`
-	Trace   bool `toml:"trace"`
+	Enabled  bool   `toml:"enabled"`
+	Trace    bool   `toml:"trace"`
+	Debugger string `toml:"debugger"`
+	GDBAddr  string `toml:"gdb_addr"`
+}
+
+const (
+	DebuggerREPL = "repl"
+	DebuggerGDB  = "gdb"
+
+	DefaultGDBAddr = "localhost:6502"
+)
+
+func DebuggerStrings() []string {
+	return []string{DebuggerREPL, DebuggerGDB}
 }
 
 const configDir = "gones"
@@ -116,6 +240,15 @@ func GetStatesDir() (string, error) {
 	return filepath.Join(configDir, "states"), nil
 }
 
+func GetGamesDir() (string, error) {
+	configDir, err := GetDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "games"), nil
+}
+
 func GetSRAMDir() (string, error) {
 	configDir, err := GetDir()
 	if err != nil {
@@ -134,6 +267,25 @@ func GetPaletteDir() (string, error) {
 	return filepath.Join(configDir, "palettes"), nil
 }
 
+// GetFDSBIOSPath returns the path to the user-supplied Famicom Disk System BIOS.
+func GetFDSBIOSPath() (string, error) {
+	configDir, err := GetDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "disksys.rom"), nil
+}
+
+func GetShaderDir() (string, error) {
+	configDir, err := GetDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "shaders"), nil
+}
+
 func GetScreenshotDir() (string, error) {
 	configDir, err := GetDir()
 	if err != nil {
@@ -142,3 +294,12 @@ func GetScreenshotDir() (string, error) {
 
 	return filepath.Join(configDir, "screenshots"), nil
 }
+
+func GetRecordingDir() (string, error) {
+	configDir, err := GetDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "recordings"), nil
+}
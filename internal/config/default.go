@@ -14,11 +14,27 @@ func NewDefault() *Config {
 			PauseUnfocused:    true,
 			RemoveSpriteLimit: true,
 			Overscan:          Overscan{Top: 8, Bottom: 8},
+			NTSC: NTSC{
+				Resolution: 3,
+				Saturation: 1,
+				Artifacts:  1,
+			},
+			Shader: []string{},
+			Uniforms: Uniforms{
+				"scanlines": {"Strength": 0.3},
+				"mask":      {"Strength": 0.25},
+				"crt":       {"Curvature": 0.03, "Vignette": 0.3},
+			},
 		},
 		State: State{
 			Resume:           true,
 			AutosaveInterval: Duration(time.Minute),
 			UndoStateCount:   5,
+			Rewind: Rewind{
+				Enabled:  true,
+				Duration: Duration(30 * time.Second),
+				Interval: 2,
+			},
 		},
 		Input: Input{
 			Reset:             Key(ebiten.KeyR),
@@ -29,12 +45,19 @@ func NewDefault() *Config {
 
 			FastForward:     Key(ebiten.KeyF),
 			FastForwardRate: 3,
+			Rewind:          Key(ebiten.KeyBackspace),
 			Fullscreen:      Key(ebiten.KeyF11),
 
-			Screenshot: Key(ebiten.KeyBackslash),
+			Screenshot:    Key(ebiten.KeyBackslash),
+			Record:        Key(ebiten.KeyF9),
+			DumpAudio:     Key(ebiten.KeyF10),
+			FDSSwitchSide: Key(ebiten.KeyF3),
 
 			TurboDutyCycle: 4,
 
+			ZapperTrigger: MouseButton(ebiten.MouseButtonLeft),
+			VausFire:      MouseButton(ebiten.MouseButtonLeft),
+
 			Player1: Keymap{
 				A:      Key(ebiten.KeyM),
 				B:      Key(ebiten.KeyN),
@@ -46,6 +69,21 @@ func NewDefault() *Config {
 				Right:  Key(ebiten.KeyD),
 				ATurbo: Key(ebiten.KeyK),
 				BTurbo: Key(ebiten.KeyJ),
+
+				Gamepad: Gamepad{
+					Device:    0,
+					LeftStick: true,
+					A:         GamepadButton(ebiten.StandardGamepadButtonRightBottom),
+					B:         GamepadButton(ebiten.StandardGamepadButtonRightLeft),
+					Start:     GamepadButton(ebiten.StandardGamepadButtonCenterRight),
+					Select:    GamepadButton(ebiten.StandardGamepadButtonCenterLeft),
+					Up:        GamepadButton(ebiten.StandardGamepadButtonLeftTop),
+					Down:      GamepadButton(ebiten.StandardGamepadButtonLeftBottom),
+					Left:      GamepadButton(ebiten.StandardGamepadButtonLeftLeft),
+					Right:     GamepadButton(ebiten.StandardGamepadButtonLeftRight),
+					ATurbo:    GamepadButton(ebiten.StandardGamepadButtonRightRight),
+					BTurbo:    GamepadButton(ebiten.StandardGamepadButtonRightTop),
+				},
 			},
 
 			Player2: Keymap{
@@ -59,7 +97,24 @@ func NewDefault() *Config {
 				Right:  Key(ebiten.KeyPageDown),
 				ATurbo: Key(ebiten.KeyKP6),
 				BTurbo: Key(ebiten.KeyKP5),
+
+				Gamepad: Gamepad{
+					Device:    1,
+					LeftStick: true,
+					A:         GamepadButton(ebiten.StandardGamepadButtonRightBottom),
+					B:         GamepadButton(ebiten.StandardGamepadButtonRightLeft),
+					Start:     GamepadButton(ebiten.StandardGamepadButtonCenterRight),
+					Select:    GamepadButton(ebiten.StandardGamepadButtonCenterLeft),
+					Up:        GamepadButton(ebiten.StandardGamepadButtonLeftTop),
+					Down:      GamepadButton(ebiten.StandardGamepadButtonLeftBottom),
+					Left:      GamepadButton(ebiten.StandardGamepadButtonLeftLeft),
+					Right:     GamepadButton(ebiten.StandardGamepadButtonLeftRight),
+					ATurbo:    GamepadButton(ebiten.StandardGamepadButtonRightRight),
+					BTurbo:    GamepadButton(ebiten.StandardGamepadButtonRightTop),
+				},
 			},
+			Player3: gamepadKeymap(2),
+			Player4: gamepadKeymap(3),
 		},
 		Audio: Audio{
 			Enabled: true,
@@ -70,8 +125,60 @@ func NewDefault() *Config {
 				Square2:  true,
 				Noise:    true,
 				PCM:      true,
+
+				MMC5Pulse1: true,
+				MMC5Pulse2: true,
+				MMC5PCM:    true,
+
+				VRC6Pulse1:   true,
+				VRC6Pulse2:   true,
+				VRC6Sawtooth: true,
+
+				Sunsoft5BA: true,
+				Sunsoft5BB: true,
+				Sunsoft5BC: true,
+
+				FDS: true,
+			},
+			Expansion: ExpansionVolume{
+				MMC5:      1,
+				VRC6:      1,
+				Sunsoft5B: 1,
+				FDS:       1,
 			},
 			BufferSize: 40 * bytefmt.KiB,
+			Latency:    Duration(50 * time.Millisecond),
+		},
+	}
+}
+
+// gamepadKeymap returns a keymap with no keys bound, controlled by the gamepad at the given index.
+func gamepadKeymap(device int) Keymap {
+	return Keymap{
+		A:      Key(-1),
+		B:      Key(-1),
+		Start:  Key(-1),
+		Select: Key(-1),
+		Up:     Key(-1),
+		Down:   Key(-1),
+		Left:   Key(-1),
+		Right:  Key(-1),
+		ATurbo: Key(-1),
+		BTurbo: Key(-1),
+
+		Gamepad: Gamepad{
+			Device:    device,
+			LeftStick: true,
+			A:         GamepadButton(ebiten.StandardGamepadButtonRightBottom),
+			B:         GamepadButton(ebiten.StandardGamepadButtonRightLeft),
+			Start:     GamepadButton(ebiten.StandardGamepadButtonCenterRight),
+			Select:    GamepadButton(ebiten.StandardGamepadButtonCenterLeft),
+			Up:        GamepadButton(ebiten.StandardGamepadButtonLeftTop),
+			Down:      GamepadButton(ebiten.StandardGamepadButtonLeftBottom),
+			Left:      GamepadButton(ebiten.StandardGamepadButtonLeftLeft),
+			Right:     GamepadButton(ebiten.StandardGamepadButtonLeftRight),
+			ATurbo:    GamepadButton(ebiten.StandardGamepadButtonRightRight),
+			BTurbo:    GamepadButton(ebiten.StandardGamepadButtonRightTop),
 		},
 	}
 }
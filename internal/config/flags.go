@@ -1,6 +1,9 @@
 package config
 
 import (
+	"strings"
+
+	"gabe565.com/gones/internal/region"
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +17,13 @@ func Flags(cmd *cobra.Command) {
 
 	cmd.Flags().Bool("debug", false, "Start with step debugging enabled")
 	cmd.Flags().Bool("trace", false, "Enable trace logging")
+	cmd.Flags().String("debugger", "", "Attach a CPU debugger, starting paused (one of "+strings.Join(DebuggerStrings(), ", ")+")")
+	if err := cmd.RegisterFlagCompletionFunc("debugger", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return DebuggerStrings(), cobra.ShellCompDirectiveNoFileComp
+	}); err != nil {
+		panic(err)
+	}
+	cmd.Flags().String("gdb-addr", "", "Listen address for the GDB remote stub (default "+DefaultGDBAddr+")")
 	cmd.Flags().Float64("scale", 3, "Default UI scale")
 	cmd.Flags().BoolP("fullscreen", "f", false, "Start in fullscreen")
 	cmd.Flags().BoolP("audio", "a", true, "Enabled audio output")
@@ -24,6 +34,14 @@ func Flags(cmd *cobra.Command) {
 	}); err != nil {
 		panic(err)
 	}
+	cmd.Flags().Bool("stems", false, "When dumping audio, also write each APU channel to its own WAV file")
+	cmd.Flags().Bool("ntsc", false, "Enable the NTSC composite video filter")
+	cmd.Flags().String("region", region.Auto.String(), "Console region timing (one of "+strings.Join(region.Strings(), ", ")+")")
+	if err := cmd.RegisterFlagCompletionFunc("region", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+		return region.Strings(), cobra.ShellCompDirectiveNoFileComp
+	}); err != nil {
+		panic(err)
+	}
 	cmd.Flags().Bool("pause-unfocused", true, "Pauses when the window loses focus. Optional, but audio will be glitchy when the game is running in the background.")
 }
 
@@ -31,11 +49,16 @@ func flagTable() map[string]string {
 	return map[string]string{
 		"debug":           "debug.enabled",
 		"trace":           "debug.trace",
+		"debugger":        "debug.debugger",
+		"gdb-addr":        "debug.gdb_addr",
 		"scale":           "ui.scale",
 		"fullscreen":      "ui.fullscreen",
 		"audio":           "audio.enabled",
+		"stems":           "audio.stems",
 		"resume":          "state.resume",
 		"palette":         "ui.palette",
+		"ntsc":            "ui.ntsc.enabled",
 		"pause-unfocused": "ui.pause_unfocused",
+		"region":          "emulation.region",
 	}
 }
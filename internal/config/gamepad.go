@@ -0,0 +1,93 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gabe565.com/gones/internal/controller/button"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type Gamepad struct {
+	Device    int  `toml:"device" comment:"Index of the gamepad to use, in the order gamepads were connected. Set to -1 to disable gamepad input."`
+	LeftStick bool `toml:"left_stick" comment:"Use the left analog stick as a D-pad."`
+
+	A      GamepadButton `toml:"a"`
+	B      GamepadButton `toml:"b"`
+	Start  GamepadButton `toml:"start"`
+	Select GamepadButton `toml:"select"`
+	Up     GamepadButton `toml:"up"`
+	Down   GamepadButton `toml:"down"`
+	Left   GamepadButton `toml:"left"`
+	Right  GamepadButton `toml:"right"`
+
+	ATurbo GamepadButton `toml:"a_turbo" comment:"Button to press the A button repeatedly (must be held)."`
+	BTurbo GamepadButton `toml:"b_turbo" comment:"Button to press the B button repeatedly (must be held)."`
+}
+
+func (g Gamepad) GetMap() map[button.Button]ebiten.StandardGamepadButton {
+	return map[button.Button]ebiten.StandardGamepadButton{
+		button.A:      ebiten.StandardGamepadButton(g.A),
+		button.B:      ebiten.StandardGamepadButton(g.B),
+		button.Start:  ebiten.StandardGamepadButton(g.Start),
+		button.Select: ebiten.StandardGamepadButton(g.Select),
+		button.Up:     ebiten.StandardGamepadButton(g.Up),
+		button.Down:   ebiten.StandardGamepadButton(g.Down),
+		button.Left:   ebiten.StandardGamepadButton(g.Left),
+		button.Right:  ebiten.StandardGamepadButton(g.Right),
+	}
+}
+
+func (g Gamepad) GetTurboMap() map[button.Button]ebiten.StandardGamepadButton {
+	return map[button.Button]ebiten.StandardGamepadButton{
+		button.A: ebiten.StandardGamepadButton(g.ATurbo),
+		button.B: ebiten.StandardGamepadButton(g.BTurbo),
+	}
+}
+
+// GamepadButton is a button in ebiten's standard gamepad layout.
+// Face buttons are named by position, so RightBottom is A on an Xbox controller and Cross on a PlayStation controller.
+type GamepadButton ebiten.StandardGamepadButton
+
+//nolint:gochecknoglobals
+var gamepadButtonNames = map[ebiten.StandardGamepadButton]string{
+	ebiten.StandardGamepadButtonRightBottom:      "RightBottom",
+	ebiten.StandardGamepadButtonRightRight:       "RightRight",
+	ebiten.StandardGamepadButtonRightLeft:        "RightLeft",
+	ebiten.StandardGamepadButtonRightTop:         "RightTop",
+	ebiten.StandardGamepadButtonFrontTopLeft:     "FrontTopLeft",
+	ebiten.StandardGamepadButtonFrontTopRight:    "FrontTopRight",
+	ebiten.StandardGamepadButtonFrontBottomLeft:  "FrontBottomLeft",
+	ebiten.StandardGamepadButtonFrontBottomRight: "FrontBottomRight",
+	ebiten.StandardGamepadButtonCenterLeft:       "CenterLeft",
+	ebiten.StandardGamepadButtonCenterRight:      "CenterRight",
+	ebiten.StandardGamepadButtonLeftStick:        "LeftStick",
+	ebiten.StandardGamepadButtonRightStick:       "RightStick",
+	ebiten.StandardGamepadButtonLeftTop:          "LeftTop",
+	ebiten.StandardGamepadButtonLeftBottom:       "LeftBottom",
+	ebiten.StandardGamepadButtonLeftLeft:         "LeftLeft",
+	ebiten.StandardGamepadButtonLeftRight:        "LeftRight",
+	ebiten.StandardGamepadButtonCenterCenter:     "CenterCenter",
+}
+
+var ErrInvalidGamepadButton = errors.New("invalid gamepad button")
+
+func (b GamepadButton) MarshalText() ([]byte, error) {
+	return []byte(gamepadButtonNames[ebiten.StandardGamepadButton(b)]), nil
+}
+
+func (b *GamepadButton) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*b = GamepadButton(-1)
+		return nil
+	}
+
+	for button, name := range gamepadButtonNames {
+		if strings.EqualFold(string(text), name) {
+			*b = GamepadButton(button)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidGamepadButton, text)
+}
@@ -17,6 +17,8 @@ type Keymap struct {
 
 	ATurbo Key `toml:"a_turbo" comment:"Key to press the A button repeatedly (must be held)."`
 	BTurbo Key `toml:"b_turbo" comment:"Key to press the B button repeatedly (must be held)."`
+
+	Gamepad Gamepad `toml:"gamepad" comment:"Gamepad bindings. Buttons use the standard gamepad layout, and can be left blank to unbind them."`
 }
 
 func (k Keymap) GetMap() map[button.Button]ebiten.Key {
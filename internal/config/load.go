@@ -202,6 +202,14 @@ func fixConfig(k *koanf.Koanf) error {
 		}
 	}
 
+	// Rewind interval min
+	if val := k.Int("state.rewind.interval"); val < 1 {
+		slog.Warn("Rewind interval must be 1 or greater. Setting value to 1.")
+		if err := k.Set("state.rewind.interval", 1); err != nil {
+			return err
+		}
+	}
+
 	// Volume min/max
 	if val := k.Float64("audio.volume"); val < 0 {
 		slog.Warn("Minimum volume is 0. Setting to 0.")
@@ -215,6 +223,21 @@ func fixConfig(k *koanf.Koanf) error {
 		}
 	}
 
+	// Expansion volume min/max
+	for _, key := range []string{"audio.expansion.mmc5", "audio.expansion.vrc6", "audio.expansion.sunsoft_5b", "audio.expansion.fds"} {
+		if val := k.Float64(key); val < 0 {
+			slog.Warn("Minimum expansion volume is 0. Setting to 0.", "key", key)
+			if err := k.Set(key, 0); err != nil {
+				return err
+			}
+		} else if val > 2 {
+			slog.Warn("Maximum expansion volume is 2. Setting to 2.", "key", key)
+			if err := k.Set(key, 2); err != nil {
+				return err
+			}
+		}
+	}
+
 	// Overscan min/max
 	if val := k.Int("ui.trim.top"); val < 0 || val >= consts.Height/2 {
 		slog.Warn("Invalid top trim. Setting to default.")
@@ -259,5 +282,13 @@ func fixConfig(k *koanf.Koanf) error {
 		}
 	}
 
+	// Audio latency
+	if val := k.Duration("audio.latency"); val < 0 {
+		slog.Warn("Audio latency must be 0 or greater. Setting to default.")
+		if err := k.Set("audio.latency", NewDefault().Audio.Latency); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
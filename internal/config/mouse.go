@@ -0,0 +1,42 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// MouseButton is a mouse button.
+type MouseButton ebiten.MouseButton
+
+//nolint:gochecknoglobals
+var mouseButtonNames = map[ebiten.MouseButton]string{
+	ebiten.MouseButtonLeft:   "Left",
+	ebiten.MouseButtonRight:  "Right",
+	ebiten.MouseButtonMiddle: "Middle",
+	ebiten.MouseButton3:      "Back",
+	ebiten.MouseButton4:      "Forward",
+}
+
+var ErrInvalidMouseButton = errors.New("invalid mouse button")
+
+func (b MouseButton) MarshalText() ([]byte, error) {
+	return []byte(mouseButtonNames[ebiten.MouseButton(b)]), nil
+}
+
+func (b *MouseButton) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*b = MouseButton(-1)
+		return nil
+	}
+
+	for button, name := range mouseButtonNames {
+		if strings.EqualFold(string(text), name) {
+			*b = MouseButton(button)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidMouseButton, text)
+}
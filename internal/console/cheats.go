@@ -0,0 +1,34 @@
+//go:build !js
+
+package console
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"gabe565.com/gones/internal/cheat"
+)
+
+func (c *Console) LoadCheats() error {
+	path, err := c.CheatsPath()
+	if err != nil {
+		return err
+	}
+
+	cheats, err := cheat.Load(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	engine, err := cheat.New(cheats)
+	if engine.Len() != 0 {
+		slog.Info("Loaded cheats", "file", filepath.Base(path), "count", engine.Len())
+		c.Bus.SetCheats(engine)
+	}
+	return err
+}
@@ -14,8 +14,14 @@ import (
 	"gabe565.com/gones/internal/config"
 	"gabe565.com/gones/internal/consts"
 	"gabe565.com/gones/internal/cpu"
+	"gabe565.com/gones/internal/debugger"
 	"gabe565.com/gones/internal/ppu"
+	"gabe565.com/gones/internal/ppu/ntsc"
 	"gabe565.com/gones/internal/ppu/palette"
+	"gabe565.com/gones/internal/record"
+	"gabe565.com/gones/internal/region"
+	"gabe565.com/gones/internal/rewind"
+	"gabe565.com/gones/internal/shader"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
 )
@@ -44,22 +50,49 @@ type Console struct {
 	Cartridge *cartridge.Cartridge
 	Mapper    cartridge.Mapper
 
-	audioCtx       *audio.Context
-	player         *audio.Player
+	// DotRemainder carries fractional PPU dots between CPU steps when the clock ratio is not a whole number.
+	DotRemainder uint
+
+	region   region.Region
+	dotRatio [2]uint
+
+	audioCtx *audio.Context
+	player   *audio.Player
+	// audioBuffered is the smoothed duration of buffered audio in seconds, which is used to sync audio
+	audioBuffered float64
+
 	actionOnUpdate UpdateAction
 	enableTrace    bool
 	debug          Debug
+	debugger       *debugger.Debugger
 
 	undoSaveStates [][]byte
 	undoLoadStates [][]byte
 
+	rewind    *rewind.Buffer
+	rewinding bool
+
+	movie    *movieState
+	headless bool
+	nsf      *nsfPlayer
+	ntsc     *ntsc.Filter
+	shaders  *shader.Pipeline
+	viewer   *viewer
+	// redraw draws the last frame again after the viewer is hidden
+	redraw bool
+
+	recordPath string
+	recording  *recording
+	dumpPath   string
+	audioDump  *record.AudioDump
+
 	autosave *time.Ticker
 	rate     uint8
 
 	willScreenshot bool
 }
 
-func New(conf *config.Config, cart *cartridge.Cartridge) (*Console, error) {
+func New(conf *config.Config, cart *cartridge.Cartridge, opts ...Option) (*Console, error) {
 	console := Console{
 		Config:    conf,
 		Cartridge: cart,
@@ -75,23 +108,65 @@ func New(conf *config.Config, cart *cartridge.Cartridge) (*Console, error) {
 		return &console, err
 	}
 
-	if err := console.LoadSRAM(); err != nil && !errors.Is(err, os.ErrNotExist) {
-		return &console, err
+	for _, opt := range opts {
+		if err := opt(&console); err != nil {
+			return &console, err
+		}
+	}
+	if _, ok := console.Mapper.(*cartridge.MapperNSF); ok && console.nsf == nil {
+		console.nsf = &nsfPlayer{}
+	}
+
+	if conf.Debug.Debugger != "" {
+		// The debugger can pause mid-frame, which would desync movies
+		if console.persistent() {
+			console.debugger = debugger.New()
+		} else {
+			slog.Warn("Debugger is disabled during movies and headless runs")
+		}
+	}
+
+	// Movies and headless runs must be reproducible, so they never touch saves on disk
+	if console.persistent() {
+		if err := console.LoadSRAM(); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return &console, err
+		}
 	}
 
 	if err := palette.LoadPalFile(conf.UI.Palette); err != nil {
 		return &console, err
 	}
 
-	console.PPU = ppu.New(conf, console.Mapper)
-	console.APU = apu.New(conf)
-	console.Bus = bus.New(conf, console.Mapper, console.PPU, console.APU)
+	console.region = conf.Emulation.Region.Resolve(cart)
+	console.dotRatio[0], console.dotRatio[1] = console.region.PPUClockRatio()
+	slog.Info("Using region", "region", console.region)
+
+	console.PPU = ppu.New(conf, console.Mapper, console.region)
+	console.APU = apu.New(conf, console.region)
+	if conf.UI.NTSC.Enabled && console.nsf == nil {
+		console.ntsc = ntsc.New(conf.UI.NTSC, console.PPU.Width(), console.PPU.Height())
+	}
+	if len(conf.UI.Shader) != 0 && console.nsf == nil && !console.headless {
+		if console.shaders, err = shader.New(conf.UI.Shader, conf.UI.Uniforms); err != nil {
+			return &console, err
+		}
+	}
+	console.Bus = bus.New(conf, console.Mapper, console.PPU, console.APU, screen{&console})
 	console.CPU = cpu.New(console.Bus)
+	if console.debugger != nil {
+		console.debugger.Attach(console.CPU, console.Bus)
+	}
+
+	if console.persistent() {
+		if err := console.LoadCheats(); err != nil {
+			slog.Error("Failed to load cheats", "error", err)
+		}
+	}
 
 	console.PPU.SetCPU(console.CPU)
 	console.APU.SetCPU(console.CPU)
 
-	if conf.Audio.Enabled {
+	if conf.Audio.Enabled && !console.headless {
 		console.audioCtx = audio.NewContext(consts.AudioSampleRate)
 		console.player, err = console.audioCtx.NewPlayerF32(console.APU)
 		if err != nil {
@@ -106,7 +181,11 @@ func New(conf *config.Config, cart *cartridge.Cartridge) (*Console, error) {
 		console.APU.Enabled = false
 	}
 
-	if conf.State.Resume {
+	resume := conf.State.Resume && !console.headless && console.nsf == nil
+	if console.movie != nil {
+		resume = resume && console.movie.mode == movieRecord && console.movie.fromState
+	}
+	if resume {
 		if err := console.LoadStateNum(AutoSaveNum); err != nil {
 			if !errors.Is(err, os.ErrNotExist) {
 				return &console, err
@@ -114,10 +193,36 @@ func New(conf *config.Config, cart *cartridge.Cartridge) (*Console, error) {
 		}
 	}
 
+	if console.movie != nil {
+		if err := console.startMovie(); err != nil {
+			return &console, err
+		}
+	}
+
+	if console.nsf != nil {
+		console.startNSF()
+	}
+
+	if console.recordPath != "" {
+		if err := console.StartRecording(console.recordPath); err != nil {
+			return &console, err
+		}
+	}
+	if console.dumpPath != "" {
+		if err := console.StartAudioDump(console.dumpPath); err != nil {
+			return &console, err
+		}
+	}
+
 	console.SetTrace(conf.Debug.Trace)
 	console.SetDebug(conf.Debug.Enabled)
 
-	if duration := conf.State.AutosaveInterval; duration != 0 {
+	if conf.State.Rewind.Enabled && console.persistent() && console.debugger == nil {
+		rewindConf := conf.State.Rewind
+		console.rewind = rewind.New(time.Duration(rewindConf.Duration), int(rewindConf.Interval), console.region.TargetFrameRate())
+	}
+
+	if duration := conf.State.AutosaveInterval; duration != 0 && console.persistent() {
 		console.autosave = time.NewTicker(time.Duration(duration))
 	}
 
@@ -129,6 +234,13 @@ func (c *Console) Close() error {
 	if c.autosave != nil {
 		c.autosave.Stop()
 	}
+	errs = append(errs, c.StopRecording(), c.StopAudioDump())
+	if c.movie != nil {
+		return errors.Join(append(errs, c.closeMovie())...)
+	}
+	if c.headless || c.nsf != nil {
+		return errors.Join(errs...)
+	}
 	if c.Config.State.Resume {
 		errs = append(errs, c.SaveStateNum(AutoSaveNum, false))
 	}
@@ -149,12 +261,21 @@ func (c *Console) Step(render bool) {
 		mapper.OnCPUStep(cycles)
 	}
 
-	for range cycles * 3 {
+	dots := cycles*c.dotRatio[0] + c.DotRemainder
+	c.DotRemainder = dots % c.dotRatio[1]
+	for range dots / c.dotRatio[1] {
 		c.PPU.Step(render)
 	}
+	if c.recording != nil {
+		c.recordFrame()
+	}
 
+	expansion, _ := c.Mapper.(cartridge.MapperAudio)
 	for range cycles {
-		irq = c.APU.Step() || irq
+		if expansion != nil {
+			expansion.StepAudio()
+		}
+		irq = c.APU.Step(expansion) || irq
 	}
 
 	if mapper, ok := c.Mapper.(cartridge.MapperIRQ); ok {
@@ -170,7 +291,19 @@ func (c *Console) Reset() {
 	c.APU.Reset()
 }
 
-func (c *Console) Layout(_, _ int) (int, int) {
+func (c *Console) Layout(outsideWidth, outsideHeight int) (int, int) {
+	if c.viewer != nil {
+		return viewerWidth, viewerHeight
+	}
+	if c.shaders != nil {
+		// Shaders draw at the window's full resolution
+		scale := ebiten.Monitor().DeviceScaleFactor()
+		return int(float64(outsideWidth) * scale), int(float64(outsideHeight) * scale)
+	}
+	if c.ntsc != nil {
+		size := c.ntsc.Image().Rect.Size()
+		return size.X, size.Y
+	}
 	return c.Width(), c.Height()
 }
 
@@ -197,17 +330,58 @@ func (c *Console) Update() error {
 		return nil
 	}
 
-	for i := range c.rate {
-		if c.rate != 1 {
-			c.PPU.RenderDone = false
+	if c.rewinding {
+		if err := c.rewind.Rewind(c.decodeState); err != nil && !errors.Is(err, rewind.ErrEmpty) {
+			slog.Error("Failed to rewind", "error", err)
+		}
+
+		// Snapshots are taken after a frame is rendered, so render the next one to update the screen
+		c.PPU.RenderDone = false
+		for !c.PPU.RenderDone {
+			c.Step(true)
+		}
+	} else if c.debugger != nil {
+		c.debugger.Lock()
+		defer c.debugger.Unlock()
+		if c.debugger.Paused() {
+			return nil
+		}
+
+		// Frames may be split across updates when the debugger pauses mid-frame
+		for !c.PPU.RenderDone {
+			if c.debuggerStep(true) {
+				return nil
+			}
 		}
-		for {
-			c.Step(i == c.rate-1)
+	} else {
+		for i := range c.rate {
+			if c.rate != 1 {
+				c.PPU.RenderDone = false
+			}
+			if c.movie != nil {
+				c.stepMovie()
+			}
+			for {
+				// Recordings need every frame, even while fast-forwarding
+				c.Step(i == c.rate-1 || c.recording != nil)
 
-			if c.PPU.RenderDone || (runtime.GOOS != "js" && c.debug == DebugStepFrame) {
-				break
+				if c.PPU.RenderDone || (runtime.GOOS != "js" && c.debug == DebugStepFrame) {
+					break
+				}
 			}
 		}
+
+		if c.nsf != nil {
+			c.updateNSF(int(c.rate))
+		}
+
+		if c.rewind != nil && c.debug == DebugDisabled {
+			if err := c.rewind.Capture(int(c.rate), c.encodeState); err != nil {
+				slog.Error("Failed to capture rewind state", "error", err)
+			}
+		}
+
+		c.syncAudio()
 	}
 
 	if runtime.GOOS != "js" && c.debug != DebugDisabled {
@@ -240,10 +414,33 @@ func (c *Console) Draw(screen *ebiten.Image) {
 		}
 	}
 
-	if c.PPU.RenderDone {
+	if c.nsf != nil {
+		c.drawNSF(screen)
+		c.PPU.RenderDone = false
+		return
+	}
+
+	if c.viewer != nil {
+		c.viewer.Draw(screen, c.PPU)
+		c.PPU.RenderDone = false
+		return
+	}
+
+	if c.PPU.RenderDone || c.redraw {
 		img := c.PPU.Image()
-		screen.WritePixels(img.Pix)
+		if c.ntsc != nil {
+			img = c.ntsc.Render(c.PPU.Pixels(), c.PPU.LinePhases())
+		}
+		if c.shaders != nil {
+			c.shaders.SetFrame(img)
+		} else {
+			screen.WritePixels(img.Pix)
+		}
 		c.PPU.RenderDone = false
+		c.redraw = false
+	}
+	if c.shaders != nil {
+		c.shaders.Draw(screen)
 	}
 }
 
@@ -275,6 +472,11 @@ func (c *Console) SetDebug(v bool) {
 	}
 }
 
+// Region returns the region whose timing is being emulated.
+func (c *Console) Region() region.Region {
+	return c.region
+}
+
 func (c *Console) Width() int {
 	return c.PPU.Width()
 }
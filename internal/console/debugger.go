@@ -0,0 +1,15 @@
+package console
+
+import "gabe565.com/gones/internal/debugger"
+
+// Debugger returns the attached CPU debugger, or nil if it is disabled.
+func (c *Console) Debugger() *debugger.Debugger {
+	return c.debugger
+}
+
+// debuggerStep steps the console once, reporting whether the debugger paused execution.
+func (c *Console) debuggerStep(render bool) bool {
+	stalled := c.CPU.Stall != 0
+	c.Step(render)
+	return c.debugger.AfterStep(stalled)
+}
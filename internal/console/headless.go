@@ -0,0 +1,55 @@
+package console
+
+import (
+	"context"
+	"image/png"
+	"io"
+)
+
+// WithHeadless prepares the console to be driven without a window or audio device.
+//
+// Audio output is disabled, and SRAM, resume states, autosave, and rewind are
+// skipped so that runs are reproducible and never touch saves in the config directory.
+func WithHeadless() Option {
+	return func(c *Console) error {
+		c.headless = true
+		return nil
+	}
+}
+
+// RunFrames steps the console until n frames have been rendered, or forever if n is 0.
+// It stops early when ctx is canceled or the CPU fails to step.
+func (c *Console) RunFrames(ctx context.Context, n int) error {
+	for frame := 0; n == 0 || frame < n; frame++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if c.movie != nil {
+			c.stepMovie()
+		}
+
+		c.PPU.RenderDone = false
+		for !c.PPU.RenderDone {
+			if c.Step(true); c.CPU.StepErr != nil {
+				return c.CPU.StepErr
+			}
+		}
+
+		if c.nsf != nil {
+			c.updateNSF(1)
+		}
+	}
+	return nil
+}
+
+// WriteFramePNG encodes the current framebuffer as a PNG.
+func (c *Console) WriteFramePNG(w io.Writer) error {
+	return png.Encode(w, c.PPU.Image())
+}
+
+// persistent reports whether the console reads and writes saves in the config directory.
+// NSF files have no saves.
+func (c *Console) persistent() bool {
+	return c.movie == nil && !c.headless && c.nsf == nil
+}
@@ -4,6 +4,7 @@ import (
 	"log/slog"
 	"runtime"
 
+	"gabe565.com/gones/internal/cartridge"
 	"gabe565.com/gones/internal/controller"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
@@ -13,8 +14,11 @@ func (c *Console) CheckInput() {
 	c.Bus.UpdateInput()
 
 	if duration := inpututil.KeyPressDuration(ebiten.Key(c.Config.Input.Reset)); duration != 0 {
-		if duration == c.Config.Input.ResetHoldFrames() {
+		if duration == c.Config.Input.ResetHoldFrames() && !c.moviePlaying() {
 			c.Reset()
+			if c.movie != nil {
+				c.movie.reset = true
+			}
 		}
 	}
 
@@ -30,6 +34,16 @@ func (c *Console) CheckInput() {
 		}
 	}
 
+	if c.rewind != nil {
+		if inpututil.IsKeyJustPressed(ebiten.Key(c.Config.Input.Rewind)) {
+			c.rewinding = true
+			c.APU.Enabled = false
+		} else if inpututil.IsKeyJustReleased(ebiten.Key(c.Config.Input.Rewind)) {
+			c.rewinding = false
+			c.APU.Enabled = c.Config.Audio.Enabled
+		}
+	}
+
 	if runtime.GOOS != "js" {
 		if inpututil.IsKeyJustPressed(controller.ToggleDebug) {
 			if c.debug == DebugDisabled {
@@ -57,9 +71,47 @@ func (c *Console) CheckInput() {
 			}
 		}
 
+		if inpututil.IsKeyJustPressed(controller.ToggleViewer) && c.nsf == nil {
+			if c.viewer == nil {
+				slog.Info("Show PPU viewer")
+				c.viewer = newViewer()
+			} else {
+				slog.Info("Hide PPU viewer")
+				c.viewer.Deallocate()
+				c.viewer = nil
+				c.redraw = true
+			}
+		}
+		if c.viewer != nil && inpututil.IsKeyJustPressed(controller.ViewerPalette) {
+			c.viewer.NextPalette()
+		}
+
 		if inpututil.IsKeyJustPressed(ebiten.Key(c.Config.Input.Screenshot)) {
 			c.willScreenshot = true
 		}
+
+		if inpututil.IsKeyJustPressed(ebiten.Key(c.Config.Input.Record)) {
+			if err := c.toggleRecording(); err != nil {
+				slog.Error("Failed to toggle recording", "error", err)
+			}
+		}
+
+		if inpututil.IsKeyJustPressed(ebiten.Key(c.Config.Input.DumpAudio)) {
+			if err := c.toggleAudioDump(); err != nil {
+				slog.Error("Failed to toggle audio dump", "error", err)
+			}
+		}
+	}
+
+	if c.nsf != nil {
+		c.checkNSFInput()
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.Key(c.Config.Input.FDSSwitchSide)) && !c.moviePlaying() {
+		if mapper, ok := c.Mapper.(cartridge.MapperDisk); ok {
+			side := mapper.SwitchSide()
+			slog.Info("Switching disk side", "side", string(rune('A'+side%2)), "disk", side/2+1)
+		}
 	}
 
 	if inpututil.IsKeyJustPressed(ebiten.Key(c.Config.Input.Fullscreen)) {
@@ -0,0 +1,191 @@
+package console
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"gabe565.com/gones/internal/controller"
+	"gabe565.com/gones/internal/movie"
+	"gabe565.com/gones/internal/region"
+)
+
+var (
+	// ErrMovieDevice is returned when a movie is started with an input device that can't be recorded.
+	ErrMovieDevice = errors.New("movies only support standard controllers")
+	// ErrMovieFourScore is returned when a movie is played with a different four player adapter setting than it was recorded with.
+	ErrMovieFourScore = errors.New("movie was recorded with a different four player adapter setting")
+)
+
+type movieMode uint8
+
+const (
+	movieRecord movieMode = iota + 1
+	moviePlay
+)
+
+type movieState struct {
+	mode      movieMode
+	path      string
+	fromState bool
+	movie     *movie.Movie
+	frame     int
+	reset     bool
+}
+
+// Option configures a console during [New].
+type Option func(c *Console) error
+
+// WithMovieRecord records all controller input to an FM2 movie, which is written when the console is closed.
+// If fromState is true, the movie is anchored to the resume state. Otherwise, it is anchored to power-on.
+func WithMovieRecord(path string, fromState bool) Option {
+	return func(c *Console) error {
+		m := movie.New(c.Cartridge)
+		m.Comments = append(m.Comments, "author GoNES")
+		c.movie = &movieState{
+			mode:      movieRecord,
+			path:      path,
+			fromState: fromState,
+			movie:     m,
+		}
+		return nil
+	}
+}
+
+// WithMoviePlayback replaces controller input with the input from an FM2 movie.
+func WithMoviePlayback(path string) Option {
+	return func(c *Console) error {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = f.Close()
+		}()
+
+		m, err := movie.ReadFM2(f)
+		if err != nil {
+			return err
+		}
+
+		if m.ROMChecksum != movie.Checksum(c.Cartridge) {
+			slog.Warn("Movie was recorded with a different ROM", "rom", m.ROMFilename)
+		}
+
+		c.movie = &movieState{
+			mode:      moviePlay,
+			path:      path,
+			fromState: len(m.SaveState) != 0,
+			movie:     m,
+		}
+		return nil
+	}
+}
+
+// startMovie anchors the movie once the console is fully initialized.
+func (c *Console) startMovie() error {
+	for i, d := range c.Bus.Ports().Devices() {
+		switch d.(type) {
+		case controller.ButtonDevice, controller.Unplugged:
+		default:
+			return fmt.Errorf("%w: player %d has a %T", ErrMovieDevice, i+1, d)
+		}
+	}
+	_, fourScore := c.Bus.Ports().(*controller.FourScore)
+
+	logger := slog.With("file", filepath.Base(c.movie.path), "anchor", "power-on")
+	if c.movie.fromState {
+		logger = logger.With("anchor", "state")
+	}
+
+	switch c.movie.mode {
+	case movieRecord:
+		c.movie.movie.PAL = c.region == region.PAL
+		c.movie.movie.FourScore = fourScore
+		if c.movie.fromState {
+			var buf bytes.Buffer
+			if err := c.SaveState(&buf); err != nil {
+				return err
+			}
+			c.movie.movie.SaveState = buf.Bytes()
+		}
+		logger.Info("Recording movie")
+	case moviePlay:
+		if c.movie.movie.FourScore != fourScore {
+			return fmt.Errorf("%w: recorded %t, connected %t", ErrMovieFourScore, c.movie.movie.FourScore, fourScore)
+		}
+		if c.movie.movie.PAL != (c.region == region.PAL) {
+			slog.Warn("Movie was recorded with a different region", "pal", c.movie.movie.PAL, "region", c.region)
+		}
+		if c.movie.fromState {
+			if err := c.LoadState(bytes.NewReader(c.movie.movie.SaveState)); err != nil {
+				return err
+			}
+		}
+		logger.Info("Playing movie", "frames", len(c.movie.movie.Frames))
+	}
+	return nil
+}
+
+// stepMovie records or replays input for the next frame.
+func (c *Console) stepMovie() {
+	switch c.movie.mode {
+	case movieRecord:
+		frame := movie.Frame{Ports: c.Bus.Buttons()}
+		if c.movie.reset {
+			frame.Command |= movie.CommandSoftReset
+			c.movie.reset = false
+		}
+		c.movie.movie.Frames = append(c.movie.movie.Frames, frame)
+	case moviePlay:
+		if c.movie.frame >= len(c.movie.movie.Frames) {
+			if c.movie.frame == len(c.movie.movie.Frames) {
+				slog.Info("Movie playback finished")
+				c.movie.frame++
+			}
+			return
+		}
+
+		frame := c.movie.movie.Frames[c.movie.frame]
+		c.movie.frame++
+		if frame.Command&movie.CommandSoftReset != 0 {
+			c.Reset()
+		}
+		c.Bus.SetButtons(frame.Ports)
+	}
+}
+
+// closeMovie writes the recorded movie to disk.
+func (c *Console) closeMovie() error {
+	if c.movie.mode != movieRecord {
+		return nil
+	}
+
+	slog.Info("Writing movie", "file", filepath.Base(c.movie.path), "frames", len(c.movie.movie.Frames))
+
+	if err := os.MkdirAll(filepath.Dir(c.movie.path), 0o777); err != nil {
+		return err
+	}
+
+	f, err := os.Create(c.movie.path)
+	if err != nil {
+		return err
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+
+	if err := c.movie.movie.WriteFM2(f); err != nil {
+		return errors.Join(err, f.Close())
+	}
+
+	return f.Close()
+}
+
+// moviePlaying reports whether live input is currently being replaced by a movie.
+func (c *Console) moviePlaying() bool {
+	return c.movie != nil && c.movie.mode == moviePlay && c.movie.frame < len(c.movie.movie.Frames)
+}
@@ -0,0 +1,220 @@
+package console
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gabe565.com/gones/internal/cartridge"
+	"gabe565.com/gones/internal/region"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	// nsfDefaultLength is how long tracks play when neither the options nor the file set a length.
+	nsfDefaultLength = 3 * time.Minute
+	// nsfDefaultFade is how long tracks fade out when neither the options nor the file set a fade.
+	nsfDefaultFade = 5 * time.Second
+)
+
+var ErrNotNSF = errors.New("not an NSF file")
+
+// NSFOptions configures NSF playback.
+type NSFOptions struct {
+	// Track is the 1-based track to play first, or 0 for the file's default.
+	Track int
+	// Length is how long each track plays before fading out, or 0 to use the file's track lengths.
+	Length time.Duration
+	// Fade is how long each track fades out, or 0 to use the file's fade times.
+	Fade time.Duration
+}
+
+type nsfPlayer struct {
+	opts   NSFOptions
+	mapper *cartridge.MapperNSF
+
+	song   int
+	frame  int
+	length time.Duration
+	fade   time.Duration
+}
+
+// WithNSF plays an NSF file with custom options.
+// NSF files are played with the default options when this is not set.
+//
+// The player runs the file's init and play routines, automatically advancing to the next track
+// when the current one ends. Saves, resume states, rewind, and autosave are disabled.
+func WithNSF(opts NSFOptions) Option {
+	return func(c *Console) error {
+		if _, ok := c.Mapper.(*cartridge.MapperNSF); !ok {
+			return ErrNotNSF
+		}
+		c.nsf = &nsfPlayer{opts: opts}
+		return nil
+	}
+}
+
+// startNSF prepares the player once the console hardware exists.
+func (c *Console) startNSF() {
+	c.nsf.mapper = c.Mapper.(*cartridge.MapperNSF)
+	nsf := c.nsf.mapper.NSF()
+	song := nsf.StartSong
+	if c.nsf.opts.Track > 0 {
+		song = min(c.nsf.opts.Track, nsf.Songs) - 1
+	}
+	slog.Info("Loaded NSF", "title", nsf.Title, "artist", nsf.Artist, "copyright", nsf.Copyright, "tracks", nsf.Songs)
+	c.playTrack(song)
+}
+
+// playTrack resets the console and starts a 0-based track.
+func (c *Console) playTrack(song int) {
+	n := c.nsf
+	nsf := n.mapper.NSF()
+	n.song = (song + nsf.Songs) % nsf.Songs
+	n.frame = 0
+
+	n.length = n.opts.Length
+	if n.length == 0 {
+		n.length = nsf.Length(n.song)
+	}
+	if n.length <= 0 {
+		n.length = nsfDefaultLength
+	}
+	n.fade = n.opts.Fade
+	if n.fade == 0 {
+		n.fade = nsf.Fade(n.song)
+	}
+	if n.fade < 0 {
+		n.fade = nsfDefaultFade
+	}
+
+	// The init routine expects cleared RAM
+	clear(c.Bus.CPUVRAM[:])
+	pal := c.region == region.PAL || c.region == region.Dendy
+	n.mapper.Start(n.song, pal, c.region.CPUFrequency())
+	c.Reset()
+	c.APU.Volume = 1
+
+	slog.Info("Playing NSF track", "track", n.song+1, "label", nsf.Label(n.song), "length", n.length)
+}
+
+// elapsed returns how long the current track has played.
+func (n *nsfPlayer) elapsed(r region.Region) time.Duration {
+	return time.Duration(float64(n.frame) / r.FrameRate() * float64(time.Second))
+}
+
+// updateNSF advances the track timer, fading out and starting the next track when the current one ends.
+func (c *Console) updateNSF(frames int) {
+	n := c.nsf
+	n.frame += frames
+	elapsed := n.elapsed(c.region)
+	switch {
+	case elapsed >= n.length+n.fade:
+		c.playTrack(n.song + 1)
+	case elapsed > n.length:
+		c.APU.Volume = 1 - float32(elapsed-n.length)/float32(n.fade)
+	}
+}
+
+// checkNSFInput switches tracks with the player 1 left and right keys.
+func (c *Console) checkNSFInput() {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.Key(c.Config.Input.Player1.Left)):
+		c.playTrack(c.nsf.song - 1)
+	case inpututil.IsKeyJustPressed(ebiten.Key(c.Config.Input.Player1.Right)):
+		c.playTrack(c.nsf.song + 1)
+	}
+}
+
+//nolint:gochecknoglobals
+var (
+	nsfAPUChannels = [...]string{"Pulse 1", "Pulse 2", "Triangle", "Noise", "DMC"}
+
+	nsfExpansionChannels = [...]struct {
+		channel cartridge.AudioChannel
+		name    string
+	}{
+		{cartridge.ChannelMMC5Pulse1, "MMC5 1"},
+		{cartridge.ChannelMMC5Pulse2, "MMC5 2"},
+		{cartridge.ChannelMMC5PCM, "MMC5 PCM"},
+		{cartridge.ChannelVRC6Pulse1, "VRC6 1"},
+		{cartridge.ChannelVRC6Pulse2, "VRC6 2"},
+		{cartridge.ChannelVRC6Sawtooth, "VRC6 Saw"},
+		{cartridge.ChannelSunsoft5BA, "5B A"},
+		{cartridge.ChannelSunsoft5BB, "5B B"},
+		{cartridge.ChannelSunsoft5BC, "5B C"},
+		{cartridge.ChannelFDS, "FDS"},
+	}
+
+	nsfBarColor = color.RGBA{R: 0x3C, G: 0xBC, B: 0xFC, A: 0xFF}
+)
+
+const (
+	nsfTextLine    = 16
+	nsfChannelLine = 13
+	nsfBarX        = 72
+	nsfBarWidth    = 176
+)
+
+// drawNSF draws the track info and the activity of each audio channel.
+func (c *Console) drawNSF(screen *ebiten.Image) {
+	n := c.nsf
+	nsf := n.mapper.NSF()
+	screen.Fill(color.Black)
+
+	track := fmt.Sprintf("Track %d/%d", n.song+1, nsf.Songs)
+	if label := nsf.Label(n.song); label != "" {
+		track += " " + label
+	}
+	lines := []string{
+		nsf.Title,
+		nsf.Artist,
+		track,
+		formatTrackTime(n.elapsed(c.region)) + " / " + formatTrackTime(n.length),
+	}
+	for i, line := range lines {
+		ebitenutil.DebugPrintAt(screen, truncate(line, (screen.Bounds().Dx()-8)/6), 4, i*nsfTextLine)
+	}
+
+	y := len(lines)*nsfTextLine + 4
+	drawLevel := func(name string, level float32) {
+		ebitenutil.DebugPrintAt(screen, name, 4, y-2)
+		if level > 0 {
+			vector.DrawFilledRect(screen, nsfBarX, float32(y+3), min(level, 1)*nsfBarWidth, 8, nsfBarColor, false)
+		}
+		y += nsfChannelLine
+	}
+
+	for i, level := range c.APU.ChannelLevels() {
+		drawLevel(nsfAPUChannels[i], level)
+	}
+
+	channels := n.mapper.Channels()
+	for _, ch := range nsfExpansionChannels {
+		if channels&ch.channel != 0 {
+			mix := cartridge.NewAudioMix(ch.channel)
+			drawLevel(ch.name, n.mapper.Audio(&mix)/(15*cartridge.ExpansionGain))
+		}
+	}
+}
+
+func formatTrackTime(d time.Duration) string {
+	d = d.Truncate(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// truncate shortens s to n runes, ending with "..." if it was cut.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:max(n-3, 0)])) + "..."
+}
@@ -19,6 +19,17 @@ func (c *Console) SRAMPath() (string, error) {
 	return filepath.Join(sramDir, sramName), nil
 }
 
+// DiskPath returns the path of the IPS patch that stores writes to a Famicom Disk System disk.
+func (c *Console) DiskPath() (string, error) {
+	sramDir, err := config.GetSRAMDir()
+	if err != nil {
+		return "", err
+	}
+
+	diskName := c.Cartridge.Hash() + ".ips"
+	return filepath.Join(sramDir, diskName), nil
+}
+
 func (c *Console) StatePath(num uint8) (string, error) {
 	statesDir, err := config.GetStatesDir()
 	if err != nil {
@@ -28,3 +39,13 @@ func (c *Console) StatePath(num uint8) (string, error) {
 	stateName := fmt.Sprintf("%s.%d.state.gz", c.Cartridge.Hash(), num)
 	return filepath.Join(statesDir, stateName), nil
 }
+
+func (c *Console) CheatsPath() (string, error) {
+	gamesDir, err := config.GetGamesDir()
+	if err != nil {
+		return "", err
+	}
+
+	cheatsName := c.Cartridge.Hash() + ".cheats.toml"
+	return filepath.Join(gamesDir, cheatsName), nil
+}
@@ -8,6 +8,14 @@ func (c *Console) SRAMPath() (string, error) {
 	return fmt.Sprintf("%s.sav", c.Cartridge.Hash()), nil
 }
 
+func (c *Console) DiskPath() (string, error) {
+	return fmt.Sprintf("%s.ips", c.Cartridge.Hash()), nil
+}
+
 func (c *Console) StatePath(num uint8) (string, error) {
 	return fmt.Sprintf("%s.%d.state.gz", c.Cartridge.Hash(), num), nil
 }
+
+func (c *Console) CheatsPath() (string, error) {
+	return fmt.Sprintf("%s.cheats.toml", c.Cartridge.Hash()), nil
+}
@@ -0,0 +1,166 @@
+package console
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gabe565.com/gones/internal/config"
+	"gabe565.com/gones/internal/consts"
+	"gabe565.com/gones/internal/record"
+)
+
+type recording struct {
+	path     string
+	recorder *record.Recorder
+	// frame is the last PPU frame that was recorded
+	frame uint64
+}
+
+// WithRecording records video and audio from the first frame, to a Y4M file at path and a WAV file next to it.
+func WithRecording(path string) Option {
+	return func(c *Console) error {
+		c.recordPath = path
+		return nil
+	}
+}
+
+// StartRecording records video and audio to a Y4M file at path and a WAV file next to it.
+// The files are timed by the emulated frame count, so they stay in sync during fast-forward and lag.
+func (c *Console) StartRecording(path string) error {
+	if c.recording != nil {
+		if err := c.StopRecording(); err != nil {
+			return err
+		}
+	}
+
+	img := c.PPU.Image()
+	rec, err := record.New(path, img.Rect.Dx(), img.Rect.Dy(), c.region.TargetFrameRate(), consts.AudioSampleRate)
+	if err != nil {
+		return err
+	}
+
+	c.recording = &recording{
+		path:     path,
+		recorder: rec,
+		frame:    c.PPU.Frames(),
+	}
+	c.APU.AddRecorder(rec)
+	slog.Info("Recording", "video", path, "audio", record.AudioPath(path))
+	return nil
+}
+
+// StopRecording finishes the current recording.
+func (c *Console) StopRecording() error {
+	if c.recording == nil {
+		return nil
+	}
+
+	rec := c.recording
+	c.recording = nil
+	c.APU.RemoveRecorder(rec.recorder)
+	if err := rec.recorder.Close(); err != nil {
+		return err
+	}
+
+	slog.Info("Saved recording", "video", rec.path, "audio", record.AudioPath(rec.path), "frames", rec.recorder.Frames())
+	return nil
+}
+
+// Recording reports whether video and audio are being recorded.
+func (c *Console) Recording() bool {
+	return c.recording != nil
+}
+
+// toggleRecording starts a recording in the recordings directory, or stops the current one.
+func (c *Console) toggleRecording() error {
+	if c.Recording() {
+		return c.StopRecording()
+	}
+
+	dir, err := config.GetRecordingDir()
+	if err != nil {
+		return err
+	}
+
+	gameDir := filepath.Join(dir, c.Cartridge.Name())
+	if err := os.MkdirAll(gameDir, 0o777); err != nil {
+		return err
+	}
+
+	return c.StartRecording(filepath.Join(gameDir, time.Now().Format("2006-01-02_150405")+".y4m"))
+}
+
+// recordFrame writes the PPU's image once each frame is complete.
+func (c *Console) recordFrame() {
+	rec := c.recording
+	if frame := c.PPU.Frames(); frame != rec.frame {
+		rec.frame = frame
+		if err := rec.recorder.WriteFrame(c.PPU.Image()); err != nil {
+			slog.Error("Recording failed", "error", err)
+			if err := c.StopRecording(); err != nil {
+				slog.Error("Failed to save recording", "error", err)
+			}
+		}
+	}
+}
+
+// WithAudioDump dumps raw APU output from the first frame to WAV files in dir.
+func WithAudioDump(dir string) Option {
+	return func(c *Console) error {
+		c.dumpPath = dir
+		return nil
+	}
+}
+
+// StartAudioDump writes every APU sample to WAV files in dir.
+// Each channel is also written to its own file if stems are enabled in the config.
+func (c *Console) StartAudioDump(dir string) error {
+	if c.audioDump != nil {
+		if err := c.StopAudioDump(); err != nil {
+			return err
+		}
+	}
+
+	dump, err := record.NewAudioDump(dir, consts.AudioSampleRate, c.Config.Audio.Stems)
+	if err != nil {
+		return err
+	}
+
+	c.audioDump = dump
+	c.APU.AddRecorder(dump)
+	slog.Info("Dumping audio", "dir", dir, "stems", dump.Stems())
+	return nil
+}
+
+// StopAudioDump finishes the current audio dump.
+func (c *Console) StopAudioDump() error {
+	if c.audioDump == nil {
+		return nil
+	}
+
+	dump := c.audioDump
+	c.audioDump = nil
+	c.APU.RemoveRecorder(dump)
+	if err := dump.Close(); err != nil {
+		return err
+	}
+
+	slog.Info("Saved audio dump", "dir", dump.Dir(), "samples", dump.Samples())
+	return nil
+}
+
+// toggleAudioDump starts an audio dump in the recordings directory, or stops the current one.
+func (c *Console) toggleAudioDump() error {
+	if c.audioDump != nil {
+		return c.StopAudioDump()
+	}
+
+	dir, err := config.GetRecordingDir()
+	if err != nil {
+		return err
+	}
+
+	return c.StartAudioDump(filepath.Join(dir, c.Cartridge.Name(), time.Now().Format("2006-01-02_150405")+"_audio"))
+}
@@ -16,18 +16,21 @@ func (c *Console) SaveState(w io.Writer) error {
 		_ = gzw.Close()
 	}()
 
-	encoder := msgpack.NewEncoder(gzw)
-	encoder.UseCompactFloats(true)
-	encoder.UseCompactInts(true)
-	encoder.SetSortMapKeys(true)
-
-	if err := encoder.Encode(c); err != nil {
+	if err := c.encodeState(gzw); err != nil {
 		return err
 	}
 
 	return gzw.Close()
 }
 
+func (c *Console) encodeState(w io.Writer) error {
+	encoder := msgpack.NewEncoder(w)
+	encoder.UseCompactFloats(true)
+	encoder.UseCompactInts(true)
+	encoder.SetSortMapKeys(true)
+	return encoder.Encode(c)
+}
+
 func (c *Console) LoadState(r io.Reader) error {
 	gzr, err := gzip.NewReader(r)
 	if err != nil {
@@ -37,11 +40,15 @@ func (c *Console) LoadState(r io.Reader) error {
 		_ = gzr.Close()
 	}()
 
-	if err := msgpack.NewDecoder(gzr).Decode(c); err != nil {
+	if err := c.decodeState(gzr); err != nil {
 		return err
 	}
 
-	if err := gzr.Close(); err != nil {
+	return gzr.Close()
+}
+
+func (c *Console) decodeState(r io.Reader) error {
+	if err := msgpack.NewDecoder(r).Decode(c); err != nil {
 		return err
 	}
 
@@ -0,0 +1,40 @@
+package console
+
+import (
+	"image"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// screen is the video output that light guns and the Arkanoid Vaus are aimed at.
+type screen struct {
+	c *Console
+}
+
+func (s screen) Image() *image.RGBA {
+	return s.c.PPU.Image()
+}
+
+func (s screen) BeamPosition() image.Point {
+	return s.c.PPU.BeamPosition()
+}
+
+// CursorPosition returns the mouse cursor position, relative to the PPU image.
+// The NTSC filter's output is wider than the image, and shaders scale the image to fit the window,
+// so the cursor is scaled to match.
+func (s screen) CursorPosition() image.Point {
+	x, y := ebiten.CursorPosition()
+	if s.c.shaders != nil {
+		rect := s.c.shaders.Rect()
+		if rect.Empty() {
+			return image.Pt(-1, -1)
+		}
+		x = (x - rect.Min.X) * s.c.PPU.Width() / rect.Dx()
+		y = (y - rect.Min.Y) * s.c.PPU.Height() / rect.Dy()
+		return image.Pt(x, y)
+	}
+	if s.c.ntsc != nil {
+		x = x * s.c.PPU.Width() / s.c.ntsc.Image().Rect.Dx()
+	}
+	return image.Pt(x, y)
+}
@@ -10,6 +10,9 @@ import (
 )
 
 func (c *Console) SaveSRAM() error {
+	if c.Cartridge.Disk != nil {
+		return c.saveDisk()
+	}
 	if !c.Cartridge.Battery {
 		return nil
 	}
@@ -33,6 +36,9 @@ func (c *Console) SaveSRAM() error {
 }
 
 func (c *Console) LoadSRAM() error {
+	if c.Cartridge.Disk != nil {
+		return c.loadDisk()
+	}
 	if !c.Cartridge.Battery {
 		return nil
 	}
@@ -52,3 +58,44 @@ func (c *Console) LoadSRAM() error {
 	c.Cartridge.SRAM = sram
 	return nil
 }
+
+// saveDisk writes changes to a Famicom Disk System disk as an IPS patch.
+func (c *Console) saveDisk() error {
+	patch, err := c.Cartridge.Disk.Diff()
+	if err != nil || patch == nil {
+		return err
+	}
+
+	path, err := c.DiskPath()
+	if err != nil {
+		return err
+	}
+
+	slog.Debug("Writing disk changes to disk", "file", filepath.Base(path))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+		return err
+	}
+
+	if err := os.Rename(path, path+".bak"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return os.WriteFile(path, patch, 0o666)
+}
+
+func (c *Console) loadDisk() error {
+	path, err := c.DiskPath()
+	if err != nil {
+		return err
+	}
+
+	patch, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	slog.Debug("Loading disk changes from disk", "file", filepath.Base(path))
+
+	return c.Cartridge.Disk.Patch(patch)
+}
@@ -8,6 +8,9 @@ import (
 )
 
 func (c *Console) SaveSRAM() error {
+	if c.Cartridge.Disk != nil {
+		return c.saveDisk()
+	}
 	if !c.Cartridge.Battery {
 		return nil
 	}
@@ -26,6 +29,9 @@ func (c *Console) SaveSRAM() error {
 }
 
 func (c *Console) LoadSRAM() error {
+	if c.Cartridge.Disk != nil {
+		return c.loadDisk()
+	}
 	if !c.Cartridge.Battery {
 		return nil
 	}
@@ -53,3 +59,48 @@ func (c *Console) LoadSRAM() error {
 
 	return nil
 }
+
+// saveDisk writes changes to a Famicom Disk System disk as an IPS patch.
+func (c *Console) saveDisk() error {
+	patch, err := c.Cartridge.Disk.Diff()
+	if err != nil || patch == nil {
+		return err
+	}
+
+	path, err := c.DiskPath()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Writing disk changes to db", "file", filepath.Base(path))
+
+	data := base64.StdEncoding.EncodeToString(patch)
+
+	_, err = await(js.Global().Get("GonesClient").Call("dbPut", "saves", path, data))
+	return err
+}
+
+func (c *Console) loadDisk() error {
+	path, err := c.DiskPath()
+	if err != nil {
+		return err
+	}
+
+	vals, err := await(js.Global().Get("GonesClient").Call("dbGet", "saves", path))
+	if err != nil {
+		return err
+	}
+	data := vals[0]
+
+	if data.IsNull() {
+		return nil
+	}
+
+	slog.Info("Loading disk changes from db", "file", filepath.Base(path))
+
+	patch, err := base64.StdEncoding.DecodeString(data.String())
+	if err != nil {
+		return err
+	}
+	return c.Cartridge.Disk.Patch(patch)
+}
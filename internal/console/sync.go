@@ -0,0 +1,37 @@
+package console
+
+import (
+	"time"
+
+	"gabe565.com/gones/internal/apu"
+)
+
+const (
+	// maxRateDelta is the most that the sample rate is nudged by.
+	// A 0.5% change in pitch is too small for most people to hear.
+	maxRateDelta = 0.005
+	// rateSmoothing is the weight of each frame's buffer level.
+	// The level jumps whenever the audio player reads, so it is smoothed across several frames.
+	rateSmoothing = 0.05
+)
+
+// syncAudio nudges the APU's sample rate so the audio buffer stays near the latency target.
+//
+// Frames are presented at the monitor's pace and audio is played at the sound card's pace, and the two never
+// quite match. Without this, the buffer slowly fills until samples are dropped, or empties until the player
+// runs dry, and both are heard as pops.
+func (c *Console) syncAudio() {
+	target := time.Duration(c.Config.Audio.Latency)
+	if c.player == nil || !c.APU.Enabled || c.rate != 1 || target <= 0 {
+		return
+	}
+	// The buffer must have room above the target to absorb bursts
+	target = min(target, c.APU.BufferCapacity()*3/4)
+
+	c.audioBuffered += (c.APU.Buffered().Seconds() - c.audioBuffered) * rateSmoothing
+	diff := (c.audioBuffered - target.Seconds()) / target.Seconds()
+	diff = min(max(diff, -1), 1)
+
+	// More cycles per sample make fewer samples, which drains the buffer
+	c.APU.SampleRate = apu.DefaultSampleRate(c.region) * (1 + maxRateDelta*diff)
+}
@@ -0,0 +1,169 @@
+package console
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"gabe565.com/gones/internal/consts"
+	"gabe565.com/gones/internal/ppu"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	viewerGap       = 8
+	viewerTextLine  = 16
+	viewerSwatch    = 16
+	viewerSpriteCol = 8
+	viewerCell      = 33
+
+	// viewerPanelX is the left edge of the panel next to the nametables.
+	viewerPanelX = ppu.NametablesWidth + viewerGap
+	// viewerPanelWidth fits both pattern tables side by side.
+	viewerPanelWidth = 2*ppu.PatternTableSize + viewerGap
+
+	viewerPatternY = viewerTextLine
+	viewerPaletteY = viewerPatternY + ppu.PatternTableSize + viewerGap + viewerTextLine
+	viewerOAMY     = viewerPaletteY + 2*viewerSwatch + viewerGap + viewerTextLine
+	viewerInfoY    = viewerOAMY + consts.PPUOAMSize/4/viewerSpriteCol*viewerCell
+
+	viewerWidth  = viewerPanelX + viewerPanelWidth
+	viewerHeight = viewerInfoY + 2*viewerTextLine
+)
+
+//nolint:gochecknoglobals
+var (
+	viewerScrollColor = color.RGBA{0xFF, 0x40, 0x40, 0xFF}
+	viewerSelectColor = color.RGBA{0xFF, 0xFF, 0xFF, 0xFF}
+)
+
+// viewer shows the PPU's nametables, pattern tables, palette, and OAM in place of the game.
+type viewer struct {
+	// canvas holds the views at 1x, which are scaled when they are drawn.
+	// The nametables are at the top-left, with the pattern tables and then the sprites to their right.
+	canvas  *image.RGBA
+	image   *ebiten.Image
+	palette byte
+}
+
+func newViewer() *viewer {
+	rect := image.Rect(0, 0, ppu.NametablesWidth+2*ppu.PatternTableSize, ppu.NametablesHeight)
+	return &viewer{
+		canvas: image.NewRGBA(rect),
+		image:  ebiten.NewImage(rect.Dx(), rect.Dy()),
+	}
+}
+
+// Deallocate frees the viewer's image.
+func (v *viewer) Deallocate() {
+	v.image.Deallocate()
+}
+
+// NextPalette cycles the palette used to draw the pattern tables.
+func (v *viewer) NextPalette() {
+	v.palette = (v.palette + 1) % 8
+}
+
+func (v *viewer) Draw(screen *ebiten.Image, p *ppu.PPU) {
+	screen.Fill(color.Black)
+
+	p.DrawNametables(v.canvas, image.Point{})
+	for table := range 2 {
+		p.DrawPatternTable(v.canvas, image.Pt(ppu.NametablesWidth+table*ppu.PatternTableSize, 0), table, v.palette)
+	}
+	spriteOrigin := image.Pt(ppu.NametablesWidth, ppu.PatternTableSize)
+	for i := range consts.PPUOAMSize / 4 {
+		p.DrawSprite(v.canvas, spriteOrigin.Add(image.Pt(i%viewerSpriteCol*8, i/viewerSpriteCol*16)), i)
+	}
+	v.image.WritePixels(v.canvas.Pix)
+
+	// Nametables
+	nametables := image.Rect(0, 0, ppu.NametablesWidth, ppu.NametablesHeight)
+	v.drawImage(screen, nametables, image.Point{}, 1)
+	v.drawScroll(screen.SubImage(nametables).(*ebiten.Image), p.Scroll())
+
+	// Pattern tables
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Pattern tables (palette %d)", v.palette), viewerPanelX, 0)
+	for table := range 2 {
+		src := image.Rect(0, 0, ppu.PatternTableSize, ppu.PatternTableSize).
+			Add(image.Pt(ppu.NametablesWidth+table*ppu.PatternTableSize, 0))
+		v.drawImage(screen, src, image.Pt(viewerPanelX+table*(ppu.PatternTableSize+viewerGap), viewerPatternY), 1)
+	}
+
+	// Palette
+	ebitenutil.DebugPrintAt(screen, "Palette", viewerPanelX, viewerPaletteY-viewerTextLine)
+	for i := range len(p.Palette) {
+		x := float32(viewerPanelX + i%16*viewerSwatch)
+		y := float32(viewerPaletteY + i/16*viewerSwatch)
+		vector.DrawFilledRect(screen, x, y, viewerSwatch, viewerSwatch, p.PaletteColor(i), false)
+	}
+	vector.StrokeRect(screen,
+		float32(viewerPanelX+int(v.palette)%4*4*viewerSwatch), float32(viewerPaletteY+int(v.palette)/4*viewerSwatch),
+		4*viewerSwatch, viewerSwatch, 1, viewerSelectColor, false,
+	)
+
+	// OAM
+	ebitenutil.DebugPrintAt(screen, "OAM", viewerPanelX, viewerOAMY-viewerTextLine)
+	hovered := -1
+	cursor := image.Pt(ebiten.CursorPosition())
+	for i := range consts.PPUOAMSize / 4 {
+		cell := image.Rect(0, 0, viewerCell, viewerCell).
+			Add(image.Pt(viewerPanelX+i%viewerSpriteCol*viewerCell, viewerOAMY+i/viewerSpriteCol*viewerCell))
+		src := image.Rect(0, 0, 8, 16).Add(spriteOrigin.Add(image.Pt(i%viewerSpriteCol*8, i/viewerSpriteCol*16)))
+		if !p.Ctrl.SpriteHeight {
+			src.Max.Y -= 8
+		}
+		v.drawImage(screen, src, cell.Min.Add(image.Pt((viewerCell-2*src.Dx())/2, (viewerCell-2*src.Dy())/2)), 2)
+		if cursor.In(cell) {
+			hovered = i
+			vector.StrokeRect(screen, float32(cell.Min.X), float32(cell.Min.Y), viewerCell, viewerCell, 1, viewerSelectColor, false)
+		}
+	}
+
+	if hovered == -1 {
+		ebitenutil.DebugPrintAt(screen, "Hover over a sprite", viewerPanelX, viewerInfoY)
+		return
+	}
+	s := p.Sprite(hovered)
+	flip := ""
+	if s.FlipH {
+		flip += "H"
+	}
+	if s.FlipV {
+		flip += "V"
+	}
+	priority := "front"
+	if s.Behind {
+		priority = "behind"
+	}
+	ebitenutil.DebugPrintAt(screen,
+		fmt.Sprintf("#%02d X:%3d Y:%3d Tile:$%02X", hovered, s.X, s.Y, s.Tile),
+		viewerPanelX, viewerInfoY,
+	)
+	ebitenutil.DebugPrintAt(screen,
+		fmt.Sprintf("Attr:$%02X Pal:%d %s Flip:%s", s.Attributes, s.Palette, priority, flip),
+		viewerPanelX, viewerInfoY+viewerTextLine,
+	)
+}
+
+// drawImage draws part of the canvas to the screen at pt.
+func (v *viewer) drawImage(screen *ebiten.Image, src image.Rectangle, pt image.Point, scale float64) {
+	var op ebiten.DrawImageOptions
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(float64(pt.X), float64(pt.Y))
+	screen.DrawImage(v.image.SubImage(src).(*ebiten.Image), &op)
+}
+
+// drawScroll outlines the visible area of the nametables. It wraps around the edges like the PPU's scrolling.
+func (v *viewer) drawScroll(nametables *ebiten.Image, scroll image.Point) {
+	for _, dx := range []int{0, -ppu.NametablesWidth} {
+		for _, dy := range []int{0, -ppu.NametablesHeight} {
+			vector.StrokeRect(nametables,
+				float32(scroll.X+dx)+0.5, float32(scroll.Y+dy)+0.5, consts.Width-1, consts.Height-1,
+				1, viewerScrollColor, false,
+			)
+		}
+	}
+}
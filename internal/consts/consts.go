@@ -21,7 +21,6 @@ const (
 
 	TargetFrameRate   = 60
 	HardwareFrameRate = 60.0988118623484
-	FrameRateDiff     = TargetFrameRate / HardwareFrameRate
 
 	Width  = 256
 	Height = 240
@@ -11,11 +11,14 @@ type Player string
 const (
 	Player1 Player = "player1"
 	Player2 Player = "player2"
+	Player3 Player = "player3"
+	Player4 Player = "player4"
 )
 
-func NewController(conf *config.Config, player Player) Controller {
-	controller := Controller{
+func NewController(conf *config.Config, player Player, gamepads *Gamepads) *Controller {
+	controller := &Controller{
 		Keymap:         NewKeymap(conf, player),
+		gamepads:       gamepads,
 		turboDutyCycle: conf.Input.TurboDutyCycle,
 	}
 	if len(controller.Keymap.Regular) != 0 {
@@ -30,7 +33,8 @@ type Controller struct {
 	index   byte
 	buttons [8]bool
 
-	Keymap Keymap
+	Keymap   Keymap
+	gamepads *Gamepads
 
 	turboDutyCycle uint16
 	turbo          uint16
@@ -64,12 +68,23 @@ func (j *Controller) Read() byte {
 }
 
 func (j *Controller) UpdateInput() {
+	gamepad, hasGamepad := j.gamepads.Get(j.Keymap.GamepadDevice)
+	hasGamepad = hasGamepad && ebiten.IsStandardGamepadLayoutAvailable(gamepad)
+
 	var turboPressed bool
 	for button, key := range j.Keymap.Regular {
 		pressed := ebiten.IsKeyPressed(key)
+		if !pressed && hasGamepad {
+			pressed = isGamepadButtonPressed(gamepad, j.Keymap.Gamepad[button])
+		}
 		if !pressed {
 			turboKey, ok := j.Keymap.Turbo[button]
-			if ok && ebiten.IsKeyPressed(turboKey) {
+			turbo := ok && ebiten.IsKeyPressed(turboKey)
+			if !turbo && hasGamepad {
+				turboButton, ok := j.Keymap.GamepadTurbo[button]
+				turbo = ok && isGamepadButtonPressed(gamepad, turboButton)
+			}
+			if turbo {
 				turboPressed = true
 				j.buttons[button] = j.turbo < j.turboDutyCycle/2
 				continue
@@ -78,6 +93,15 @@ func (j *Controller) UpdateInput() {
 		j.buttons[button] = pressed
 	}
 
+	if hasGamepad && j.Keymap.GamepadLeftStick {
+		x := ebiten.StandardGamepadAxisValue(gamepad, ebiten.StandardGamepadAxisLeftStickHorizontal)
+		y := ebiten.StandardGamepadAxisValue(gamepad, ebiten.StandardGamepadAxisLeftStickVertical)
+		j.buttons[button.Left] = j.buttons[button.Left] || x <= -stickThreshold
+		j.buttons[button.Right] = j.buttons[button.Right] || x >= stickThreshold
+		j.buttons[button.Up] = j.buttons[button.Up] || y <= -stickThreshold
+		j.buttons[button.Down] = j.buttons[button.Down] || y >= stickThreshold
+	}
+
 	// Directional safety
 	if j.buttons[button.Left] && j.buttons[button.Right] {
 		j.buttons[button.Right] = false
@@ -96,3 +120,25 @@ func (j *Controller) UpdateInput() {
 		j.turbo = 0
 	}
 }
+
+func isGamepadButtonPressed(id ebiten.GamepadID, b ebiten.StandardGamepadButton) bool {
+	return b >= 0 && ebiten.IsStandardGamepadButtonPressed(id, b)
+}
+
+// Buttons returns the pressed buttons as a bitmask in the order they are read by the console.
+func (j *Controller) Buttons() byte {
+	var v byte
+	for i, pressed := range j.buttons {
+		if pressed {
+			v |= 1 << i
+		}
+	}
+	return v
+}
+
+// SetButtons sets the pressed buttons from a bitmask in the order they are read by the console.
+func (j *Controller) SetButtons(v byte) {
+	for i := range j.buttons {
+		j.buttons[i] = v>>i&1 == 1
+	}
+}
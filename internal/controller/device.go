@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"log/slog"
+
+	"gabe565.com/gones/internal/config"
+	"gabe565.com/gones/internal/controller/device"
+)
+
+// Device is an input device plugged into a controller port.
+type Device interface {
+	// Read returns the data lines read from the port's register. Bit 0 is D0.
+	Read() byte
+	// Write is called for writes to $4016. Bit 0 is the strobe.
+	Write(data byte)
+	// UpdateInput polls the host's input devices. It is called once per frame.
+	UpdateInput()
+}
+
+// ButtonDevice is implemented by devices with standard controller buttons, which can be recorded to movies.
+type ButtonDevice interface {
+	// Buttons returns the pressed buttons as a bitmask in the order they are read by the console.
+	Buttons() byte
+	// SetButtons sets the pressed buttons from a bitmask in the order they are read by the console.
+	SetButtons(v byte)
+}
+
+// NewDevice creates the device plugged into a player's port.
+func NewDevice(conf *config.Config, player Player, t device.Type, gamepads *Gamepads, screen Screen) Device { //nolint:ireturn
+	slog.Debug("Connecting input device", "player", player, "device", t)
+	switch t {
+	case device.Zapper:
+		return NewZapper(conf, screen)
+	case device.Vaus:
+		return NewVaus(conf, player, gamepads, screen)
+	case device.None:
+		return Unplugged{}
+	default:
+		return NewController(conf, player, gamepads)
+	}
+}
+
+// Unplugged is an empty controller port. Reads return 0.
+type Unplugged struct{}
+
+func (Unplugged) Read() byte { return 0 }
+
+func (Unplugged) Write(byte) {}
+
+func (Unplugged) UpdateInput() {}
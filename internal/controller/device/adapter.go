@@ -0,0 +1,61 @@
+package device
+
+import (
+	"fmt"
+	"strings"
+
+	"gabe565.com/gones/internal/cartridge"
+)
+
+//go:generate go tool stringer -type Adapter -linecomment
+
+// Adapter is a multitap that connects four controllers to the console.
+type Adapter uint8
+
+const (
+	AdapterAuto      Adapter = iota // auto
+	AdapterNone                     // none
+	AdapterFourScore                // four_score
+	AdapterHori                     // hori
+)
+
+func AdapterValues() []Adapter {
+	return []Adapter{AdapterAuto, AdapterNone, AdapterFourScore, AdapterHori}
+}
+
+func AdapterStrings() []string {
+	values := AdapterValues()
+	s := make([]string, 0, len(values))
+	for _, v := range values {
+		s = append(s, v.String())
+	}
+	return s
+}
+
+func (a Adapter) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+func (a *Adapter) UnmarshalText(text []byte) error {
+	for _, v := range AdapterValues() {
+		if strings.EqualFold(string(text), v.String()) {
+			*a = v
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrInvalid, text)
+}
+
+// Resolve returns a, or the adapter declared by a NES 2.0 header when a is [AdapterAuto].
+func (a Adapter) Resolve(cart *cartridge.Cartridge) Adapter {
+	if a != AdapterAuto {
+		return a
+	}
+	switch cart.Header.ExpansionDevice() {
+	case cartridge.ExpansionFourScore:
+		return AdapterFourScore
+	case cartridge.ExpansionFourPlayers:
+		return AdapterHori
+	}
+	return AdapterNone
+}
@@ -0,0 +1,77 @@
+package device
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gabe565.com/gones/internal/cartridge"
+)
+
+//go:generate go tool stringer -type Type -linecomment
+
+// Type is an input device that can be plugged into a controller port.
+type Type uint8
+
+const (
+	Auto        Type = iota // auto
+	Controller              // controller
+	Zapper                  // zapper
+	None                    // none
+	Vaus                    // vaus
+	VausFamicom             // vaus_famicom
+)
+
+var ErrInvalid = errors.New("invalid device")
+
+func Values() []Type {
+	return []Type{Auto, Controller, Zapper, None, Vaus, VausFamicom}
+}
+
+func Strings() []string {
+	values := Values()
+	s := make([]string, 0, len(values))
+	for _, v := range values {
+		s = append(s, v.String())
+	}
+	return s
+}
+
+func (t Type) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *Type) UnmarshalText(text []byte) error {
+	for _, v := range Values() {
+		if strings.EqualFold(string(text), v.String()) {
+			*t = v
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrInvalid, text)
+}
+
+// Resolve returns t, or the device for a port declared by a NES 2.0 header when t is [Auto].
+// Port is 1 or 2. Standard controllers are used when the header does not declare a supported device.
+func (t Type) Resolve(cart *cartridge.Cartridge, port int) Type {
+	if t != Auto {
+		return t
+	}
+	switch cart.Header.ExpansionDevice() {
+	case cartridge.ExpansionZapper:
+		if port == 2 {
+			return Zapper
+		}
+	case cartridge.ExpansionDoubleZapper:
+		return Zapper
+	case cartridge.ExpansionArkanoidNES:
+		if port == 2 {
+			return Vaus
+		}
+	case cartridge.ExpansionArkanoidFamicom:
+		if port == 2 {
+			return VausFamicom
+		}
+	}
+	return Controller
+}
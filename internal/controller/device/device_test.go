@@ -0,0 +1,70 @@
+package device
+
+import (
+	"testing"
+
+	"gabe565.com/gones/internal/cartridge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestType_Resolve(t *testing.T) {
+	t.Parallel()
+	cart := cartridge.New()
+	assert.Equal(t, Controller, Auto.Resolve(cart, 2))
+
+	cart.Header.SetNESv2(true)
+	cart.Header.SetExpansionDevice(cartridge.ExpansionZapper)
+	assert.Equal(t, Controller, Auto.Resolve(cart, 1))
+	assert.Equal(t, Zapper, Auto.Resolve(cart, 2))
+	assert.Equal(t, None, None.Resolve(cart, 2))
+
+	cart.Header.SetExpansionDevice(cartridge.ExpansionDoubleZapper)
+	assert.Equal(t, Zapper, Auto.Resolve(cart, 1))
+
+	cart.Header.SetExpansionDevice(cartridge.ExpansionArkanoidNES)
+	assert.Equal(t, Controller, Auto.Resolve(cart, 1))
+	assert.Equal(t, Vaus, Auto.Resolve(cart, 2))
+
+	cart.Header.SetExpansionDevice(cartridge.ExpansionArkanoidFamicom)
+	assert.Equal(t, VausFamicom, Auto.Resolve(cart, 2))
+}
+
+func TestType_UnmarshalText(t *testing.T) {
+	t.Parallel()
+	var d Type
+	require.NoError(t, d.UnmarshalText([]byte("Zapper")))
+	assert.Equal(t, Zapper, d)
+
+	require.NoError(t, d.UnmarshalText([]byte("vaus_famicom")))
+	assert.Equal(t, VausFamicom, d)
+
+	require.ErrorIs(t, d.UnmarshalText([]byte("power_glove")), ErrInvalid)
+
+	text, err := None.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "none", string(text))
+}
+
+func TestAdapter_Resolve(t *testing.T) {
+	t.Parallel()
+	cart := cartridge.New()
+	assert.Equal(t, AdapterNone, AdapterAuto.Resolve(cart))
+
+	cart.Header.SetNESv2(true)
+	cart.Header.SetExpansionDevice(cartridge.ExpansionFourScore)
+	assert.Equal(t, AdapterFourScore, AdapterAuto.Resolve(cart))
+	assert.Equal(t, AdapterNone, AdapterNone.Resolve(cart))
+
+	cart.Header.SetExpansionDevice(cartridge.ExpansionFourPlayers)
+	assert.Equal(t, AdapterHori, AdapterAuto.Resolve(cart))
+}
+
+func TestAdapter_UnmarshalText(t *testing.T) {
+	t.Parallel()
+	var a Adapter
+	require.NoError(t, a.UnmarshalText([]byte("Four_Score")))
+	assert.Equal(t, AdapterFourScore, a)
+
+	require.ErrorIs(t, a.UnmarshalText([]byte("satellite")), ErrInvalid)
+}
@@ -0,0 +1,93 @@
+package controller
+
+import "gabe565.com/gones/internal/config"
+
+const fourScoreReads = 24
+
+//nolint:gochecknoglobals
+var (
+	// fourScoreSignature is read from $4016 and $4017 after both controllers on the NES Four Score.
+	// Bits are in read order, so $4016 reads 0, 0, 0, 1, 0, 0, 0, 0.
+	fourScoreSignature = [2]byte{0x08, 0x04}
+	// horiSignature is read from $4016 and $4017 after the controller on the Hori 4 Players Adapter.
+	horiSignature = [2]byte{0x04, 0x08}
+)
+
+// NewFourScore creates a four player adapter.
+// If hori is true, it acts like the Famicom Hori 4 Players Adapter instead of the NES Four Score.
+func NewFourScore(conf *config.Config, gamepads *Gamepads, hori bool) *FourScore {
+	return &FourScore{
+		Players: [4]*Controller{
+			NewController(conf, Player1, gamepads),
+			NewController(conf, Player2, gamepads),
+			NewController(conf, Player3, gamepads),
+			NewController(conf, Player4, gamepads),
+		},
+		Hori: hori,
+	}
+}
+
+// FourScore is a four player adapter.
+//
+// On the NES Four Score, each port reads 24 bits on D0: controller 1 or 2,
+// then controller 3 or 4, then a signature.
+//
+// On the Hori 4 Players Adapter, controllers 1 and 2 are read from D0 like standard Famicom controllers.
+// Controllers 3 and 4 are read from D1 of $4016 and $4017, followed by 8 empty bits and a signature.
+type FourScore struct {
+	Players [4]*Controller
+	Hori    bool
+
+	strobe bool
+	index  [2]byte
+}
+
+func (f *FourScore) Read(port int) byte {
+	var data byte = 1
+	if i := f.index[port]; i < fourScoreReads {
+		data = byte(f.serial(port)>>i) & 1
+		if !f.strobe {
+			f.index[port]++
+		}
+	}
+
+	if f.Hori {
+		return f.Players[port].Read() | data<<1
+	}
+	return data
+}
+
+// serial returns the bits read from a port in read order.
+func (f *FourScore) serial(port int) uint32 {
+	if f.Hori {
+		return uint32(f.Players[port+2].Buttons()) | uint32(horiSignature[port])<<16
+	}
+	return uint32(f.Players[port].Buttons()) |
+		uint32(f.Players[port+2].Buttons())<<8 |
+		uint32(fourScoreSignature[port])<<16
+}
+
+func (f *FourScore) Write(data byte) {
+	f.strobe = data&1 == 1
+	if f.strobe {
+		f.index = [2]byte{}
+	}
+	if f.Hori {
+		f.Players[0].Write(data)
+		f.Players[1].Write(data)
+	}
+}
+
+func (f *FourScore) UpdateInput() {
+	for _, p := range f.Players {
+		p.UpdateInput()
+	}
+}
+
+func (f *FourScore) Devices() []Device {
+	devices := make([]Device, 0, len(f.Players))
+	for _, p := range f.Players {
+		devices = append(devices, p)
+	}
+	return devices
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"testing"
+
+	"gabe565.com/gones/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// readPort returns the bits read from a port after strobing, in read order.
+func readPort(f *FourScore, port, n int) uint32 {
+	f.Write(1)
+	f.Write(0)
+	var v uint32
+	for i := range n {
+		v |= uint32(f.Read(port)) << i
+	}
+	return v
+}
+
+func TestFourScore_Read(t *testing.T) {
+	t.Parallel()
+
+	f := NewFourScore(config.NewDefault(), &Gamepads{}, false)
+	for i, p := range f.Players {
+		p.SetButtons(byte(0x11 << i))
+	}
+
+	assert.EqualValues(t, 0x08_4411, readPort(f, 0, fourScoreReads))
+	assert.EqualValues(t, 0x04_8822, readPort(f, 1, fourScoreReads))
+	assert.EqualValues(t, 1, f.Read(0), "reads after the signature return 1")
+
+	f.Write(1)
+	assert.EqualValues(t, 1, f.Read(0))
+	assert.EqualValues(t, 1, f.Read(0), "strobe holds the first button")
+}
+
+func TestFourScore_ReadHori(t *testing.T) {
+	t.Parallel()
+
+	f := NewFourScore(config.NewDefault(), &Gamepads{}, true)
+	for i, p := range f.Players {
+		p.SetButtons(byte(0x11 << i))
+	}
+
+	f.Write(1)
+	f.Write(0)
+	var d0, d1 uint32
+	for i := range fourScoreReads {
+		v := f.Read(0)
+		d0 |= uint32(v&1) << i
+		d1 |= uint32(v>>1&1) << i
+	}
+	assert.EqualValues(t, 0xFF_FF11, d0, "controller 1 is read from D0")
+	assert.EqualValues(t, 0x04_0044, d1, "controller 3 is read from D1")
+}
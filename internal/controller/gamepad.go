@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// stickThreshold is how far the analog stick must be pushed before it counts as a D-pad press.
+const stickThreshold = 0.5
+
+// noGamepad marks a device index whose gamepad was disconnected.
+const noGamepad ebiten.GamepadID = -1
+
+// Gamepads tracks connected gamepads in the order they were connected,
+// so that each player's device index stays stable while other gamepads are plugged in or removed.
+// When a gamepad is removed, its device index is left empty until another gamepad is connected.
+type Gamepads struct {
+	ids  []ebiten.GamepadID
+	scan []ebiten.GamepadID
+}
+
+// Update detects gamepads that were connected or disconnected since the last update.
+func (g *Gamepads) Update() {
+	g.scan = ebiten.AppendGamepadIDs(g.scan[:0])
+	g.update(g.scan)
+}
+
+// update assigns device indexes to the currently connected gamepads.
+func (g *Gamepads) update(connected []ebiten.GamepadID) {
+	for device, id := range g.ids {
+		if id != noGamepad && !slices.Contains(connected, id) {
+			slog.Info("Gamepad disconnected", "id", id, "device", device)
+			g.ids[device] = noGamepad
+		}
+	}
+
+	for _, id := range connected {
+		if slices.Contains(g.ids, id) {
+			continue
+		}
+
+		// Fill the first empty device index, so a replaced gamepad takes over its player
+		device := slices.Index(g.ids, noGamepad)
+		if device == -1 {
+			device = len(g.ids)
+			g.ids = append(g.ids, id)
+		} else {
+			g.ids[device] = id
+		}
+
+		logger := slog.With("id", id, "name", ebiten.GamepadName(id), "device", device)
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			logger.Info("Gamepad connected")
+		} else {
+			logger.Warn("Gamepad connected without a standard layout mapping. It will be ignored.")
+		}
+	}
+}
+
+// Get returns the gamepad at the given device index.
+// It returns false if no gamepad is connected at that index.
+func (g *Gamepads) Get(device int) (ebiten.GamepadID, bool) {
+	if device < 0 || device >= len(g.ids) || g.ids[device] == noGamepad {
+		return 0, false
+	}
+	return g.ids[device], true
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGamepads(t *testing.T) {
+	t.Parallel()
+
+	assertDevices := func(t *testing.T, g *Gamepads, want ...ebiten.GamepadID) {
+		t.Helper()
+		for device, id := range want {
+			got, ok := g.Get(device)
+			if id == noGamepad {
+				assert.False(t, ok, "device %d", device)
+			} else if assert.True(t, ok, "device %d", device) {
+				assert.Equal(t, id, got, "device %d", device)
+			}
+		}
+		_, ok := g.Get(len(want))
+		assert.False(t, ok, "device %d", len(want))
+	}
+
+	var g Gamepads
+	assertDevices(t, &g)
+
+	// Connect in order
+	g.update([]ebiten.GamepadID{5, 6, 7})
+	assertDevices(t, &g, 5, 6, 7)
+
+	// Disconnecting a gamepad leaves its device empty, and the others keep their devices
+	g.update([]ebiten.GamepadID{6, 7})
+	assertDevices(t, &g, noGamepad, 6, 7)
+	g.update([]ebiten.GamepadID{7})
+	assertDevices(t, &g, noGamepad, noGamepad, 7)
+
+	// Reconnected gamepads fill the first empty device
+	g.update([]ebiten.GamepadID{7, 8})
+	assertDevices(t, &g, 8, noGamepad, 7)
+	g.update([]ebiten.GamepadID{7, 8, 9, 10})
+	assertDevices(t, &g, 8, 9, 7, 10)
+
+	// Disconnect everything
+	g.update(nil)
+	assertDevices(t, &g, noGamepad, noGamepad, noGamepad, noGamepad)
+	_, ok := g.Get(-1)
+	assert.False(t, ok)
+}
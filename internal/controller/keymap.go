@@ -9,6 +9,11 @@ import (
 type Keymap struct {
 	Regular map[button.Button]ebiten.Key
 	Turbo   map[button.Button]ebiten.Key
+
+	GamepadDevice    int
+	GamepadLeftStick bool
+	Gamepad          map[button.Button]ebiten.StandardGamepadButton
+	GamepadTurbo     map[button.Button]ebiten.StandardGamepadButton
 }
 
 func NewKeymap(conf *config.Config, player Player) Keymap {
@@ -18,6 +23,10 @@ func NewKeymap(conf *config.Config, player Player) Keymap {
 		keymap = conf.Input.Player1
 	case Player2:
 		keymap = conf.Input.Player2
+	case Player3:
+		keymap = conf.Input.Player3
+	case Player4:
+		keymap = conf.Input.Player4
 	default:
 		panic("invalid player: " + player)
 	}
@@ -25,12 +34,19 @@ func NewKeymap(conf *config.Config, player Player) Keymap {
 	return Keymap{
 		Regular: keymap.GetMap(),
 		Turbo:   keymap.GetTurboMap(),
+
+		GamepadDevice:    keymap.Gamepad.Device,
+		GamepadLeftStick: keymap.Gamepad.LeftStick,
+		Gamepad:          keymap.Gamepad.GetMap(),
+		GamepadTurbo:     keymap.Gamepad.GetTurboMap(),
 	}
 }
 
 const (
-	ToggleTrace = ebiten.KeyTab
-	ToggleDebug = ebiten.KeyGraveAccent
-	StepFrame   = ebiten.Key1
-	RunToRender = ebiten.Key2
+	ToggleTrace   = ebiten.KeyTab
+	ToggleDebug   = ebiten.KeyGraveAccent
+	StepFrame     = ebiten.Key1
+	RunToRender   = ebiten.Key2
+	ToggleViewer  = ebiten.Key3
+	ViewerPalette = ebiten.Key4
 )
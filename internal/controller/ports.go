@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"log/slog"
+
+	"gabe565.com/gones/internal/cartridge"
+	"gabe565.com/gones/internal/config"
+	"gabe565.com/gones/internal/controller/device"
+)
+
+// Ports connects input devices to the $4016 and $4017 registers.
+type Ports interface {
+	// Read returns the data lines read from $4016 (port 0) or $4017 (port 1). Bit 0 is D0.
+	Read(port int) byte
+	// Write is called for writes to $4016. Bit 0 is the strobe.
+	Write(data byte)
+	// UpdateInput polls the host's input devices. It is called once per frame.
+	UpdateInput()
+	// Devices returns the connected devices in player order.
+	Devices() []Device
+}
+
+// NewPorts connects the devices configured for a cartridge.
+func NewPorts(conf *config.Config, cart *cartridge.Cartridge, gamepads *Gamepads, screen Screen) Ports { //nolint:ireturn
+	if adapter := conf.Input.FourPlayer.Resolve(cart); adapter != device.AdapterNone {
+		slog.Debug("Connecting four player adapter", "adapter", adapter)
+		return NewFourScore(conf, gamepads, adapter == device.AdapterHori)
+	}
+
+	types := [2]device.Type{conf.Input.Port1.Resolve(cart, 1), conf.Input.Port2.Resolve(cart, 2)}
+	players := [2]Player{Player1, Player2}
+	var vaus *Vaus
+	for i, t := range types {
+		if t == device.VausFamicom {
+			// The Famicom Vaus uses the expansion port, so the port keeps its controller
+			slog.Debug("Connecting expansion device", "device", t)
+			vaus = NewVaus(conf, players[i], gamepads, screen)
+			types[i] = device.Controller
+		}
+	}
+
+	ports := &StandardPorts{
+		NewDevice(conf, Player1, types[0], gamepads, screen),
+		NewDevice(conf, Player2, types[1], gamepads, screen),
+	}
+	if vaus != nil {
+		return &FamicomVaus{Ports: ports, Vaus: vaus}
+	}
+	return ports
+}
+
+// StandardPorts connects one device directly to each port.
+type StandardPorts [2]Device
+
+func (s *StandardPorts) Read(port int) byte {
+	return s[port].Read()
+}
+
+func (s *StandardPorts) Write(data byte) {
+	for _, d := range s {
+		d.Write(data)
+	}
+}
+
+func (s *StandardPorts) UpdateInput() {
+	for _, d := range s {
+		d.UpdateInput()
+	}
+}
+
+func (s *StandardPorts) Devices() []Device {
+	return s[:]
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"gabe565.com/gones/internal/config"
+	"gabe565.com/gones/internal/controller/button"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// VausMin and VausMax are the potentiometer values at each end of the knob's range.
+	VausMin = 0x62
+	VausMax = 0xF2
+	// vausStickSpeed is how far the knob turns per frame when the analog stick is fully pushed.
+	vausStickSpeed = 4
+)
+
+func NewVaus(conf *config.Config, player Player, gamepads *Gamepads, screen Screen) *Vaus {
+	keymap := NewKeymap(conf, player)
+	return &Vaus{
+		Position:      (VausMin + VausMax) / 2,
+		fireButton:    ebiten.MouseButton(conf.Input.VausFire),
+		screen:        screen,
+		gamepads:      gamepads,
+		gamepadDevice: keymap.GamepadDevice,
+		gamepadFire:   keymap.Gamepad[button.A],
+	}
+}
+
+// Vaus is the Arkanoid paddle controller. The knob is turned by moving the mouse or the left analog stick,
+// and the fire button is pressed with a mouse button or the gamepad's A button.
+//
+// The NES version is read from D3 (fire) and D4 (knob) of its port.
+// The knob's potentiometer is latched by the strobe, then shifted out inverted, most significant bit first.
+type Vaus struct {
+	Position byte
+	Fire     bool
+
+	strobe bool
+	shift  byte
+
+	fireButton    ebiten.MouseButton
+	screen        Screen
+	gamepads      *Gamepads
+	gamepadDevice int
+	gamepadFire   ebiten.StandardGamepadButton
+	cursorX       int
+	hasCursor     bool
+}
+
+func (v *Vaus) Read() byte {
+	var data byte
+	if v.Fire {
+		data |= 0x08
+	}
+	return data | v.next()<<4
+}
+
+// next returns the next bit of the potentiometer.
+func (v *Vaus) next() byte {
+	data := v.shift >> 7
+	if !v.strobe {
+		v.shift <<= 1
+	}
+	return data
+}
+
+func (v *Vaus) Write(data byte) {
+	v.strobe = data&1 == 1
+	if v.strobe {
+		v.shift = ^v.Position
+	}
+}
+
+func (v *Vaus) UpdateInput() {
+	x := v.screen.CursorPosition().X
+	if v.hasCursor {
+		v.Turn(x - v.cursorX)
+	}
+	v.cursorX, v.hasCursor = x, true
+	v.Fire = ebiten.IsMouseButtonPressed(v.fireButton)
+
+	if gamepad, ok := v.gamepads.Get(v.gamepadDevice); ok && ebiten.IsStandardGamepadLayoutAvailable(gamepad) {
+		axis := ebiten.StandardGamepadAxisValue(gamepad, ebiten.StandardGamepadAxisLeftStickHorizontal)
+		v.Turn(int(axis * vausStickSpeed))
+		v.Fire = v.Fire || isGamepadButtonPressed(gamepad, v.gamepadFire)
+	}
+}
+
+// Turn moves the knob, clamping it to the potentiometer's range.
+func (v *Vaus) Turn(delta int) {
+	v.Position = byte(min(max(int(v.Position)+delta, VausMin), VausMax))
+}
+
+// FamicomVaus is the Famicom version of the Arkanoid paddle controller.
+// It is plugged into the expansion port, so the controllers in ports 1 and 2 are still read from D0.
+// Fire is read from D1 of $4016, and the knob from D1 of $4017.
+type FamicomVaus struct {
+	Ports
+	Vaus *Vaus
+}
+
+func (f *FamicomVaus) Read(port int) byte {
+	data := f.Ports.Read(port)
+	switch port {
+	case 0:
+		if f.Vaus.Fire {
+			data |= 0x02
+		}
+	case 1:
+		data |= f.Vaus.next() << 1
+	}
+	return data
+}
+
+func (f *FamicomVaus) Write(data byte) {
+	f.Ports.Write(data)
+	f.Vaus.Write(data)
+}
+
+func (f *FamicomVaus) UpdateInput() {
+	f.Ports.UpdateInput()
+	f.Vaus.UpdateInput()
+}
+
+func (f *FamicomVaus) Devices() []Device {
+	return append(f.Ports.Devices(), f.Vaus)
+}
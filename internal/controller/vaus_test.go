@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVaus_Read(t *testing.T) {
+	t.Parallel()
+
+	v := &Vaus{Position: 0xA5, Fire: true}
+	v.Write(1)
+	v.Write(0)
+	var knob byte
+	for range 8 {
+		data := v.Read()
+		assert.EqualValues(t, 0x08, data&0x08, "fire is read from D3")
+		knob = knob<<1 | data>>4&1
+	}
+	assert.EqualValues(t, 0xA5, ^knob, "the knob is read inverted, most significant bit first")
+
+	v.Turn(-0xFF)
+	assert.EqualValues(t, VausMin, v.Position)
+	v.Turn(0xFF)
+	assert.EqualValues(t, VausMax, v.Position)
+}
+
+func TestFamicomVaus_Read(t *testing.T) {
+	t.Parallel()
+
+	f := &FamicomVaus{
+		Ports: &StandardPorts{Unplugged{}, Unplugged{}},
+		Vaus:  &Vaus{Position: 0xA5, Fire: true},
+	}
+	f.Write(1)
+	f.Write(0)
+	assert.EqualValues(t, 0x02, f.Read(0), "fire is read from D1 of $4016")
+	var knob byte
+	for range 8 {
+		knob = knob<<1 | f.Read(1)>>1&1
+	}
+	assert.EqualValues(t, 0xA5, ^knob, "the knob is read from D1 of $4017")
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"image"
+	"image/color"
+
+	"gabe565.com/gones/internal/config"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// zapperRadius is how far from the cursor, in pixels, the Zapper senses light.
+	zapperRadius = 2
+	// zapperHoldLines is how many scanlines the light sensor stays on after the beam draws a bright pixel.
+	zapperHoldLines = 20
+	// zapperThreshold is the minimum luma of a pixel that the light sensor detects.
+	zapperThreshold = 0xC0
+)
+
+// Screen is the video output that the Zapper is aimed at.
+type Screen interface {
+	// Image returns the frame being drawn.
+	Image() *image.RGBA
+	// BeamPosition returns the pixel being drawn, relative to the image.
+	BeamPosition() image.Point
+	// CursorPosition returns the mouse cursor position, relative to the image.
+	CursorPosition() image.Point
+}
+
+func NewZapper(conf *config.Config, screen Screen) *Zapper {
+	return &Zapper{
+		screen:  screen,
+		trigger: ebiten.MouseButton(conf.Input.ZapperTrigger),
+	}
+}
+
+// Zapper is the NES light gun. It is aimed with the mouse cursor, and fired with a mouse button.
+//
+// The light sensor reads the framebuffer near the cursor, but only for pixels that the beam has drawn recently,
+// so games see light at the same point in the frame as they would on a CRT.
+type Zapper struct {
+	screen  Screen
+	trigger ebiten.MouseButton
+
+	// Aim is the cursor position relative to the image
+	Aim      image.Point
+	OnScreen bool
+	Trigger  bool
+}
+
+func (z *Zapper) Read() byte {
+	var data byte
+	if !z.Light() {
+		data |= 0x08
+	}
+	if z.Trigger {
+		data |= 0x10
+	}
+	return data
+}
+
+func (z *Zapper) Write(byte) {}
+
+func (z *Zapper) UpdateInput() {
+	z.Aim = z.screen.CursorPosition()
+	z.OnScreen = z.Aim.In(z.screen.Image().Bounds())
+	z.Trigger = ebiten.IsMouseButtonPressed(z.trigger)
+}
+
+// Light reports whether the light sensor sees a bright pixel that was recently drawn.
+func (z *Zapper) Light() bool {
+	if !z.OnScreen {
+		return false
+	}
+
+	img := z.screen.Image()
+	beam := z.screen.BeamPosition()
+	area := image.Rect(-zapperRadius, -zapperRadius, zapperRadius+1, zapperRadius+1).
+		Add(z.Aim).
+		Intersect(img.Bounds())
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		lines := beam.Y - y
+		if lines < 0 || lines >= zapperHoldLines {
+			continue
+		}
+		for x := area.Min.X; x < area.Max.X; x++ {
+			if lines == 0 && beam.X < x {
+				break
+			}
+			if luma(img.RGBAAt(x, y)) >= zapperThreshold {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func luma(c color.RGBA) uint32 {
+	return (299*uint32(c.R) + 587*uint32(c.G) + 114*uint32(c.B)) / 1000
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeScreen struct {
+	img  *image.RGBA
+	beam image.Point
+}
+
+func (s *fakeScreen) Image() *image.RGBA { return s.img }
+
+func (s *fakeScreen) BeamPosition() image.Point { return s.beam }
+
+func (s *fakeScreen) CursorPosition() image.Point { return image.Point{} }
+
+func TestZapper_Read(t *testing.T) {
+	t.Parallel()
+
+	screen := &fakeScreen{img: image.NewRGBA(image.Rect(0, 0, 256, 240))}
+	screen.img.SetRGBA(100, 50, color.RGBA{R: 0xFF, G: 0xFF, B: 0xFF, A: 0xFF})
+	z := &Zapper{screen: screen, Aim: image.Pt(101, 51), OnScreen: true}
+
+	tests := []struct {
+		name  string
+		beam  image.Point
+		light bool
+	}{
+		{"before the pixel is drawn", image.Pt(50, 50), false},
+		{"as the pixel is drawn", image.Pt(100, 50), true},
+		{"a few scanlines later", image.Pt(0, 60), true},
+		{"after the sensor resets", image.Pt(0, 50+zapperHoldLines), false},
+	}
+	for _, tt := range tests {
+		screen.beam = tt.beam
+		assert.Equal(t, tt.light, z.Light(), tt.name)
+	}
+
+	screen.beam = image.Pt(0, 55)
+	assert.EqualValues(t, 0, z.Read(), "light detected, trigger released")
+	z.Trigger = true
+	assert.EqualValues(t, 0x10, z.Read())
+	z.OnScreen = false
+	assert.EqualValues(t, 0x18, z.Read(), "no light when aimed off screen")
+}
@@ -30,6 +30,28 @@ const (
 	IndirectY
 )
 
+// Format formats an operand using assembler syntax for the addressing mode.
+func (m AddressingMode) Format(operand string) string {
+	switch m {
+	case Accumulator:
+		return "A"
+	case Immediate:
+		return "#" + operand
+	case ZeroPageX, AbsoluteX:
+		return operand + ",X"
+	case ZeroPageY, AbsoluteY:
+		return operand + ",Y"
+	case Indirect:
+		return "(" + operand + ")"
+	case IndirectX:
+		return "(" + operand + ",X)"
+	case IndirectY:
+		return "(" + operand + "),Y"
+	default:
+		return operand
+	}
+}
+
 // getAbsoluteAddress gets the address for an address based on the [AddressingMode].
 //
 // See [6502 Addressing Mode].
@@ -8,6 +8,7 @@ import (
 	"gabe565.com/gones/internal/cartridge"
 	"gabe565.com/gones/internal/config"
 	"gabe565.com/gones/internal/ppu"
+	"gabe565.com/gones/internal/region"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -15,10 +16,10 @@ import (
 func stubCPU(program []byte) *CPU {
 	cart := cartridge.FromBytes(program)
 	mapper := cartridge.NewMapper2(cart)
-	ppu := ppu.New(config.NewDefault(), mapper)
+	ppu := ppu.New(config.NewDefault(), mapper, region.NTSC)
 	conf := config.NewDefault()
-	apu := apu.New(conf)
-	bus := bus.New(conf, mapper, ppu, apu)
+	apu := apu.New(conf, region.NTSC)
+	bus := bus.New(conf, mapper, ppu, apu, nil)
 	cpu := New(bus)
 	apu.SetCPU(cpu)
 	return cpu
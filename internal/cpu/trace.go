@@ -38,25 +38,24 @@ func (c *CPU) Trace() string {
 	switch op.Len {
 	case 1:
 		if op.Mode == Accumulator {
-			trace += "A "
+			trace += op.Mode.Format("") + " "
 		}
 	case 2:
 		addr := c.ReadMem(begin + 1)
 		hexDump = append(hexDump, addr)
 
+		operand := op.Mode.Format(fmt.Sprintf("$%02X", addr))
 		switch op.Mode {
 		case Immediate:
-			trace += fmt.Sprintf("#$%02X", addr)
+			trace += operand
 		case ZeroPage:
-			trace += fmt.Sprintf("$%02X = %02X", valAddr, val)
-		case ZeroPageX:
-			trace += fmt.Sprintf("$%02X,X @ %02X = %02X", addr, valAddr, val)
-		case ZeroPageY:
-			trace += fmt.Sprintf("$%02X,Y @ %02X = %02X", addr, valAddr, val)
+			trace += fmt.Sprintf("%s = %02X", operand, val)
+		case ZeroPageX, ZeroPageY:
+			trace += fmt.Sprintf("%s @ %02X = %02X", operand, valAddr, val)
 		case IndirectX:
-			trace += fmt.Sprintf("($%02X,X) @ %02X = %04X = %02X", addr, addr+c.RegisterX, valAddr, val)
+			trace += fmt.Sprintf("%s @ %02X = %04X = %02X", operand, addr+c.RegisterX, valAddr, val)
 		case IndirectY:
-			trace += fmt.Sprintf("($%02X),Y = %04X @ %04X = %02X", addr, valAddr-uint16(c.RegisterY), valAddr, val)
+			trace += fmt.Sprintf("%s = %04X @ %04X = %02X", operand, valAddr-uint16(c.RegisterY), valAddr, val)
 		case Implicit, Relative:
 			// assuming local jumps: BNE, BVS, etc
 			addr := uint16(int8(addr)) + begin + 2
@@ -71,21 +70,20 @@ func (c *CPU) Trace() string {
 		addr := c.ReadMem16(begin + 1)
 		hexDump = append(hexDump, uint8(addr&0xFF), uint8(addr>>8))
 
+		operand := op.Mode.Format(fmt.Sprintf("$%04X", addr))
 		switch op.Mode {
 		case Indirect:
-			trace += fmt.Sprintf("($%04X) = %04X", addr, valAddr)
+			trace += fmt.Sprintf("%s = %04X", operand, valAddr)
 		case Implicit, Relative:
 			trace += fmt.Sprintf("$%04X", addr)
 		case Absolute:
 			if op.Code() == 0x4C { // JMP
 				trace += fmt.Sprintf("$%04X", valAddr)
 			} else {
-				trace += fmt.Sprintf("$%04X = %02X", valAddr, val)
+				trace += fmt.Sprintf("%s = %02X", operand, val)
 			}
-		case AbsoluteX:
-			trace += fmt.Sprintf("$%04X,X @ %04X = %02X", addr, valAddr, val)
-		case AbsoluteY:
-			trace += fmt.Sprintf("$%04X,Y @ %04X = %02X", addr, valAddr, val)
+		case AbsoluteX, AbsoluteY:
+			trace += fmt.Sprintf("%s @ %04X = %02X", operand, valAddr, val)
 		default:
 			slog.Error("Invalid addressing mode has len 3",
 				"mode", op.Mode,
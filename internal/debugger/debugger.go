@@ -0,0 +1,428 @@
+// Package debugger implements an interactive CPU debugger with breakpoints and watchpoints.
+//
+// The emulator drives the debugger by calling [Debugger.AfterStep] after every CPU step while holding the lock.
+// Front ends such as the REPL and GDB stub run on their own goroutines and use the exported methods,
+// which lock the debugger so they only observe the CPU between emulator updates.
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"gabe565.com/gones/internal/cpu"
+	"gabe565.com/gones/internal/memory"
+)
+
+// Bus is the memory bus the debugger inspects and watches.
+type Bus interface {
+	memory.ReadSafe
+	memory.Write8
+	SetWatcher(w memory.Watcher)
+}
+
+type mode uint8
+
+const (
+	modeContinue mode = iota
+	modeStep
+	modeStepOver
+	modeStepOut
+)
+
+const opJSR = 0x20
+
+func New() *Debugger {
+	return &Debugger{
+		paused:      true,
+		breakpoints: make(map[uint16]struct{}),
+		stops:       make(chan Stop, 1),
+	}
+}
+
+// Debugger pauses and inspects the CPU.
+type Debugger struct {
+	mu sync.Mutex
+
+	cpu *cpu.CPU
+	bus Bus
+
+	paused  bool
+	mode    mode
+	steps   uint
+	sp      byte
+	retAddr uint16
+	hit     *Stop
+	lastErr error
+
+	breakpoints map[uint16]struct{}
+	watchpoints []Watchpoint
+
+	stops chan Stop
+}
+
+// Attach connects the debugger to a CPU and its bus.
+func (d *Debugger) Attach(c *cpu.CPU, b Bus) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cpu = c
+	d.bus = b
+	d.updateWatcher()
+}
+
+// Lock is held by the emulator while it steps the CPU.
+func (d *Debugger) Lock() { d.mu.Lock() }
+
+// Unlock releases the lock acquired by [Debugger.Lock].
+func (d *Debugger) Unlock() { d.mu.Unlock() }
+
+// Paused reports whether the emulator should stop stepping. The lock must be held.
+func (d *Debugger) Paused() bool {
+	return d.paused
+}
+
+// AfterStep is called by the emulator after every CPU step while the lock is held.
+// stalled reports whether the CPU was stalled before the step, meaning no instruction was executed.
+// It returns true when the emulator should pause.
+func (d *Debugger) AfterStep(stalled bool) bool {
+	if !stalled && d.mode == modeStep && d.steps != 0 {
+		d.steps--
+	}
+	if d.cpu.Stall != 0 {
+		// Wait for DMA to finish so that the CPU stops on an instruction boundary
+		return false
+	}
+
+	pc := d.cpu.ProgramCounter
+	var stop Stop
+	switch {
+	case d.hit != nil:
+		stop = *d.hit
+		d.hit = nil
+	case d.cpu.StepErr != nil && d.cpu.StepErr != d.lastErr: //nolint:errorlint
+		d.lastErr = d.cpu.StepErr
+		stop = Stop{Reason: StopError, Err: d.cpu.StepErr}
+	case d.hasBreakpoint(pc):
+		stop = Stop{Reason: StopBreakpoint, Addr: pc}
+	default:
+		if w, ok := d.findWatchpoint(pc, AccessExec); ok {
+			stop = Stop{Reason: StopWatchpoint, Addr: pc, Access: AccessExec, Watchpoint: w}
+			break
+		}
+
+		switch d.mode {
+		case modeContinue:
+			return false
+		case modeStep:
+			if d.steps != 0 {
+				return false
+			}
+		case modeStepOver:
+			if pc != d.retAddr || d.cpu.StackPointer < d.sp {
+				return false
+			}
+		case modeStepOut:
+			if d.cpu.StackPointer <= d.sp {
+				return false
+			}
+		}
+		stop = Stop{Reason: StopStep}
+	}
+
+	d.stop(stop)
+	return true
+}
+
+// OnRead implements [memory.Watcher].
+func (d *Debugger) OnRead(addr uint16, data byte) {
+	if d.hit == nil {
+		if w, ok := d.findWatchpoint(addr, AccessRead); ok {
+			d.hit = &Stop{Reason: StopWatchpoint, Addr: addr, Access: AccessRead, Value: data, Watchpoint: w}
+		}
+	}
+}
+
+// OnWrite implements [memory.Watcher].
+func (d *Debugger) OnWrite(addr uint16, data byte) {
+	if d.hit == nil {
+		if w, ok := d.findWatchpoint(addr, AccessWrite); ok {
+			d.hit = &Stop{Reason: StopWatchpoint, Addr: addr, Access: AccessWrite, Value: data, Watchpoint: w}
+		}
+	}
+}
+
+func (d *Debugger) stop(stop Stop) {
+	d.paused = true
+	d.mode = modeContinue
+	stop.PC = d.cpu.ProgramCounter
+	select {
+	case d.stops <- stop:
+	default:
+	}
+}
+
+func (d *Debugger) resume(m mode) {
+	// Drop a stop that was never received so that callers only see the next one
+	select {
+	case <-d.stops:
+	default:
+	}
+	d.hit = nil
+	d.mode = m
+	d.paused = false
+}
+
+// Stops returns a channel that receives an event every time the CPU pauses.
+func (d *Debugger) Stops() <-chan Stop {
+	return d.stops
+}
+
+// Pause stops the CPU at the next instruction boundary.
+func (d *Debugger) Pause() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.paused {
+		d.stop(Stop{Reason: StopPause})
+	}
+}
+
+// IsPaused reports whether the CPU is paused.
+func (d *Debugger) IsPaused() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.paused
+}
+
+// Continue resumes execution until a breakpoint or watchpoint is hit.
+func (d *Debugger) Continue() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resume(modeContinue)
+}
+
+// Step executes n instructions.
+func (d *Debugger) Step(n uint) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resume(modeStep)
+	d.steps = max(n, 1)
+}
+
+// StepOver executes one instruction, running subroutine calls to completion.
+func (d *Debugger) StepOver() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	pc := d.cpu.ProgramCounter
+	if d.bus.ReadMemSafe(pc) != opJSR {
+		d.resume(modeStep)
+		d.steps = 1
+		return
+	}
+	d.resume(modeStepOver)
+	d.retAddr = pc + 3
+	d.sp = d.cpu.StackPointer
+}
+
+// StepOut runs until the current subroutine returns.
+func (d *Debugger) StepOut() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resume(modeStepOut)
+	d.sp = d.cpu.StackPointer
+}
+
+// Detach removes all breakpoints and watchpoints, then resumes execution.
+func (d *Debugger) Detach() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	clear(d.breakpoints)
+	d.watchpoints = nil
+	d.updateWatcher()
+	d.resume(modeContinue)
+}
+
+// SetBreakpoint pauses the CPU before the instruction at addr is executed.
+func (d *Debugger) SetBreakpoint(addr uint16) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.breakpoints[addr] = struct{}{}
+}
+
+// ClearBreakpoint removes a breakpoint. It returns false if no breakpoint was set.
+func (d *Debugger) ClearBreakpoint(addr uint16) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	_, ok := d.breakpoints[addr]
+	delete(d.breakpoints, addr)
+	return ok
+}
+
+// Breakpoints returns the sorted breakpoint addresses.
+func (d *Debugger) Breakpoints() []uint16 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	addrs := make([]uint16, 0, len(d.breakpoints))
+	for addr := range d.breakpoints {
+		addrs = append(addrs, addr)
+	}
+	slices.Sort(addrs)
+	return addrs
+}
+
+func (d *Debugger) hasBreakpoint(addr uint16) bool {
+	_, ok := d.breakpoints[addr]
+	return ok
+}
+
+// AddWatchpoint pauses the CPU when a matching access occurs.
+func (d *Debugger) AddWatchpoint(w Watchpoint) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !slices.Contains(d.watchpoints, w) {
+		d.watchpoints = append(d.watchpoints, w)
+	}
+	d.updateWatcher()
+}
+
+// RemoveWatchpoint removes a watchpoint. It returns false if the watchpoint was not set.
+func (d *Debugger) RemoveWatchpoint(w Watchpoint) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	i := slices.Index(d.watchpoints, w)
+	if i == -1 {
+		return false
+	}
+	d.watchpoints = slices.Delete(d.watchpoints, i, i+1)
+	d.updateWatcher()
+	return true
+}
+
+// Watchpoints returns the active watchpoints.
+func (d *Debugger) Watchpoints() []Watchpoint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.watchpoints)
+}
+
+func (d *Debugger) findWatchpoint(addr uint16, access Access) (Watchpoint, bool) {
+	for _, w := range d.watchpoints {
+		if w.Matches(addr, access) {
+			return w, true
+		}
+	}
+	return Watchpoint{}, false
+}
+
+// updateWatcher only hooks the bus while read or write watchpoints are set, since every access is checked.
+func (d *Debugger) updateWatcher() {
+	if d.bus == nil {
+		return
+	}
+	for _, w := range d.watchpoints {
+		if w.Access&(AccessRead|AccessWrite) != 0 {
+			d.bus.SetWatcher(d)
+			return
+		}
+	}
+	d.bus.SetWatcher(nil)
+}
+
+// Registers is a snapshot of the CPU registers.
+type Registers struct {
+	A, X, Y, P, SP byte
+	PC             uint16
+	Cycles         uint
+}
+
+func (r Registers) String() string {
+	return fmt.Sprintf("PC:%04X A:%02X X:%02X Y:%02X P:%02X SP:%02X CYC:%d",
+		r.PC, r.A, r.X, r.Y, r.P, r.SP, r.Cycles,
+	)
+}
+
+// Registers returns the current CPU registers.
+func (d *Debugger) Registers() Registers {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return Registers{
+		A:      d.cpu.Accumulator,
+		X:      d.cpu.RegisterX,
+		Y:      d.cpu.RegisterY,
+		P:      d.cpu.Status.Get(),
+		SP:     d.cpu.StackPointer,
+		PC:     d.cpu.ProgramCounter,
+		Cycles: d.cpu.Cycles,
+	}
+}
+
+var (
+	ErrUnknownRegister = errors.New("unknown register")
+	ErrRegisterRange   = errors.New("value out of range for register")
+)
+
+// RegisterNames lists the registers accepted by [Debugger.SetRegister].
+func RegisterNames() []string {
+	return []string{"a", "x", "y", "p", "sp", "pc"}
+}
+
+// SetRegister changes a CPU register by name.
+func (d *Debugger) SetRegister(name string, v uint16) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	name = strings.ToLower(name)
+	if name != "pc" && v > 0xFF {
+		return fmt.Errorf("%w %s: $%X", ErrRegisterRange, name, v)
+	}
+	switch name {
+	case "a":
+		d.cpu.Accumulator = byte(v)
+	case "x":
+		d.cpu.RegisterX = byte(v)
+	case "y":
+		d.cpu.RegisterY = byte(v)
+	case "p":
+		d.cpu.Status.Set(byte(v))
+	case "sp":
+		d.cpu.StackPointer = byte(v)
+	case "pc":
+		d.cpu.ProgramCounter = v
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownRegister, name)
+	}
+	return nil
+}
+
+// ReadMem reads n bytes starting at addr without triggering side effects or watchpoints.
+// Registers with read side effects return $FF.
+func (d *Debugger) ReadMem(addr uint16, n int) []byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	buf := make([]byte, n)
+	for i := range buf {
+		buf[i] = d.bus.ReadMemSafe(addr + uint16(i))
+	}
+	return buf
+}
+
+// WriteMem writes data starting at addr as if the CPU had written it, without triggering watchpoints.
+func (d *Debugger) WriteMem(addr uint16, data []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	hit := d.hit
+	for i, b := range data {
+		d.bus.WriteMem(addr+uint16(i), b)
+	}
+	d.hit = hit
+}
+
+// Trace returns a trace log line for the next instruction.
+func (d *Debugger) Trace() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	hit := d.hit
+	trace := d.cpu.Trace()
+	d.hit = hit
+	return trace
+}
@@ -0,0 +1,242 @@
+package debugger
+
+import (
+	"testing"
+
+	"gabe565.com/gones/internal/cpu"
+	"gabe565.com/gones/internal/interrupt"
+	"gabe565.com/gones/internal/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testBus struct {
+	mem     [0x10000]byte
+	watcher memory.Watcher
+}
+
+func (b *testBus) ReadMem(addr uint16) byte {
+	if b.watcher != nil {
+		b.watcher.OnRead(addr, b.mem[addr])
+	}
+	return b.mem[addr]
+}
+
+func (b *testBus) ReadMemSafe(addr uint16) byte { return b.mem[addr] }
+
+func (b *testBus) WriteMem(addr uint16, data byte) {
+	if b.watcher != nil {
+		b.watcher.OnWrite(addr, data)
+	}
+	b.mem[addr] = data
+}
+
+func (b *testBus) ReadMem16(addr uint16) uint16 {
+	return uint16(b.ReadMem(addr+1))<<8 | uint16(b.ReadMem(addr))
+}
+
+func (b *testBus) WriteMem16(addr uint16, data uint16) {
+	b.WriteMem(addr, byte(data))
+	b.WriteMem(addr+1, byte(data>>8))
+}
+
+func (b *testBus) SetWatcher(w memory.Watcher) { b.watcher = w }
+
+//nolint:gochecknoglobals
+var testProgram = []byte{
+	0xA9, 0x01, // 8000: LDA #$01
+	0x20, 0x10, 0x80, // 8002: JSR $8010
+	0x8D, 0x00, 0x02, // 8005: STA $0200
+	0x4C, 0x08, 0x80, // 8008: JMP $8008
+	0x00, 0x00, 0x00, 0x00, 0x00,
+	0xAD, 0x00, 0x03, // 8010: LDA $0300
+	0x69, 0x01, // 8013: ADC #$01
+	0x60, // 8015: RTS
+}
+
+func newTestDebugger(t *testing.T) (*Debugger, *cpu.CPU, *testBus) {
+	t.Helper()
+	b := &testBus{}
+	copy(b.mem[0x8000:], testProgram)
+	b.mem[interrupt.ResetVector] = 0x00
+	b.mem[interrupt.ResetVector+1] = 0x80
+	b.mem[0x0300] = 0x41
+
+	c := cpu.New(b)
+	d := New()
+	d.Attach(c, b)
+	return d, c, b
+}
+
+// run steps the CPU like the console does, returning true if the debugger paused.
+func run(d *Debugger, c *cpu.CPU) bool {
+	d.Lock()
+	defer d.Unlock()
+	for range 1000 {
+		if d.Paused() {
+			return true
+		}
+		stalled := c.Stall != 0
+		c.Step()
+		if d.AfterStep(stalled) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDebugger_Step(t *testing.T) {
+	t.Parallel()
+	d, c, _ := newTestDebugger(t)
+	require.True(t, d.IsPaused())
+	require.True(t, run(d, c))
+
+	d.Step(1)
+	require.True(t, run(d, c))
+	assert.Equal(t, Stop{Reason: StopStep, PC: 0x8002}, <-d.Stops())
+
+	d.Step(2)
+	require.True(t, run(d, c))
+	assert.Equal(t, uint16(0x8013), c.ProgramCounter)
+	<-d.Stops()
+
+	d.StepOut()
+	require.True(t, run(d, c))
+	assert.Equal(t, uint16(0x8005), c.ProgramCounter)
+	assert.Equal(t, byte(0x42), c.Accumulator)
+}
+
+func TestDebugger_StepOver(t *testing.T) {
+	t.Parallel()
+	d, c, _ := newTestDebugger(t)
+
+	d.StepOver()
+	require.True(t, run(d, c))
+	assert.Equal(t, uint16(0x8002), c.ProgramCounter)
+
+	d.StepOver()
+	require.True(t, run(d, c))
+	assert.Equal(t, uint16(0x8005), c.ProgramCounter)
+	assert.Equal(t, byte(0x42), c.Accumulator)
+}
+
+func TestDebugger_Breakpoint(t *testing.T) {
+	t.Parallel()
+	d, c, _ := newTestDebugger(t)
+
+	d.SetBreakpoint(0x8013)
+	assert.Equal(t, []uint16{0x8013}, d.Breakpoints())
+	d.Continue()
+	require.True(t, run(d, c))
+	assert.Equal(t, Stop{Reason: StopBreakpoint, PC: 0x8013, Addr: 0x8013}, <-d.Stops())
+
+	assert.True(t, d.ClearBreakpoint(0x8013))
+	assert.False(t, d.ClearBreakpoint(0x8013))
+	d.Continue()
+	assert.False(t, run(d, c))
+}
+
+func TestDebugger_Watchpoint(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		watch Watchpoint
+		want  Stop
+	}{
+		{
+			"read",
+			Watchpoint{Start: 0x0300, End: 0x0300, Access: AccessRead},
+			Stop{Reason: StopWatchpoint, PC: 0x8013, Addr: 0x0300, Access: AccessRead, Value: 0x41},
+		},
+		{
+			"write",
+			Watchpoint{Start: 0x0200, End: 0x02FF, Access: AccessRead | AccessWrite},
+			Stop{Reason: StopWatchpoint, PC: 0x8008, Addr: 0x0200, Access: AccessWrite, Value: 0x42},
+		},
+		{
+			"exec",
+			Watchpoint{Start: 0x8010, End: 0x8015, Access: AccessExec},
+			Stop{Reason: StopWatchpoint, PC: 0x8010, Addr: 0x8010, Access: AccessExec},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			d, c, b := newTestDebugger(t)
+
+			d.AddWatchpoint(tt.watch)
+			assert.Equal(t, tt.watch.Access&AccessExec == 0, b.watcher != nil)
+			d.Continue()
+			require.True(t, run(d, c))
+			tt.want.Watchpoint = tt.watch
+			assert.Equal(t, tt.want, <-d.Stops())
+
+			assert.True(t, d.RemoveWatchpoint(tt.watch))
+			assert.Nil(t, b.watcher)
+		})
+	}
+}
+
+func TestDebugger_Memory(t *testing.T) {
+	t.Parallel()
+	d, c, b := newTestDebugger(t)
+
+	d.AddWatchpoint(Watchpoint{Start: 0, End: 0xFFFF, Access: AccessRead | AccessWrite})
+	d.WriteMem(0x0010, []byte{1, 2, 3})
+	assert.Equal(t, []byte{0, 1, 2, 3}, d.ReadMem(0x000F, 4))
+	assert.Equal(t, []byte{1, 2, 3}, b.mem[0x10:0x13])
+	assert.NotEmpty(t, d.Trace())
+
+	// Inspection must not leave a pending watchpoint hit
+	d.Detach()
+	d.Pause()
+	<-d.Stops()
+	d.Step(1)
+	require.True(t, run(d, c))
+	assert.Equal(t, StopStep, (<-d.Stops()).Reason)
+}
+
+func TestDebugger_SetRegister(t *testing.T) {
+	t.Parallel()
+	d, _, _ := newTestDebugger(t)
+
+	require.NoError(t, d.SetRegister("A", 0x12))
+	require.NoError(t, d.SetRegister("x", 0x34))
+	require.NoError(t, d.SetRegister("y", 0x56))
+	require.NoError(t, d.SetRegister("p", 0xC3))
+	require.NoError(t, d.SetRegister("sp", 0xF0))
+	require.NoError(t, d.SetRegister("pc", 0x8013))
+	require.ErrorIs(t, d.SetRegister("a", 0x100), ErrRegisterRange)
+	require.ErrorIs(t, d.SetRegister("q", 0), ErrUnknownRegister)
+
+	r := d.Registers()
+	r.Cycles = 0
+	assert.Equal(t, Registers{A: 0x12, X: 0x34, Y: 0x56, P: 0xE3, SP: 0xF0, PC: 0x8013}, r)
+}
+
+func TestParseRange(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		s         string
+		wantStart uint16
+		wantEnd   uint16
+		wantErr   require.ErrorAssertionFunc
+	}{
+		{"$0200", 0x0200, 0x0200, require.NoError},
+		{"0x10-1F", 0x10, 0x1F, require.NoError},
+		{"8000-$FFFF", 0x8000, 0xFFFF, require.NoError},
+		{"20-10", 0, 0, require.Error},
+		{"10000", 0, 0, require.Error},
+		{"zz", 0, 0, require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			t.Parallel()
+			start, end, err := ParseRange(tt.s)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantStart, start)
+			assert.Equal(t, tt.wantEnd, end)
+		})
+	}
+}
@@ -0,0 +1,399 @@
+package debugger
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// gdbRegisters is the register order used by the g and G packets.
+// All registers are 8 bits, except PC which is 16 bits little-endian.
+//
+//nolint:gochecknoglobals
+var gdbRegisters = []string{"a", "x", "y", "p", "sp", "pc"}
+
+const gdbInterrupt = 0x03
+
+var ErrInvalidPacket = errors.New("invalid packet")
+
+// ListenGDB serves the GDB remote serial protocol on addr until ctx is canceled.
+// One client is served at a time. The CPU pauses when a client connects,
+// and the debugger is detached when it disconnects.
+func ListenGDB(ctx context.Context, d *Debugger, addr string) error {
+	var lc net.ListenConfig
+	l, err := lc.Listen(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	context.AfterFunc(ctx, func() {
+		_ = l.Close()
+	})
+	slog.Info("GDB stub listening", "addr", l.Addr().String())
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		slog.Info("GDB client connected", "addr", conn.RemoteAddr().String())
+		if err := ServeGDB(ctx, d, conn); err != nil && !errors.Is(err, io.EOF) {
+			slog.Error("GDB connection failed", "error", err)
+		}
+		_ = conn.Close()
+		slog.Info("GDB client disconnected")
+	}
+}
+
+type gdbEvent struct {
+	packet    string
+	interrupt bool
+	err       error
+}
+
+type gdbConn struct {
+	d       *Debugger
+	w       io.Writer
+	running bool
+}
+
+// ServeGDB serves the GDB remote serial protocol over a single connection.
+func ServeGDB(ctx context.Context, d *Debugger, rw io.ReadWriter) error {
+	defer d.Detach()
+	d.Pause()
+
+	events := make(chan gdbEvent)
+	g := &gdbConn{d: d, w: rw}
+	go g.read(ctx, bufio.NewReader(rw), events)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case stop := <-d.Stops():
+			if g.running {
+				g.running = false
+				if err := g.send(stopReply(stop)); err != nil {
+					return err
+				}
+			}
+		case ev := <-events:
+			switch {
+			case ev.err != nil:
+				return ev.err
+			case ev.interrupt:
+				d.Pause()
+			default:
+				reply, done := g.handle(ev.packet)
+				if reply != nil {
+					if err := g.send(*reply); err != nil {
+						return err
+					}
+				}
+				if done {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+func (g *gdbConn) read(ctx context.Context, r *bufio.Reader, events chan<- gdbEvent) {
+	emit := func(ev gdbEvent) bool {
+		select {
+		case events <- ev:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var noAck bool
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			emit(gdbEvent{err: err})
+			return
+		}
+
+		switch b {
+		case gdbInterrupt:
+			if !emit(gdbEvent{interrupt: true}) {
+				return
+			}
+		case '$':
+			packet, err := readPacket(r)
+			if err != nil {
+				if errors.Is(err, ErrInvalidPacket) {
+					_, _ = g.w.Write([]byte{'-'})
+					continue
+				}
+				emit(gdbEvent{err: err})
+				return
+			}
+			if !noAck {
+				if _, err := g.w.Write([]byte{'+'}); err != nil {
+					emit(gdbEvent{err: err})
+					return
+				}
+				// The request is acknowledged, but later packets are not
+				noAck = packet == "QStartNoAckMode"
+			}
+			if !emit(gdbEvent{packet: packet}) {
+				return
+			}
+		}
+	}
+}
+
+// readPacket reads the packet data after a '$' and validates its checksum.
+func readPacket(r *bufio.Reader) (string, error) {
+	data, err := r.ReadString('#')
+	if err != nil {
+		return "", err
+	}
+	data = data[:len(data)-1]
+
+	var sumBuf [2]byte
+	if _, err := io.ReadFull(r, sumBuf[:]); err != nil {
+		return "", err
+	}
+	sum, err := strconv.ParseUint(string(sumBuf[:]), 16, 8)
+	if err != nil || byte(sum) != checksum(data) {
+		return "", fmt.Errorf("%w: checksum mismatch", ErrInvalidPacket)
+	}
+
+	// Unescape binary data
+	if strings.Contains(data, "}") {
+		var buf strings.Builder
+		for i := 0; i < len(data); i++ {
+			if data[i] == '}' && i+1 < len(data) {
+				i++
+				buf.WriteByte(data[i] ^ 0x20)
+			} else {
+				buf.WriteByte(data[i])
+			}
+		}
+		data = buf.String()
+	}
+	return data, nil
+}
+
+func checksum(data string) byte {
+	var sum byte
+	for i := range len(data) {
+		sum += data[i]
+	}
+	return sum
+}
+
+func (g *gdbConn) send(data string) error {
+	_, err := fmt.Fprintf(g.w, "$%s#%02x", data, checksum(data))
+	return err
+}
+
+func stopReply(stop Stop) string {
+	if stop.Reason == StopWatchpoint && stop.Access != AccessExec {
+		kind := "watch"
+		if stop.Access == AccessRead {
+			kind = "rwatch"
+			if stop.Watchpoint.Access&AccessWrite != 0 {
+				kind = "awatch"
+			}
+		} else if stop.Watchpoint.Access&AccessRead != 0 {
+			kind = "awatch"
+		}
+		return fmt.Sprintf("T05%s:%04x;", kind, stop.Addr)
+	}
+	if stop.Reason == StopError {
+		// SIGILL
+		return "S04"
+	}
+	// SIGTRAP
+	return "S05"
+}
+
+// handle processes a packet. It returns the reply, or nil if the reply is sent when the CPU stops.
+// done is true when the client detached.
+func (g *gdbConn) handle(packet string) (*string, bool) {
+	reply := func(s string) (*string, bool) { return &s, false }
+	if packet == "" {
+		return reply("")
+	}
+
+	cmd, args := packet[0], packet[1:]
+	switch cmd {
+	case '?':
+		return reply("S05")
+	case 'g':
+		r := g.d.Registers()
+		return reply(hex.EncodeToString([]byte{r.A, r.X, r.Y, r.P, r.SP, byte(r.PC), byte(r.PC >> 8)}))
+	case 'G':
+		b, err := hex.DecodeString(args)
+		if err != nil || len(b) < 7 {
+			return reply("E01")
+		}
+		for i, name := range gdbRegisters[:5] {
+			_ = g.d.SetRegister(name, uint16(b[i]))
+		}
+		_ = g.d.SetRegister("pc", uint16(b[5])|uint16(b[6])<<8)
+		return reply("OK")
+	case 'p':
+		n, err := strconv.ParseUint(args, 16, 8)
+		if err != nil || int(n) >= len(gdbRegisters) {
+			return reply("E01")
+		}
+		r := g.d.Registers()
+		regs := []byte{r.A, r.X, r.Y, r.P, r.SP, byte(r.PC), byte(r.PC >> 8)}
+		if gdbRegisters[n] == "pc" {
+			return reply(hex.EncodeToString(regs[n : n+2]))
+		}
+		return reply(hex.EncodeToString(regs[n : n+1]))
+	case 'P':
+		nStr, vStr, _ := strings.Cut(args, "=")
+		n, err := strconv.ParseUint(nStr, 16, 8)
+		if err != nil || int(n) >= len(gdbRegisters) {
+			return reply("E01")
+		}
+		b, err := hex.DecodeString(vStr)
+		if err != nil || len(b) == 0 {
+			return reply("E01")
+		}
+		v := uint16(b[0])
+		if len(b) > 1 {
+			v |= uint16(b[1]) << 8
+		}
+		if err := g.d.SetRegister(gdbRegisters[n], v); err != nil {
+			return reply("E01")
+		}
+		return reply("OK")
+	case 'm':
+		addr, n, ok := parseAddrLen(args)
+		if !ok {
+			return reply("E01")
+		}
+		return reply(hex.EncodeToString(g.d.ReadMem(addr, n)))
+	case 'M':
+		loc, data, _ := strings.Cut(args, ":")
+		addr, n, ok := parseAddrLen(loc)
+		if !ok {
+			return reply("E01")
+		}
+		b, err := hex.DecodeString(data)
+		if err != nil || len(b) != n {
+			return reply("E01")
+		}
+		g.d.WriteMem(addr, b)
+		return reply("OK")
+	case 'c', 's':
+		if args != "" {
+			addr, err := strconv.ParseUint(args, 16, 16)
+			if err != nil {
+				return reply("E01")
+			}
+			_ = g.d.SetRegister("pc", uint16(addr))
+		}
+		g.running = true
+		if cmd == 'c' {
+			g.d.Continue()
+		} else {
+			g.d.Step(1)
+		}
+		return nil, false
+	case 'Z', 'z':
+		return reply(g.handleBreakpoint(cmd == 'Z', args))
+	case 'D':
+		ok := "OK"
+		return &ok, true
+	case 'k':
+		return nil, true
+	case 'H':
+		return reply("OK")
+	case 'q':
+		switch {
+		case strings.HasPrefix(args, "Supported"):
+			return reply("PacketSize=4000;QStartNoAckMode+")
+		case args == "Attached":
+			return reply("1")
+		case args == "C":
+			return reply("QC1")
+		case args == "fThreadInfo":
+			return reply("m1")
+		case args == "sThreadInfo":
+			return reply("l")
+		}
+	case 'Q':
+		if args == "StartNoAckMode" {
+			return reply("OK")
+		}
+	}
+	return reply("")
+}
+
+func (g *gdbConn) handleBreakpoint(insert bool, args string) string {
+	fields := strings.Split(args, ",")
+	if len(fields) < 3 {
+		return "E01"
+	}
+	addr, err := strconv.ParseUint(fields[1], 16, 16)
+	if err != nil {
+		return "E01"
+	}
+	kind, err := strconv.ParseUint(fields[2], 16, 16)
+	if err != nil {
+		return "E01"
+	}
+
+	var access Access
+	switch fields[0] {
+	case "0", "1":
+		if insert {
+			g.d.SetBreakpoint(uint16(addr))
+		} else {
+			g.d.ClearBreakpoint(uint16(addr))
+		}
+		return "OK"
+	case "2":
+		access = AccessWrite
+	case "3":
+		access = AccessRead
+	case "4":
+		access = AccessRead | AccessWrite
+	default:
+		return ""
+	}
+
+	w := Watchpoint{Start: uint16(addr), End: uint16(addr + max(kind, 1) - 1), Access: access}
+	if insert {
+		g.d.AddWatchpoint(w)
+	} else {
+		g.d.RemoveWatchpoint(w)
+	}
+	return "OK"
+}
+
+func parseAddrLen(s string) (uint16, int, bool) {
+	addrStr, lenStr, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, false
+	}
+	addr, err := strconv.ParseUint(addrStr, 16, 16)
+	if err != nil {
+		return 0, 0, false
+	}
+	n, err := strconv.ParseUint(lenStr, 16, 16)
+	if err != nil {
+		return 0, 0, false
+	}
+	return uint16(addr), int(n), true
+}
@@ -0,0 +1,94 @@
+package debugger
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type gdbClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func (c *gdbClient) send(packet string) {
+	c.t.Helper()
+	_, err := fmt.Fprintf(c.conn, "$%s#%02x", packet, checksum(packet))
+	require.NoError(c.t, err)
+	ack, err := c.r.ReadByte()
+	require.NoError(c.t, err)
+	require.Equal(c.t, byte('+'), ack)
+}
+
+func (c *gdbClient) recv() string {
+	c.t.Helper()
+	b, err := c.r.ReadByte()
+	require.NoError(c.t, err)
+	require.Equal(c.t, byte('$'), b)
+	packet, err := readPacket(c.r)
+	require.NoError(c.t, err)
+	return packet
+}
+
+func (c *gdbClient) request(packet string) string {
+	c.t.Helper()
+	c.send(packet)
+	return c.recv()
+}
+
+func TestServeGDB(t *testing.T) {
+	t.Parallel()
+	d, c, b := newTestDebugger(t)
+
+	server, conn := net.Pipe()
+	t.Cleanup(func() { _ = conn.Close() })
+	ctx, cancel := context.WithCancel(t.Context())
+	t.Cleanup(cancel)
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- ServeGDB(ctx, d, server)
+	}()
+
+	client := &gdbClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+	assert.Contains(t, client.request("qSupported:multiprocess+"), "PacketSize")
+	assert.Equal(t, "S05", client.request("?"))
+	assert.Equal(t, "00000024fd0080", client.request("g"))
+	assert.Equal(t, "OK", client.request("P0=7f"))
+	assert.Equal(t, "7f", client.request("p0"))
+	assert.Equal(t, "0080", client.request("p5"))
+	assert.Equal(t, "a90120", client.request("m8000,3"))
+	assert.Equal(t, "OK", client.request("M0010,2:beef"))
+	assert.Equal(t, []byte{0xBE, 0xEF}, b.mem[0x10:0x12])
+	assert.Empty(t, client.request("vMustReplyEmpty"))
+
+	resume := func(packet string) string {
+		client.send(packet)
+		require.Eventually(t, func() bool { return !d.IsPaused() }, time.Second, time.Millisecond)
+		require.True(t, run(d, c))
+		return client.recv()
+	}
+
+	assert.Equal(t, "S05", resume("s"))
+	assert.Equal(t, uint16(0x8002), c.ProgramCounter)
+
+	assert.Equal(t, "OK", client.request("Z0,8013,1"))
+	assert.Equal(t, "S05", resume("c"))
+	assert.Equal(t, uint16(0x8013), c.ProgramCounter)
+	assert.Equal(t, "OK", client.request("z0,8013,1"))
+
+	assert.Equal(t, "OK", client.request("Z2,0200,1"))
+	assert.Equal(t, "T05watch:0200;", resume("c"))
+	assert.Equal(t, "OK", client.request("z2,0200,1"))
+	assert.Nil(t, b.watcher)
+
+	assert.Equal(t, "OK", client.request("D"))
+	require.NoError(t, <-errCh)
+	assert.False(t, d.IsPaused())
+}
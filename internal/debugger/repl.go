@@ -0,0 +1,274 @@
+package debugger
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const replPrompt = "(gones) "
+
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrMissingArgs    = errors.New("missing arguments")
+)
+
+type replCommand struct {
+	names []string
+	usage string
+	help  string
+	run   func(r *repl, args []string) error
+}
+
+//nolint:gochecknoglobals
+var replCommands = []replCommand{
+	{[]string{"continue", "c"}, "", "Resume execution", func(r *repl, _ []string) error {
+		r.d.Continue()
+		return nil
+	}},
+	{[]string{"step", "s"}, "[COUNT]", "Execute one or more instructions", func(r *repl, args []string) error {
+		n := uint64(1)
+		if len(args) != 0 {
+			var err error
+			if n, err = strconv.ParseUint(args[0], 10, 32); err != nil {
+				return err
+			}
+		}
+		r.d.Step(uint(n))
+		return nil
+	}},
+	{[]string{"next", "n"}, "", "Execute one instruction, stepping over subroutine calls", func(r *repl, _ []string) error {
+		r.d.StepOver()
+		return nil
+	}},
+	{[]string{"finish", "out"}, "", "Run until the current subroutine returns", func(r *repl, _ []string) error {
+		r.d.StepOut()
+		return nil
+	}},
+	{[]string{"pause", "p"}, "", "Pause execution", func(r *repl, _ []string) error {
+		if r.d.IsPaused() {
+			r.printf("Already paused\n")
+		}
+		r.d.Pause()
+		return nil
+	}},
+	{[]string{"break", "b"}, "ADDR", "Set a breakpoint", func(r *repl, args []string) error {
+		if len(args) == 0 {
+			return ErrMissingArgs
+		}
+		addr, err := ParseAddress(args[0])
+		if err != nil {
+			return err
+		}
+		r.d.SetBreakpoint(addr)
+		r.printf("Breakpoint set at $%04X\n", addr)
+		return nil
+	}},
+	{[]string{"delete", "d"}, "ADDR", "Delete a breakpoint", func(r *repl, args []string) error {
+		if len(args) == 0 {
+			return ErrMissingArgs
+		}
+		addr, err := ParseAddress(args[0])
+		if err != nil {
+			return err
+		}
+		if !r.d.ClearBreakpoint(addr) {
+			r.printf("No breakpoint at $%04X\n", addr)
+		}
+		return nil
+	}},
+	{[]string{"watch", "w"}, "r|w|rw|x ADDR[-END]", "Set a watchpoint on reads, writes, or execution", func(r *repl, args []string) error {
+		w, err := parseWatchArgs(args)
+		if err != nil {
+			return err
+		}
+		r.d.AddWatchpoint(w)
+		r.printf("Watchpoint set: %s\n", w)
+		return nil
+	}},
+	{[]string{"unwatch", "uw"}, "r|w|rw|x ADDR[-END]", "Delete a watchpoint", func(r *repl, args []string) error {
+		w, err := parseWatchArgs(args)
+		if err != nil {
+			return err
+		}
+		if !r.d.RemoveWatchpoint(w) {
+			r.printf("No watchpoint %s\n", w)
+		}
+		return nil
+	}},
+	{[]string{"list", "l"}, "", "List breakpoints and watchpoints", func(r *repl, _ []string) error {
+		for _, addr := range r.d.Breakpoints() {
+			r.printf("break $%04X\n", addr)
+		}
+		for _, w := range r.d.Watchpoints() {
+			r.printf("watch %s\n", w)
+		}
+		return nil
+	}},
+	{[]string{"regs", "r"}, "", "Print registers", func(r *repl, _ []string) error {
+		r.printf("%s\n", r.d.Registers())
+		return nil
+	}},
+	{[]string{"set"}, "REG VALUE", "Set a register (" + strings.Join(RegisterNames(), ", ") + ")", func(r *repl, args []string) error {
+		if len(args) < 2 {
+			return ErrMissingArgs
+		}
+		v, err := ParseAddress(args[1])
+		if err != nil {
+			return err
+		}
+		return r.d.SetRegister(args[0], v)
+	}},
+	{[]string{"mem", "m", "x"}, "ADDR [LEN]", "Dump memory", func(r *repl, args []string) error {
+		if len(args) == 0 {
+			return ErrMissingArgs
+		}
+		addr, err := ParseAddress(args[0])
+		if err != nil {
+			return err
+		}
+		n := uint64(0x40)
+		if len(args) > 1 {
+			if n, err = strconv.ParseUint(strings.TrimPrefix(args[1], "$"), 16, 16); err != nil {
+				return err
+			}
+		}
+		r.dump(addr, r.d.ReadMem(addr, int(n)))
+		return nil
+	}},
+	{[]string{"poke"}, "ADDR BYTE...", "Write bytes to memory", func(r *repl, args []string) error {
+		if len(args) < 2 {
+			return ErrMissingArgs
+		}
+		addr, err := ParseAddress(args[0])
+		if err != nil {
+			return err
+		}
+		data := make([]byte, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			v, err := strconv.ParseUint(strings.TrimPrefix(arg, "$"), 16, 8)
+			if err != nil {
+				return err
+			}
+			data = append(data, byte(v))
+		}
+		r.d.WriteMem(addr, data)
+		return nil
+	}},
+	{[]string{"trace", "t"}, "", "Print the next instruction", func(r *repl, _ []string) error {
+		r.printf("%s\n", r.d.Trace())
+		return nil
+	}},
+	{[]string{"quit", "q"}, "", "Detach the debugger and resume execution", nil},
+	{[]string{"help", "h", "?"}, "", "Print this help", nil},
+}
+
+func parseWatchArgs(args []string) (Watchpoint, error) {
+	if len(args) < 2 {
+		return Watchpoint{}, ErrMissingArgs
+	}
+	access, err := ParseAccess(args[0])
+	if err != nil {
+		return Watchpoint{}, err
+	}
+	start, end, err := ParseRange(args[1])
+	if err != nil {
+		return Watchpoint{}, err
+	}
+	return Watchpoint{Start: start, End: end, Access: access}, nil
+}
+
+type repl struct {
+	d *Debugger
+	w io.Writer
+}
+
+// RunREPL reads debugger commands from r and writes results to w until ctx is canceled,
+// r is closed, or the quit command is entered. The debugger is detached when it returns.
+func RunREPL(ctx context.Context, d *Debugger, r io.Reader, w io.Writer) error {
+	defer d.Detach()
+
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	rp := &repl{d: d, w: w}
+	rp.printf("Debugger attached. Type \"help\" for a list of commands.\n")
+	rp.printf("%s\n%s", d.Trace(), replPrompt)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			return err
+		case stop := <-d.Stops():
+			rp.printf("\n%s\n%s\n%s", stop, d.Trace(), replPrompt)
+		case line := <-lines:
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				rp.printf("%s", replPrompt)
+				continue
+			}
+			if fields[0] == "quit" || fields[0] == "q" {
+				return nil
+			}
+			if err := rp.exec(fields[0], fields[1:]); err != nil {
+				rp.printf("Error: %v\n", err)
+			}
+			if d.IsPaused() {
+				rp.printf("%s", replPrompt)
+			}
+		}
+	}
+}
+
+func (r *repl) exec(name string, args []string) error {
+	for _, cmd := range replCommands {
+		if !slices.Contains(cmd.names, name) {
+			continue
+		}
+		if cmd.run == nil {
+			r.help()
+			return nil
+		}
+		return cmd.run(r, args)
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownCommand, name)
+}
+
+func (r *repl) help() {
+	for _, cmd := range replCommands {
+		r.printf("  %-32s %s\n", strings.Join(cmd.names, ", ")+" "+cmd.usage, cmd.help)
+	}
+}
+
+func (r *repl) printf(format string, a ...any) {
+	_, _ = fmt.Fprintf(r.w, format, a...)
+}
+
+func (r *repl) dump(addr uint16, data []byte) {
+	for i := 0; i < len(data); i += 16 {
+		row := data[i:min(i+16, len(data))]
+		r.printf("%04X ", addr+uint16(i))
+		for _, b := range row {
+			r.printf(" %02X", b)
+		}
+		r.printf("\n")
+	}
+}
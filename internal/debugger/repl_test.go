@@ -0,0 +1,40 @@
+package debugger
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunREPL(t *testing.T) {
+	t.Parallel()
+	d, _, b := newTestDebugger(t)
+
+	input := strings.Join([]string{
+		"b 8013",
+		"watch rw 0200-02ff",
+		"list",
+		"set x 7f",
+		"regs",
+		"poke 10 be ef",
+		"m 10 2",
+		"bogus",
+		"quit",
+	}, "\n")
+	var out strings.Builder
+	require.NoError(t, RunREPL(t.Context(), d, strings.NewReader(input), &out))
+
+	assert.Contains(t, out.String(), "Breakpoint set at $8013")
+	assert.Contains(t, out.String(), "break $8013\nwatch rw $0200-$02FF\n")
+	assert.Contains(t, out.String(), "PC:8000 A:00 X:7F")
+	assert.Contains(t, out.String(), "0010  BE EF\n")
+	assert.Contains(t, out.String(), "Error: unknown command: bogus")
+	assert.Equal(t, []byte{0xBE, 0xEF}, b.mem[0x10:0x12])
+
+	// Quitting detaches the debugger
+	assert.Empty(t, d.Breakpoints())
+	assert.Empty(t, d.Watchpoints())
+	assert.False(t, d.IsPaused())
+}
@@ -0,0 +1,46 @@
+package debugger
+
+import "fmt"
+
+//go:generate go tool stringer -type StopReason -trimprefix Stop
+
+type StopReason uint8
+
+const (
+	StopPause StopReason = iota
+	StopStep
+	StopBreakpoint
+	StopWatchpoint
+	StopError
+)
+
+// Stop describes why the CPU paused.
+type Stop struct {
+	Reason StopReason
+	// PC is the address of the next instruction.
+	PC uint16
+	// Addr is the address that triggered a breakpoint or watchpoint.
+	Addr uint16
+	// Access is the access type that triggered a watchpoint.
+	Access Access
+	// Value is the byte that was read or written for a watchpoint.
+	Value      byte
+	Watchpoint Watchpoint
+	Err        error
+}
+
+func (s Stop) String() string {
+	switch s.Reason {
+	case StopBreakpoint:
+		return fmt.Sprintf("Breakpoint at $%04X", s.Addr)
+	case StopWatchpoint:
+		if s.Access == AccessExec {
+			return fmt.Sprintf("Watchpoint %s: execute $%04X", s.Watchpoint, s.Addr)
+		}
+		return fmt.Sprintf("Watchpoint %s: %s $%04X = $%02X", s.Watchpoint, s.Access, s.Addr, s.Value)
+	case StopError:
+		return "Error: " + s.Err.Error()
+	default:
+		return s.Reason.String()
+	}
+}
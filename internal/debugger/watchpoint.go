@@ -0,0 +1,104 @@
+package debugger
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Access is a set of memory access types.
+type Access uint8
+
+const (
+	AccessRead Access = 1 << iota
+	AccessWrite
+	AccessExec
+)
+
+var ErrInvalidAccess = errors.New("invalid access type")
+
+// ParseAccess parses a combination of "r", "w", and "x".
+func ParseAccess(s string) (Access, error) {
+	var a Access
+	for _, c := range strings.ToLower(s) {
+		switch c {
+		case 'r':
+			a |= AccessRead
+		case 'w':
+			a |= AccessWrite
+		case 'x':
+			a |= AccessExec
+		default:
+			return 0, fmt.Errorf("%w: %q", ErrInvalidAccess, s)
+		}
+	}
+	if a == 0 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAccess, s)
+	}
+	return a, nil
+}
+
+func (a Access) String() string {
+	var s string
+	if a&AccessRead != 0 {
+		s += "r"
+	}
+	if a&AccessWrite != 0 {
+		s += "w"
+	}
+	if a&AccessExec != 0 {
+		s += "x"
+	}
+	return s
+}
+
+// Watchpoint matches accesses to an inclusive address range.
+type Watchpoint struct {
+	Start, End uint16
+	Access     Access
+}
+
+// Matches reports whether an access to addr should trigger the watchpoint.
+func (w Watchpoint) Matches(addr uint16, access Access) bool {
+	return w.Access&access != 0 && w.Start <= addr && addr <= w.End
+}
+
+func (w Watchpoint) String() string {
+	if w.Start == w.End {
+		return fmt.Sprintf("%s $%04X", w.Access, w.Start)
+	}
+	return fmt.Sprintf("%s $%04X-$%04X", w.Access, w.Start, w.End)
+}
+
+var ErrInvalidAddress = errors.New("invalid address")
+
+// ParseAddress parses a hexadecimal address with an optional "$" or "0x" prefix.
+func ParseAddress(s string) (uint16, error) {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(s), "$"), "0x")
+	v, err := strconv.ParseUint(trimmed, 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAddress, s)
+	}
+	return uint16(v), nil
+}
+
+// ParseRange parses an address or an inclusive "start-end" address range.
+func ParseRange(s string) (uint16, uint16, error) {
+	startStr, endStr, isRange := strings.Cut(s, "-")
+	start, err := ParseAddress(startStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !isRange {
+		return start, start, nil
+	}
+	end, err := ParseAddress(endStr)
+	if err != nil {
+		return 0, 0, err
+	}
+	if end < start {
+		return 0, 0, fmt.Errorf("%w: %q", ErrInvalidAddress, s)
+	}
+	return start, end, nil
+}
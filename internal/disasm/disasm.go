@@ -0,0 +1,242 @@
+// Package disasm disassembles NES PRG ROM into ca65 source.
+package disasm
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"gabe565.com/gones/internal/cartridge"
+	"gabe565.com/gones/internal/cpu"
+	"gabe565.com/gones/internal/interrupt"
+)
+
+// Config configures how PRG ROM is split into banks and mapped into CPU memory.
+type Config struct {
+	// BankSize is the size of each PRG bank in bytes.
+	BankSize int
+	// Mapping maps bank indexes to CPU addresses.
+	// Unmapped banks are placed at $8000, except for the last bank which is placed at the top of memory.
+	Mapping map[int]uint16
+	// Undocumented allows undocumented opcodes to be traced as code.
+	// They are always written as bytes, since assemblers may encode them differently.
+	Undocumented bool
+}
+
+var (
+	ErrInvalidBankSize = errors.New("invalid bank size")
+	ErrInvalidBank     = errors.New("invalid bank")
+	ErrInvalidMapping  = errors.New("bank does not fit in PRG address space")
+)
+
+type byteKind uint8
+
+const (
+	kindData byteKind = iota
+	kindOpcode
+	kindOperand
+)
+
+type bank struct {
+	index  int
+	addr   uint16
+	data   []byte
+	kind   []byteKind
+	labels map[uint16]string
+	// shared is true when another bank is mapped to an overlapping address range
+	shared bool
+}
+
+func (b *bank) contains(addr uint16) bool {
+	return b.addr <= addr && int(addr) < int(b.addr)+len(b.data)
+}
+
+// Disassembler traces code from the interrupt vectors and formats PRG ROM as ca65 source.
+type Disassembler struct {
+	cart  *cartridge.Cartridge
+	conf  Config
+	banks []*bank
+	queue []entry
+}
+
+type entry struct {
+	bank *bank
+	addr uint16
+}
+
+// New splits PRG ROM into banks and traces code reachable from the interrupt vectors.
+func New(cart *cartridge.Cartridge, conf Config) (*Disassembler, error) {
+	if conf.BankSize <= 0 || conf.BankSize > 0x8000 || conf.BankSize&(conf.BankSize-1) != 0 {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidBankSize, conf.BankSize)
+	}
+
+	d := &Disassembler{cart: cart, conf: conf}
+	for i := 0; i < len(cart.PRG); i += conf.BankSize {
+		data := cart.PRG[i:min(i+conf.BankSize, len(cart.PRG))]
+		d.banks = append(d.banks, &bank{
+			index:  len(d.banks),
+			data:   data,
+			kind:   make([]byteKind, len(data)),
+			labels: make(map[uint16]string),
+		})
+	}
+
+	for i := range conf.Mapping {
+		if i < 0 || i >= len(d.banks) {
+			return nil, fmt.Errorf("%w: %d", ErrInvalidBank, i)
+		}
+	}
+
+	for _, b := range d.banks {
+		addr, ok := conf.Mapping[b.index]
+		switch {
+		case ok:
+			b.addr = addr
+		case b.index == len(d.banks)-1:
+			b.addr = uint16(0x10000 - len(b.data))
+		default:
+			b.addr = 0x8000
+		}
+		if b.addr < 0x4020 || int(b.addr)+len(b.data) > 0x10000 {
+			return nil, fmt.Errorf("%w: bank %d at $%04X", ErrInvalidMapping, b.index, b.addr)
+		}
+	}
+
+	for _, b := range d.banks {
+		for _, other := range d.banks {
+			if b != other && int(b.addr) < int(other.addr)+len(other.data) && int(other.addr) < int(b.addr)+len(b.data) {
+				b.shared = true
+			}
+		}
+	}
+
+	d.trace()
+	return d, nil
+}
+
+// vectorBank returns the bank that contains the interrupt vectors.
+func (d *Disassembler) vectorBank() *bank {
+	for _, b := range slices.Backward(d.banks) {
+		if b.contains(interrupt.NMIVector) && b.contains(interrupt.IRQVector+1) {
+			return b
+		}
+	}
+	return nil
+}
+
+// resolve finds the bank containing addr, preferring from if it is mapped there.
+// It returns nil if addr is outside PRG ROM or ambiguous.
+func (d *Disassembler) resolve(from *bank, addr uint16) *bank {
+	if from != nil && from.contains(addr) {
+		return from
+	}
+	var found *bank
+	for _, b := range d.banks {
+		if b.contains(addr) {
+			if found != nil {
+				return nil
+			}
+			found = b
+		}
+	}
+	return found
+}
+
+func (d *Disassembler) trace() {
+	if vb := d.vectorBank(); vb != nil {
+		for _, v := range []struct {
+			name string
+			addr uint16
+		}{
+			{"reset", interrupt.ResetVector},
+			{"nmi", interrupt.NMIVector},
+			{"irq", interrupt.IRQVector},
+		} {
+			off := v.addr - vb.addr
+			target := uint16(vb.data[off]) | uint16(vb.data[off+1])<<8
+			if b := d.resolve(vb, target); b != nil {
+				if _, ok := b.labels[target]; !ok {
+					b.labels[target] = v.name
+				}
+				d.queue = append(d.queue, entry{b, target})
+			}
+		}
+	}
+
+	for len(d.queue) != 0 {
+		e := d.queue[0]
+		d.queue = d.queue[1:]
+		d.traceFrom(e.bank, e.addr)
+	}
+}
+
+// traceFrom marks instructions as code until execution leaves the current path.
+func (d *Disassembler) traceFrom(b *bank, addr uint16) {
+	for b.contains(addr) {
+		off := int(addr - b.addr)
+		if b.kind[off] != kindData {
+			return
+		}
+
+		op := cpu.Lookup(b.data[off])
+		if op == nil || (op.Undocumented && !d.conf.Undocumented) || off+int(op.Len) > len(b.data) {
+			return
+		}
+		for i := 1; i < int(op.Len); i++ {
+			if b.kind[off+i] != kindData {
+				return
+			}
+		}
+
+		b.kind[off] = kindOpcode
+		for i := 1; i < int(op.Len); i++ {
+			b.kind[off+i] = kindOperand
+		}
+
+		switch {
+		case op.Mode == cpu.Relative:
+			d.addLabel(b, branchTarget(addr, b.data[off+1]))
+		case op.Name == cpu.JSR, op.Name == cpu.JMP && op.Mode == cpu.Absolute:
+			d.addLabel(b, operand16(b.data[off:]))
+		}
+
+		switch op.Name {
+		case cpu.JMP, cpu.RTS, cpu.RTI, cpu.BRK:
+			return
+		}
+		addr += uint16(op.Len)
+	}
+}
+
+func (d *Disassembler) addLabel(from *bank, addr uint16) {
+	b := d.resolve(from, addr)
+	if b == nil {
+		return
+	}
+	if _, ok := b.labels[addr]; !ok {
+		if b.shared {
+			b.labels[addr] = fmt.Sprintf("B%d_%04X", b.index, addr)
+		} else {
+			b.labels[addr] = fmt.Sprintf("L%04X", addr)
+		}
+	}
+	d.queue = append(d.queue, entry{b, addr})
+}
+
+// label returns the label for addr if it names the start of an instruction.
+func (d *Disassembler) label(from *bank, addr uint16) (string, bool) {
+	b := d.resolve(from, addr)
+	if b == nil {
+		return "", false
+	}
+	name, ok := b.labels[addr]
+	return name, ok && b.kind[addr-b.addr] == kindOpcode
+}
+
+func branchTarget(addr uint16, offset byte) uint16 {
+	return addr + 2 + uint16(int8(offset))
+}
+
+func operand16(b []byte) uint16 {
+	return uint16(b[1]) | uint16(b[2])<<8
+}
@@ -0,0 +1,134 @@
+package disasm
+
+import (
+	"strings"
+	"testing"
+
+	"gabe565.com/gones/internal/cartridge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCart(t *testing.T, prgSize int, program map[uint16][]byte) *cartridge.Cartridge {
+	t.Helper()
+	cart := cartridge.New()
+	require.NoError(t, cart.Header.SetPRGSize(prgSize))
+	cart.PRG = make([]byte, prgSize)
+	base := 0x10000 - prgSize
+	for addr, b := range program {
+		copy(cart.PRG[int(addr)-base:], b)
+	}
+	return cart
+}
+
+//nolint:gochecknoglobals
+var testProgram = map[uint16][]byte{
+	0xC000: {
+		0x78,       // C000: sei
+		0xA2, 0xFF, //       C001: ldx #$FF
+		0x9A,             // C003: txs
+		0x20, 0x10, 0xC0, // C004: jsr $C010
+		0xAD, 0x10, 0x00, // C007: lda a:$0010
+		0xD0, 0xFB, //       C00A: bne $C007
+		0x4C, 0x04, 0xC0, // C00D: jmp $C004
+		0xFF,       //             C00F: data
+		0x0A,       //       C010: asl a
+		0x07, 0x10, //       C011: slo $10
+		0x60,       //       C013: rts
+		0x40,       //       C014: rti
+		0xF0, 0x00, //       C015: beq $C017
+	},
+	0xFFFA: {0x14, 0xC0, 0x00, 0xC0, 0x15, 0xC0},
+}
+
+func TestDisassembler_WriteSource(t *testing.T) {
+	t.Parallel()
+
+	cart := newTestCart(t, 0x4000, testProgram)
+	d, err := New(cart, Config{BankSize: 0x4000})
+	require.NoError(t, err)
+
+	var buf strings.Builder
+	require.NoError(t, d.WriteSource(&buf))
+	src := buf.String()
+
+	for _, want := range []string{
+		"\t.byte \"NES\", $1A\n",
+		".segment \"PRG0\"\n",
+		"reset:\n\tsei ",
+		"\tjsr LC010 ",
+		"LC007:\n\tlda a:$0010 ",
+		"\tbne LC007 ",
+		"\tjmp LC004 ",
+		"\t.byte $FF ",
+		"LC010:\n\tasl a ",
+		"nmi:\n\trti ",
+		"irq:\n\tbeq LC017 ",
+		"\t.addr nmi, reset, irq ",
+	} {
+		assert.Contains(t, src, want)
+	}
+	assert.NotContains(t, src, "slo")
+	assert.NotContains(t, src, "CHR")
+}
+
+func TestDisassembler_Undocumented(t *testing.T) {
+	t.Parallel()
+
+	cart := newTestCart(t, 0x4000, testProgram)
+	d, err := New(cart, Config{BankSize: 0x4000, Undocumented: true})
+	require.NoError(t, err)
+
+	var buf strings.Builder
+	require.NoError(t, d.WriteSource(&buf))
+	assert.Contains(t, buf.String(), "\t.byte $07,$10                    ; $C011  07 10     *slo $10\n")
+	assert.Contains(t, buf.String(), "\trts ")
+}
+
+func TestDisassembler_Mapping(t *testing.T) {
+	t.Parallel()
+
+	cart := newTestCart(t, 0x8000, map[uint16][]byte{
+		0x8000: {0x4C, 0x00, 0xC0}, // 8000: jmp $C000
+		0xC000: {0x4C, 0x00, 0x80}, // C000: jmp $8000
+		0xFFFA: {0x00, 0xC0, 0x00, 0xC0, 0x00, 0xC0},
+	})
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+		d, err := New(cart, Config{BankSize: 0x4000})
+		require.NoError(t, err)
+
+		var buf strings.Builder
+		require.NoError(t, d.WriteSource(&buf))
+		assert.Contains(t, buf.String(), "reset:\n\tjmp L8000 ")
+		assert.Contains(t, buf.String(), "L8000:\n\tjmp reset ")
+
+		var cfg strings.Builder
+		require.NoError(t, d.WriteLinkerConfig(&cfg))
+		assert.Contains(t, cfg.String(), "PRG0: start = $8000, size = $4000, file = %O, fill = yes;")
+		assert.Contains(t, cfg.String(), "PRG1: start = $C000, size = $4000, file = %O, fill = yes;")
+	})
+
+	t.Run("8k banks", func(t *testing.T) {
+		t.Parallel()
+		d, err := New(cart, Config{BankSize: 0x2000, Mapping: map[int]uint16{2: 0xC000}})
+		require.NoError(t, err)
+
+		var buf strings.Builder
+		require.NoError(t, d.WriteSource(&buf))
+		// Banks 0 and 1 are both mapped at $8000, so the jump target is ambiguous
+		assert.Contains(t, buf.String(), "\tjmp $8000 ")
+		assert.Contains(t, buf.String(), "reset:\n")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := New(cart, Config{BankSize: 0x3000})
+		require.ErrorIs(t, err, ErrInvalidBankSize)
+		_, err = New(cart, Config{BankSize: 0x4000, Mapping: map[int]uint16{2: 0x8000}})
+		require.ErrorIs(t, err, ErrInvalidBank)
+		_, err = New(cart, Config{BankSize: 0x4000, Mapping: map[int]uint16{0: 0xE000}})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+	})
+}
@@ -0,0 +1,210 @@
+package disasm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"gabe565.com/gones/internal/cpu"
+	"gabe565.com/gones/internal/interrupt"
+)
+
+const bytesPerLine = 16
+
+// WriteSource writes ca65 source that reassembles to the original ROM.
+// Each bank is written to its own segment, so it must be linked with the config from [Disassembler.WriteLinkerConfig].
+func (d *Disassembler) WriteSource(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	var header bytes.Buffer
+	if err := binary.Write(&header, binary.LittleEndian, d.cart.Header); err != nil {
+		return err
+	}
+	if name := d.cart.Name(); name != "" {
+		_, _ = fmt.Fprintf(bw, "; %s\n", name)
+	}
+	_, _ = fmt.Fprintf(bw, "; Mapper %d, %d PRG banks of %d KiB\n", d.cart.Header.Mapper(), len(d.banks), d.conf.BankSize/1024)
+	_, _ = fmt.Fprintf(bw, "\n.segment \"HEADER\"\n")
+	_, _ = fmt.Fprintf(bw, "\t.byte \"NES\", $1A\n")
+	writeBytes(bw, header.Bytes()[4:], 0, false)
+
+	for _, b := range d.banks {
+		_, _ = fmt.Fprintf(bw, "\n.segment \"%s\"\n", segmentName(b))
+		d.writeBank(bw, b)
+	}
+
+	if d.cart.Header.CHRSize() != 0 {
+		_, _ = fmt.Fprintf(bw, "\n.segment \"CHR\"\n")
+		writeBytes(bw, d.cart.CHR, 0, true)
+	}
+
+	return bw.Flush()
+}
+
+func segmentName(b *bank) string {
+	return fmt.Sprintf("PRG%d", b.index)
+}
+
+func (d *Disassembler) writeBank(w io.Writer, b *bank) {
+	vectorOff := -1
+	if b == d.vectorBank() {
+		vectorOff = int(interrupt.NMIVector - b.addr)
+	}
+
+	for off := 0; off < len(b.data); {
+		addr := b.addr + uint16(off)
+		if b.kind[off] == kindOpcode {
+			if name, ok := b.labels[addr]; ok {
+				_, _ = fmt.Fprintf(w, "%s:\n", name)
+			}
+			op := cpu.Lookup(b.data[off])
+			raw := b.data[off : off+int(op.Len)]
+			code, note := d.instruction(b, addr, op, raw)
+			writeLine(w, code, note, addr, raw)
+			off += int(op.Len)
+			continue
+		}
+
+		if off == vectorOff && d.isData(b, off, 6) {
+			raw := b.data[off : off+6]
+			vectors := make([]string, 0, 3)
+			for i := 0; i < len(raw); i += 2 {
+				target := uint16(raw[i]) | uint16(raw[i+1])<<8
+				vectors = append(vectors, d.address(b, target, false))
+			}
+			writeLine(w, ".addr "+strings.Join(vectors, ", "), "vectors", addr, raw)
+			off += len(raw)
+			continue
+		}
+
+		end := off + 1
+		for end < len(b.data) && (int(b.addr)+end)%bytesPerLine != 0 && b.kind[end] == kindData && end != vectorOff {
+			end++
+		}
+		writeBytes(w, b.data[off:end], addr, true)
+		off = end
+	}
+}
+
+func (d *Disassembler) isData(b *bank, off, n int) bool {
+	if off+n > len(b.data) {
+		return false
+	}
+	for _, k := range b.kind[off : off+n] {
+		if k != kindData {
+			return false
+		}
+	}
+	return true
+}
+
+// instruction formats a single instruction using ca65 syntax.
+// Undocumented opcodes often have several encodings, so they are written as bytes with the instruction in note.
+func (d *Disassembler) instruction(b *bank, addr uint16, op *cpu.OpCode, raw []byte) (string, string) {
+	code := strings.ToLower(op.Name.String())
+	if operand := d.operand(b, addr, op, raw); operand != "" {
+		code += " " + operand
+	}
+	if op.Undocumented {
+		return byteDirective(raw), "*" + code
+	}
+	return code, ""
+}
+
+func (d *Disassembler) operand(b *bank, addr uint16, op *cpu.OpCode, raw []byte) string {
+	switch {
+	case op.Mode == cpu.Accumulator:
+		return format(op.Mode, "")
+	case op.Mode == cpu.Relative:
+		target := branchTarget(addr, raw[1])
+		if label, ok := d.label(b, target); ok {
+			return label
+		}
+		return fmt.Sprintf("*%+d", int(target)-int(addr))
+	case op.Len == 1:
+		return ""
+	case op.Len == 2:
+		return format(op.Mode, fmt.Sprintf("$%02X", raw[1]))
+	default:
+		forceAbs := op.Mode == cpu.Absolute || op.Mode == cpu.AbsoluteX || op.Mode == cpu.AbsoluteY
+		return format(op.Mode, d.address(b, operand16(raw), forceAbs))
+	}
+}
+
+// format formats an operand with lowercase register names, leaving labels untouched.
+func format(mode cpu.AddressingMode, operand string) string {
+	const placeholder = "\x00"
+	return strings.Replace(strings.ToLower(mode.Format(placeholder)), placeholder, operand, 1)
+}
+
+// address formats a 16-bit operand, using a label when one exists.
+func (d *Disassembler) address(b *bank, addr uint16, forceAbs bool) string {
+	if label, ok := d.label(b, addr); ok {
+		return label
+	}
+	if forceAbs && addr < 0x100 {
+		// Prevent ca65 from optimizing to zero page addressing
+		return fmt.Sprintf("a:$%04X", addr)
+	}
+	return fmt.Sprintf("$%04X", addr)
+}
+
+func writeLine(w io.Writer, code, note string, addr uint16, raw []byte) {
+	hexBytes := make([]string, len(raw))
+	for i, b := range raw {
+		hexBytes[i] = fmt.Sprintf("%02X", b)
+	}
+	comment := fmt.Sprintf("$%04X  %-8s", addr, strings.Join(hexBytes, " "))
+	if note != "" {
+		comment += "  " + note
+	}
+	_, _ = fmt.Fprintf(w, "\t%-32s ; %s\n", code, strings.TrimSpace(comment))
+}
+
+func writeBytes(w io.Writer, data []byte, addr uint16, comment bool) {
+	for i := 0; i < len(data); i += bytesPerLine {
+		row := data[i:min(i+bytesPerLine, len(data))]
+		if comment {
+			_, _ = fmt.Fprintf(w, "\t%-72s ; $%04X\n", byteDirective(row), addr+uint16(i))
+		} else {
+			_, _ = fmt.Fprintf(w, "\t%s\n", byteDirective(row))
+		}
+	}
+}
+
+func byteDirective(data []byte) string {
+	vals := make([]string, len(data))
+	for i, b := range data {
+		vals[i] = fmt.Sprintf("$%02X", b)
+	}
+	return ".byte " + strings.Join(vals, ",")
+}
+
+// WriteLinkerConfig writes an ld65 config that places each segment written by [Disassembler.WriteSource]
+// at its mapped address and in ROM file order.
+func (d *Disassembler) WriteLinkerConfig(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	_, _ = fmt.Fprintln(bw, "MEMORY {")
+	_, _ = fmt.Fprintln(bw, "    HEADER: start = $0000, size = $0010, file = %O, fill = yes;")
+	for _, b := range d.banks {
+		_, _ = fmt.Fprintf(bw, "    %s: start = $%04X, size = $%04X, file = %%O, fill = yes;\n", segmentName(b), b.addr, len(b.data))
+	}
+	if size := d.cart.Header.CHRSize(); size != 0 {
+		_, _ = fmt.Fprintf(bw, "    CHR: start = $0000, size = $%04X, file = %%O, fill = yes;\n", size)
+	}
+	_, _ = fmt.Fprintln(bw, "}")
+
+	_, _ = fmt.Fprintln(bw, "SEGMENTS {")
+	_, _ = fmt.Fprintln(bw, "    HEADER: load = HEADER, type = ro;")
+	for _, b := range d.banks {
+		_, _ = fmt.Fprintf(bw, "    %[1]s: load = %[1]s, type = ro;\n", segmentName(b))
+	}
+	if d.cart.Header.CHRSize() != 0 {
+		_, _ = fmt.Fprintln(bw, "    CHR: load = CHR, type = ro;")
+	}
+	_, _ = fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
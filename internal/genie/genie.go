@@ -0,0 +1,111 @@
+// Package genie encodes and decodes NES Game Genie codes.
+package genie
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// lookup maps each Game Genie letter to its 4-bit value.
+const lookup = "APZLGITYEOXUKSVN"
+
+// loPosOrder is the order that each letter's low 3 bits appear in the decoded value.
+//
+//nolint:gochecknoglobals
+var loPosOrder = [...]int{3, 5, 2, 4, 1, 0, 7, 6}
+
+// Code is a decoded Game Genie code.
+type Code struct {
+	Code    string
+	Address int
+	Replace int
+	// Compare is the value that must be read for the replacement to apply, or -1 for 6-letter codes.
+	Compare int
+}
+
+var (
+	ErrInvalidCodeLen   = errors.New("invalid length")
+	ErrInvalidCharacter = errors.New("invalid character")
+	ErrOutOfRange       = errors.New("encoded value out of range")
+)
+
+// Decode converts a 6 or 8 letter Game Genie code into an address, replacement value, and optional compare value.
+func Decode(code string) (Code, error) {
+	code = strings.ToUpper(code)
+	result := Code{Code: code, Compare: -1}
+
+	switch len(code) {
+	case 6, 8:
+	default:
+		return result, fmt.Errorf("%w %d in code %q; expected 6 or 8 characters", ErrInvalidCodeLen, len(code), code)
+	}
+
+	// Convert letters into integers (0x0-0xF)
+	codeValues := make([]int, 0, len(code))
+	for _, r := range code {
+		index := strings.IndexRune(lookup, r)
+		if index == -1 {
+			return result, fmt.Errorf("%w %q in code %q", ErrInvalidCharacter, r, code)
+		}
+		codeValues = append(codeValues, index)
+	}
+
+	// 24/32 bits (16 for address, 8 for replacement, 0 or 8 for compare)
+	var bigint int
+	for _, loPos := range loPosOrder[:len(code)] {
+		hiPos := (loPos - 1 + len(code)) % len(code)
+		bigint = (bigint << 4) | (codeValues[hiPos] & 8) | (codeValues[loPos] & 7)
+	}
+
+	// Split integer and set MSB of address
+	if len(code) == 8 {
+		compValue := bigint & 0xFF
+		result.Compare = compValue
+		bigint >>= 8
+	}
+
+	result.Address = (bigint >> 8) | 0x8000
+	result.Replace = bigint & 0xFF
+	return result, nil
+}
+
+// Encode converts an address, replacement value, and compare value into a Game Genie code.
+// A 6-letter code is returned when compare is -1.
+func Encode(address, replace, compare int) (string, error) {
+	var codeLen int
+	var bigint int
+
+	// Create 24/32-bit int and clear/set MSB of address for 6/8-letter codes
+	if compare == -1 {
+		codeLen = 6
+		address &= 0x7fff
+		bigint = (address << 8) | replace
+	} else {
+		codeLen = 8
+		address |= 0x8000
+		bigint = (address << 16) | (replace << 8) | compare
+	}
+
+	// Convert into 4-bit ints
+	encoded := make([]int, codeLen)
+	for i := codeLen - 1; i >= 0; i-- {
+		loPos := loPosOrder[i]
+		hiPos := (loPos - 1 + codeLen) % codeLen
+		encoded[loPos] |= bigint & 0b111
+		encoded[hiPos] |= bigint & 0b1000
+		bigint >>= 4
+	}
+
+	// Convert into letters
+	var result strings.Builder
+	result.Grow(codeLen)
+	for _, val := range encoded {
+		if val < 0 || val >= len(lookup) {
+			return "", ErrOutOfRange
+		}
+		result.WriteByte(lookup[val])
+	}
+
+	return result.String(), nil
+}
@@ -0,0 +1,118 @@
+// Package ips creates and applies IPS patches.
+package ips
+
+import (
+	"bytes"
+	"errors"
+)
+
+const (
+	header = "PATCH"
+	footer = "EOF"
+
+	// eofOffset is the record offset that would be read as the footer.
+	eofOffset = 0x454F46
+	// maxOffset is the largest offset that fits in a record.
+	maxOffset = 0xFFFFFF
+	// maxSize is the largest record payload.
+	maxSize = 0xFFFF
+)
+
+var (
+	ErrInvalidHeader = errors.New("missing IPS header")
+	ErrTruncated     = errors.New("truncated IPS patch")
+	ErrTooLarge      = errors.New("file is too large for an IPS patch")
+)
+
+// Diff returns a patch that converts original into modified.
+// If modified is shorter, the patch uses the truncation extension.
+func Diff(original, modified []byte) ([]byte, error) {
+	if len(modified) > maxOffset {
+		return nil, ErrTooLarge
+	}
+
+	buf := bytes.NewBufferString(header)
+	for i := 0; i < len(modified); {
+		if i < len(original) && modified[i] == original[i] {
+			i++
+			continue
+		}
+
+		start := i
+		if start == eofOffset {
+			// Start one byte earlier so the offset is not mistaken for the footer
+			start--
+		}
+		for i < len(modified) && i-start < maxSize && (i >= len(original) || modified[i] != original[i]) {
+			i++
+		}
+
+		buf.Write([]byte{byte(start >> 16), byte(start >> 8), byte(start)})
+		buf.Write([]byte{byte((i - start) >> 8), byte(i - start)})
+		buf.Write(modified[start:i])
+	}
+	buf.WriteString(footer)
+
+	if len(modified) < len(original) {
+		size := len(modified)
+		buf.Write([]byte{byte(size >> 16), byte(size >> 8), byte(size)})
+	}
+	return buf.Bytes(), nil
+}
+
+// Apply returns a copy of original with the patch applied.
+// Run-length encoded records and the truncation extension are supported.
+func Apply(original, patch []byte) ([]byte, error) {
+	if !bytes.HasPrefix(patch, []byte(header)) {
+		return nil, ErrInvalidHeader
+	}
+	patch = patch[len(header):]
+
+	result := bytes.Clone(original)
+	for {
+		if len(patch) < 3 {
+			return nil, ErrTruncated
+		}
+		if string(patch[:3]) == footer {
+			patch = patch[3:]
+			break
+		}
+
+		offset := int(patch[0])<<16 | int(patch[1])<<8 | int(patch[2])
+		if len(patch) < 5 {
+			return nil, ErrTruncated
+		}
+		size := int(patch[3])<<8 | int(patch[4])
+		patch = patch[5:]
+
+		var data []byte
+		if size == 0 {
+			// Run-length encoded record
+			if len(patch) < 3 {
+				return nil, ErrTruncated
+			}
+			size = int(patch[0])<<8 | int(patch[1])
+			data = bytes.Repeat(patch[2:3], size)
+			patch = patch[3:]
+		} else {
+			if len(patch) < size {
+				return nil, ErrTruncated
+			}
+			data = patch[:size]
+			patch = patch[size:]
+		}
+
+		if end := offset + size; end > len(result) {
+			result = append(result, make([]byte, end-len(result))...)
+		}
+		copy(result[offset:], data)
+	}
+
+	if len(patch) >= 3 {
+		size := int(patch[0])<<16 | int(patch[1])<<8 | int(patch[2])
+		if size < len(result) {
+			result = result[:size]
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,76 @@
+package ips
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	original := []byte{0, 1, 2, 3, 4, 5}
+	tests := []struct {
+		name     string
+		modified []byte
+		want     []byte
+	}{
+		{"unchanged", original, []byte("PATCHEOF")},
+		{"changed", []byte{0, 9, 9, 3, 4, 9}, []byte("PATCH\x00\x00\x01\x00\x02\x09\x09\x00\x00\x05\x00\x01\x09EOF")},
+		{"extended", []byte{0, 1, 2, 3, 4, 5, 6}, []byte("PATCH\x00\x00\x06\x00\x01\x06EOF")},
+		{"truncated", []byte{0, 1, 2}, []byte("PATCHEOF\x00\x00\x03")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := Diff(original, tt.modified)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			patched, err := Apply(original, got)
+			require.NoError(t, err)
+			assert.Equal(t, tt.modified, patched)
+		})
+	}
+}
+
+func TestDiff_EOFOffset(t *testing.T) {
+	t.Parallel()
+
+	original := make([]byte, eofOffset+2)
+	modified := bytes.Clone(original)
+	modified[eofOffset] = 1
+
+	patch, err := Diff(original, modified)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x45, 0x4F, 0x45, 0x00, 0x02, 0x00, 0x01}, patch[5:12])
+
+	patched, err := Apply(original, patch)
+	require.NoError(t, err)
+	assert.Equal(t, modified, patched)
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	t.Run("rle", func(t *testing.T) {
+		t.Parallel()
+		got, err := Apply(make([]byte, 4), []byte("PATCH\x00\x00\x01\x00\x00\x00\x02\xAAEOF"))
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0, 0xAA, 0xAA, 0}, got)
+	})
+
+	t.Run("invalid header", func(t *testing.T) {
+		t.Parallel()
+		_, err := Apply(nil, []byte("NOPE"))
+		require.ErrorIs(t, err, ErrInvalidHeader)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		t.Parallel()
+		_, err := Apply(make([]byte, 4), []byte("PATCH\x00\x00\x01\x00\x04\x01"))
+		require.ErrorIs(t, err, ErrTruncated)
+	})
+}
@@ -43,3 +43,9 @@ type ReadSafeWrite interface {
 type HasCycles interface {
 	GetCycles() uint
 }
+
+// Watcher observes memory accesses.
+type Watcher interface {
+	OnRead(addr uint16, data byte)
+	OnWrite(addr uint16, data byte)
+}
@@ -0,0 +1,223 @@
+package movie
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// fm2Version is the only FM2 version FCEUX has ever written.
+const fm2Version = 3
+
+// fm2Buttons is the order buttons are written in an FM2 input log, from bit 7 to bit 0.
+const fm2Buttons = "RLDUTSBA"
+
+// stateMagic is the gzip header that starts every GoNES save state.
+//
+//nolint:gochecknoglobals
+var stateMagic = []byte{0x1F, 0x8B}
+
+var (
+	ErrInvalidFM2      = errors.New("invalid fm2 file")
+	ErrUnsupportedFM2  = errors.New("unsupported fm2 file")
+	ErrChecksumInvalid = errors.New("invalid checksum")
+)
+
+// ReadFM2 parses an FCEUX text movie.
+//
+// Embedded save states must be GoNES save states, so FCEUX movies that start from a save state are not supported.
+// Hard resets are not supported either.
+//
+// See [FM2].
+//
+// [FM2]: https://fceux.com/web/help/fm2.html
+func ReadFM2(r io.Reader) (*Movie, error) {
+	m := &Movie{}
+	ports := [3]int{1, 1, 0}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var line int
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" {
+			continue
+		}
+
+		if text[0] == '|' {
+			frame, err := parseFM2Frame(text, ports, m.FourScore)
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidFM2, line, err)
+			}
+			if frame.Command&CommandHardReset != 0 {
+				return nil, fmt.Errorf("%w: line %d: hard reset", ErrUnsupportedFM2, line)
+			}
+			m.Frames = append(m.Frames, frame)
+			continue
+		}
+
+		key, val, _ := strings.Cut(text, " ")
+		var err error
+		switch key {
+		case "version":
+			var v int
+			if v, err = strconv.Atoi(val); err == nil && v != fm2Version {
+				return nil, fmt.Errorf("%w: version %d", ErrUnsupportedFM2, v)
+			}
+		case "emuVersion":
+			m.EmuVersion, err = strconv.Atoi(val)
+		case "rerecordCount":
+			m.RerecordCount, err = strconv.Atoi(val)
+		case "palFlag":
+			m.PAL = val == "1"
+		case "romFilename":
+			m.ROMFilename = val
+		case "romChecksum":
+			var b []byte
+			if b, err = decodeFM2Binary(val); err == nil {
+				if len(b) != len(m.ROMChecksum) {
+					err = ErrChecksumInvalid
+				}
+				copy(m.ROMChecksum[:], b)
+			}
+		case "guid":
+			m.GUID = val
+		case "comment":
+			m.Comments = append(m.Comments, val)
+		case "savestate":
+			if m.SaveState, err = decodeFM2Binary(val); err == nil && !bytes.HasPrefix(m.SaveState, stateMagic) {
+				return nil, fmt.Errorf("%w: savestate was not created by GoNES", ErrUnsupportedFM2)
+			}
+		case "port0", "port1", "port2":
+			port := int(key[4] - '0')
+			ports[port], err = strconv.Atoi(val)
+		case "binary":
+			if val == "1" {
+				return nil, fmt.Errorf("%w: binary input log", ErrUnsupportedFM2)
+			}
+		case "fourscore":
+			m.FourScore = val == "1"
+		case "FDS":
+			if val == "1" {
+				return nil, fmt.Errorf("%w: famicom disk system", ErrUnsupportedFM2)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %s: %w", ErrInvalidFM2, line, key, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// parseFM2Frame parses an input log line.
+// With a four score, the line has a field for each of the 4 controllers and the port types are ignored.
+func parseFM2Frame(text string, ports [3]int, fourScore bool) (Frame, error) {
+	var frame Frame
+	fields := strings.Split(strings.Trim(text, "|"), "|")
+	if len(fields) == 0 {
+		return frame, ErrInvalidFM2
+	}
+
+	cmd, err := strconv.ParseUint(fields[0], 10, 8)
+	if err != nil {
+		return frame, err
+	}
+	frame.Command = Command(cmd)
+
+	joypads := ports[:2]
+	if fourScore {
+		joypads = []int{1, 1, 1, 1}
+	}
+	for i, port := range joypads {
+		if port != 1 || i+1 >= len(fields) {
+			continue
+		}
+
+		field := fields[i+1]
+		if len(field) != len(fm2Buttons) {
+			return frame, fmt.Errorf("%w: port %d: %q", ErrInvalidFM2, i, field)
+		}
+		for j, r := range field {
+			if r != '.' && r != ' ' {
+				frame.Ports[i] |= 1 << (len(fm2Buttons) - 1 - j)
+			}
+		}
+	}
+	return frame, nil
+}
+
+// WriteFM2 writes the movie as an FCEUX text movie.
+func (m *Movie) WriteFM2(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	var pal, fourScore int
+	if m.PAL {
+		pal = 1
+	}
+	players := 2
+	if m.FourScore {
+		fourScore = 1
+		players = 4
+	}
+
+	_, _ = fmt.Fprintf(bw, "version %d\n", fm2Version)
+	_, _ = fmt.Fprintf(bw, "emuVersion %d\n", m.EmuVersion)
+	_, _ = fmt.Fprintf(bw, "rerecordCount %d\n", m.RerecordCount)
+	_, _ = fmt.Fprintf(bw, "palFlag %d\n", pal)
+	_, _ = fmt.Fprintf(bw, "romFilename %s\n", m.ROMFilename)
+	_, _ = fmt.Fprintf(bw, "romChecksum base64:%s\n", base64.StdEncoding.EncodeToString(m.ROMChecksum[:]))
+	_, _ = fmt.Fprintf(bw, "guid %s\n", m.GUID)
+	_, _ = fmt.Fprintf(bw, "fourscore %d\n", fourScore)
+	_, _ = bw.WriteString("microphone 0\nport0 1\nport1 1\nport2 0\nFDS 0\nNewPPU 0\n")
+	for _, comment := range m.Comments {
+		_, _ = fmt.Fprintf(bw, "comment %s\n", comment)
+	}
+	if len(m.SaveState) != 0 {
+		_, _ = fmt.Fprintf(bw, "savestate base64:%s\n", base64.StdEncoding.EncodeToString(m.SaveState))
+	}
+
+	line := make([]byte, 0, 42)
+	for _, frame := range m.Frames {
+		line = append(line[:0], '|')
+		line = strconv.AppendUint(line, uint64(frame.Command), 10)
+		for _, port := range frame.Ports[:players] {
+			line = append(line, '|')
+			for j := range len(fm2Buttons) {
+				if port&(1<<(len(fm2Buttons)-1-j)) != 0 {
+					line = append(line, fm2Buttons[j])
+				} else {
+					line = append(line, '.')
+				}
+			}
+		}
+		line = append(line, "||\n"...)
+		if _, err := bw.Write(line); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// decodeFM2Binary decodes a binary header value, which is either base64 with a "base64:" prefix or hex with a "0x" prefix.
+func decodeFM2Binary(val string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(val, "base64:"):
+		return base64.StdEncoding.DecodeString(strings.TrimPrefix(val, "base64:"))
+	case strings.HasPrefix(val, "0x"):
+		return hex.DecodeString(strings.TrimPrefix(val, "0x"))
+	default:
+		return nil, fmt.Errorf("%w: unknown binary encoding", ErrInvalidFM2)
+	}
+}
@@ -0,0 +1,102 @@
+package movie
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFM2 = `version 3
+emuVersion 22020
+rerecordCount 4
+palFlag 0
+romFilename Super Mario Bros.
+romChecksum base64:jjYwGG411HcjG/j9UOVM3Q==
+guid 2F5C0D3B-9A3E-4F43-A8E1-7B7C45C55A43
+fourscore 0
+microphone 0
+port0 1
+port1 1
+port2 0
+FDS 0
+NewPPU 0
+comment author gones
+|1|........|........||
+|0|.......A|........||
+|0|R..UT..A|.L....B.||
+`
+
+func TestReadFM2(t *testing.T) {
+	t.Parallel()
+
+	m, err := ReadFM2(strings.NewReader(testFM2))
+	require.NoError(t, err)
+
+	assert.Equal(t, 22020, m.EmuVersion)
+	assert.Equal(t, 4, m.RerecordCount)
+	assert.False(t, m.PAL)
+	assert.Equal(t, "Super Mario Bros.", m.ROMFilename)
+	assert.Equal(t, "2F5C0D3B-9A3E-4F43-A8E1-7B7C45C55A43", m.GUID)
+	assert.Equal(t, []string{"author gones"}, m.Comments)
+	assert.Empty(t, m.SaveState)
+	assert.Equal(t, []Frame{
+		{Command: CommandSoftReset},
+		{Ports: [4]byte{0x01, 0x00}},
+		{Ports: [4]byte{0x99, 0x42}},
+	}, m.Frames)
+}
+
+func TestReadFM2_Unsupported(t *testing.T) {
+	t.Parallel()
+
+	_, err := ReadFM2(strings.NewReader("version 3\nbinary 1\n"))
+	require.ErrorIs(t, err, ErrUnsupportedFM2)
+
+	_, err = ReadFM2(strings.NewReader("version 2\n"))
+	require.ErrorIs(t, err, ErrUnsupportedFM2)
+
+	_, err = ReadFM2(strings.NewReader("version 3\nsavestate base64:RkNTWHN0YXRl\n"))
+	require.ErrorIs(t, err, ErrUnsupportedFM2, "FCEUX save state")
+
+	_, err = ReadFM2(strings.NewReader(testFM2 + "|2|........|........||\n"))
+	require.ErrorIs(t, err, ErrUnsupportedFM2, "hard reset")
+}
+
+func TestMovie_WriteFM2(t *testing.T) {
+	t.Parallel()
+
+	m, err := ReadFM2(strings.NewReader(testFM2))
+	require.NoError(t, err)
+	m.SaveState = append(bytes.Clone(stateMagic), "state"...)
+
+	var buf bytes.Buffer
+	require.NoError(t, m.WriteFM2(&buf))
+	assert.Contains(t, buf.String(), "savestate base64:H4tzdGF0ZQ==\n")
+	assert.True(t, strings.HasSuffix(buf.String(), "|0|R..UT..A|.L....B.||\n"))
+
+	got, err := ReadFM2(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, m, got)
+}
+
+func TestMovie_WriteFM2_FourScore(t *testing.T) {
+	t.Parallel()
+
+	m, err := ReadFM2(strings.NewReader(strings.Replace(testFM2, "fourscore 0", "fourscore 1", 1) +
+		"|0|.......A|......B.|.....S..|....T...||\n"))
+	require.NoError(t, err)
+	assert.True(t, m.FourScore)
+	assert.Equal(t, Frame{Ports: [4]byte{0x01, 0x02, 0x04, 0x08}}, m.Frames[len(m.Frames)-1])
+
+	var buf bytes.Buffer
+	require.NoError(t, m.WriteFM2(&buf))
+	assert.Contains(t, buf.String(), "fourscore 1\n")
+	assert.True(t, strings.HasSuffix(buf.String(), "|0|.......A|......B.|.....S..|....T...||\n"))
+
+	got, err := ReadFM2(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, m, got)
+}
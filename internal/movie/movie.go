@@ -0,0 +1,72 @@
+package movie
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"fmt"
+
+	"gabe565.com/gones/internal/cartridge"
+)
+
+// Command is a bitmask of console commands that are executed at the start of a frame.
+type Command uint8
+
+const (
+	CommandSoftReset Command = 1 << iota
+	CommandHardReset
+)
+
+// Frame is the input for a single frame.
+//
+// Each port is a bitmask of pressed buttons, ordered the same way the
+// controller shifts them out (A, B, Select, Start, Up, Down, Left, Right).
+// Players 3 and 4 are only used with a four player adapter.
+type Frame struct {
+	Command Command
+	Ports   [4]byte
+}
+
+// Movie is a recording of per-frame input.
+//
+// A movie is anchored to power-on, or to the embedded SaveState when one is set.
+// SaveState is a GoNES save state, so FCEUX can only play movies that are anchored to power-on.
+type Movie struct {
+	EmuVersion    int
+	RerecordCount int
+	PAL           bool
+	FourScore     bool
+	ROMFilename   string
+	ROMChecksum   [md5.Size]byte
+	GUID          string
+	Comments      []string
+	SaveState     []byte
+
+	Frames []Frame
+}
+
+// New creates an empty movie for the given cartridge.
+func New(cart *cartridge.Cartridge) *Movie {
+	return &Movie{
+		ROMFilename: cart.Name(),
+		ROMChecksum: Checksum(cart),
+		GUID:        newGUID(),
+	}
+}
+
+// Checksum returns the MD5 of the ROM data, excluding the iNES header.
+func Checksum(cart *cartridge.Cartridge) [md5.Size]byte {
+	h := md5.New()
+	_, _ = h.Write(cart.PRG)
+	if cart.Header.CHRSize() != 0 {
+		_, _ = h.Write(cart.CHR)
+	}
+	var sum [md5.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+func newGUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return fmt.Sprintf("%X-%X-%X-%X-%X", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
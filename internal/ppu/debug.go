@@ -0,0 +1,134 @@
+package ppu
+
+import (
+	"image"
+	"image/color"
+
+	"gabe565.com/gones/internal/consts"
+)
+
+// Debug views read memory through the mapper like the PPU's $2007 port, so they match the game's
+// current CHR banks and nametable mapping, without the side effects of rendering fetches.
+
+const (
+	// NametablesWidth and NametablesHeight are the size of the four nametables in their 2x2 layout.
+	NametablesWidth  = 2 * consts.Width
+	NametablesHeight = 2 * consts.Height
+	// PatternTableSize is the width and height of a pattern table, which is 16x16 tiles.
+	PatternTableSize = 16 * 8
+)
+
+// Sprite is a decoded OAM entry.
+type Sprite struct {
+	X, Y       byte
+	Tile       byte
+	Palette    byte
+	Behind     bool
+	FlipH      bool
+	FlipV      bool
+	Attributes byte
+}
+
+// Sprite returns one of the 64 sprites in OAM.
+func (p *PPU) Sprite(i int) Sprite {
+	y, tile, a, x := p.OAM[i*4], p.OAM[i*4+1], p.OAM[i*4+2], p.OAM[i*4+3]
+	return Sprite{
+		X:          x,
+		Y:          y,
+		Tile:       tile,
+		Palette:    a&3 + 4,
+		Behind:     a&0x20 != 0,
+		FlipH:      a&0x40 != 0,
+		FlipV:      a&0x80 != 0,
+		Attributes: a,
+	}
+}
+
+// PaletteColor returns the color of an entry in palette RAM.
+func (p *PPU) PaletteColor(i int) color.RGBA {
+	return p.systemPalette.RGBA[p.readPalette(uint16(i)%32)%64] //nolint:gosec
+}
+
+// Scroll returns the top-left corner of the next frame in the nametables.
+// Games that change the scroll mid-frame, like for a status bar, only show the last scroll.
+func (p *PPU) Scroll() image.Point {
+	pt := image.Pt(int(p.TmpAddr.CoarseX)*8+int(p.FineX), int(p.TmpAddr.CoarseY)*8+int(p.TmpAddr.FineY))
+	if p.TmpAddr.NametableX {
+		pt.X += consts.Width
+	}
+	if p.TmpAddr.NametableY {
+		pt.Y += consts.Height
+	}
+	return pt
+}
+
+// DrawNametables draws all four nametables to img at pt, using the background pattern table.
+func (p *PPU) DrawNametables(img *image.RGBA, pt image.Point) {
+	for nt := range uint16(4) {
+		base := 0x2000 + nt*0x400
+		origin := pt.Add(image.Pt(int(nt%2)*consts.Width, int(nt/2)*consts.Height))
+		for i := range uint16(32 * 30) {
+			col, row := i%32, i/32
+			tile := p.ReadDataAddr(base + i)
+			attr := p.ReadDataAddr(base + 0x3C0 + row/4*8 + col/4)
+			attr = attr >> (row & 2 << 1) >> (col & 2) & 3
+
+			addr := p.Ctrl.BgTileAddr() + uint16(tile)*16
+			p.drawTile(img, origin.Add(image.Pt(int(col)*8, int(row)*8)), addr, attr, false, false)
+		}
+	}
+}
+
+// DrawPatternTable draws one of the two pattern tables to img at pt, using one of the 8 palettes.
+func (p *PPU) DrawPatternTable(img *image.RGBA, pt image.Point, table int, palette byte) {
+	for tile := range 256 {
+		addr := uint16(table*0x1000 + tile*16) //nolint:gosec
+		p.drawTile(img, pt.Add(image.Pt(tile%16*8, tile/16*8)), addr, palette, false, false)
+	}
+}
+
+// DrawSprite draws a sprite to img at pt with its palette and flips applied.
+// Tall sprites are drawn 8x16 when they are enabled, so the area should be 8x16.
+func (p *PPU) DrawSprite(img *image.RGBA, pt image.Point, i int) {
+	s := p.Sprite(i)
+	if !p.Ctrl.SpriteHeight {
+		addr := p.Ctrl.SpriteTileAddr() + uint16(s.Tile)*16
+		p.drawTile(img, pt, addr, s.Palette, s.FlipH, s.FlipV)
+		return
+	}
+
+	top := uint16(s.Tile&1)*0x1000 + uint16(s.Tile&0xFE)*16
+	bottom := top + 16
+	if s.FlipV {
+		top, bottom = bottom, top
+	}
+	p.drawTile(img, pt, top, s.Palette, s.FlipH, s.FlipV)
+	p.drawTile(img, pt.Add(image.Pt(0, 8)), bottom, s.Palette, s.FlipH, s.FlipV)
+}
+
+// drawTile draws an 8x8 tile from the pattern tables at addr to img at pt.
+func (p *PPU) drawTile(img *image.RGBA, pt image.Point, addr uint16, palette byte, flipH, flipV bool) {
+	for row := range 8 {
+		lo := p.ReadDataAddr(addr + uint16(row)) //nolint:gosec
+		hi := p.ReadDataAddr(addr + uint16(row) + 8)
+		y := row
+		if flipV {
+			y = 7 - row
+		}
+		for col := range 8 {
+			value := lo>>(7-col)&1 | hi>>(7-col)&1<<1
+			x := col
+			if flipH {
+				x = 7 - col
+			}
+
+			var c color.RGBA
+			if value == 0 {
+				c = p.PaletteColor(0)
+			} else {
+				c = p.PaletteColor(int(palette)*4 + int(value))
+			}
+			img.SetRGBA(pt.X+x, pt.Y+y, c)
+		}
+	}
+}
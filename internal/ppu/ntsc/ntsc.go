@@ -0,0 +1,229 @@
+package ntsc
+
+import (
+	"image"
+	"math"
+
+	"gabe565.com/gones/internal/config"
+)
+
+const (
+	// SamplesPerPixel is the number of video signal samples generated for each PPU dot.
+	SamplesPerPixel = 8
+	// SamplesPerCycle is the number of video signal samples in each color subcarrier cycle.
+	SamplesPerCycle = 12
+
+	// MaxResolution is the maximum number of output pixels per PPU dot.
+	MaxResolution = SamplesPerPixel
+
+	// phaseOffset aligns the decoder's color burst with the PPU's color phases.
+	phaseOffset = 3.9
+	// chromaGain scales demodulated chroma to match the NES's colors.
+	chromaGain = 1.4
+	// attenuation is the signal level multiplier applied by color emphasis.
+	attenuation = 0.746
+)
+
+//nolint:gochecknoglobals
+var (
+	// levels are the PPU's signal voltages for each luma level. The first half is the low level of the
+	// square wave, and the second half is the high level.
+	levels = [8]float32{0.228, 0.312, 0.552, 0.880, 0.616, 0.840, 1.100, 1.100}
+	black  = levels[1]
+	white  = levels[6]
+)
+
+// yiq is a color decoded from the video signal.
+type yiq struct {
+	y, i, q float32
+}
+
+// Filter decodes the PPU's composite video signal like an NTSC TV.
+//
+// Each pixel is converted to 8 samples of the PPU's square wave signal, then decoded to YIQ with box filters.
+// Chroma is averaged over a full color subcarrier cycle, so sharp luma changes bleed into the chroma,
+// producing artifact colors. The subcarrier phase shifts every line and every frame, which produces dot crawl.
+type Filter struct {
+	resolution int
+	lumaWidth  int
+	saturation float32
+	artifacts  float32
+
+	// carrier is the demodulation carrier at each phase, including the hue rotation and chroma gain
+	carrier [SamplesPerCycle]struct{ cos, sin float32 }
+	// signals is the signal level of each pixel value at each phase
+	signals [512][SamplesPerCycle]float32
+	// flat is the color of each pixel value without artifacts
+	flat [512]yiq
+
+	width int
+	image *image.RGBA
+	// sums and clean are running sums of the decoded signal and the artifact-free colors of each sample
+	sums  []yiq
+	clean []yiq
+}
+
+// New creates a filter for a PPU image of the given size.
+func New(conf config.NTSC, width, height int) *Filter {
+	f := &Filter{
+		resolution: min(max(conf.Resolution, 1), MaxResolution),
+		lumaWidth:  min(max(int(math.Round(SamplesPerCycle-8*conf.Sharpness)), 2), 2*SamplesPerCycle),
+		saturation: float32(min(max(conf.Saturation, 0), 2)),
+		artifacts:  float32(min(max(conf.Artifacts, 0), 1)),
+	}
+
+	hue := conf.Hue / 360 * SamplesPerCycle
+	for phase := range f.carrier {
+		angle := math.Pi * (float64(phase) + phaseOffset + hue) / (SamplesPerCycle / 2)
+		f.carrier[phase].cos = float32(math.Cos(angle)) * chromaGain
+		f.carrier[phase].sin = float32(math.Sin(angle)) * chromaGain
+	}
+
+	for pixel := range f.flat {
+		var c yiq
+		for phase := range SamplesPerCycle {
+			s := Signal(uint16(pixel), phase) //nolint:gosec
+			f.signals[pixel][phase] = s
+			c.y += s
+			c.i += s * f.carrier[phase].cos
+			c.q += s * f.carrier[phase].sin
+		}
+		f.flat[pixel] = yiq{
+			y: c.y / SamplesPerCycle,
+			i: c.i / SamplesPerCycle,
+			q: c.q / SamplesPerCycle,
+		}
+	}
+
+	samples := width * SamplesPerPixel
+	f.width = width
+	f.image = image.NewRGBA(image.Rect(0, 0, width*f.resolution, height))
+	f.sums = make([]yiq, samples+1)
+	f.clean = make([]yiq, samples+1)
+	return f
+}
+
+// Image returns the last decoded frame.
+func (f *Filter) Image() *image.RGBA {
+	return f.image
+}
+
+// Signal returns the normalized signal level of a pixel at a color subcarrier phase.
+// Black is 0, and white is 1.
+//
+// The pixel is a 9-bit value. Bits 0-5 are the color index, and bits 6-8 are the emphasis bits.
+func Signal(pixel uint16, phase int) float32 {
+	color := int(pixel & 0x0F)
+	level := pixel >> 4 & 3
+	emphasis := pixel >> 6
+
+	// Colors $xE and $xF are black
+	if color > 13 {
+		level = 1
+	}
+	low, high := levels[level], levels[4+level]
+	switch {
+	case color == 0:
+		low = high
+	case color > 12:
+		high = low
+	}
+
+	inPhase := func(color int) bool {
+		return (color+phase)%SamplesPerCycle < SamplesPerCycle/2
+	}
+
+	s := low
+	if inPhase(color) {
+		s = high
+	}
+	if emphasis&1 != 0 && inPhase(0) ||
+		emphasis&2 != 0 && inPhase(4) ||
+		emphasis&4 != 0 && inPhase(8) {
+		s *= attenuation
+	}
+	return (s - black) / (white - black)
+}
+
+// Render decodes a frame. Pixels are the PPU's 9-bit pixel values, and phases are the color subcarrier
+// phase of the first pixel in each line.
+func (f *Filter) Render(pixels []uint16, phases []byte) *image.RGBA {
+	for y, phase := range phases {
+		f.renderLine(y, pixels[y*f.width:(y+1)*f.width], int(phase))
+	}
+	return f.image
+}
+
+func (f *Filter) renderLine(y int, pixels []uint16, phase int) {
+	// Generate the signal, and sum the decoded signal and the artifact-free colors
+	var sum, clean yiq
+	k := 0
+	for _, pixel := range pixels {
+		pixel &= 0x1FF
+		signals := &f.signals[pixel]
+		flat := f.flat[pixel]
+		for range SamplesPerPixel {
+			s := signals[phase]
+			k++
+
+			sum.y += s
+			sum.i += s * f.carrier[phase].cos
+			sum.q += s * f.carrier[phase].sin
+			f.sums[k] = sum
+
+			clean.y += flat.y
+			clean.i += flat.i
+			clean.q += flat.q
+			f.clean[k] = clean
+
+			if phase++; phase == SamplesPerCycle {
+				phase = 0
+			}
+		}
+	}
+
+	samples := f.width * SamplesPerPixel
+	row := f.image.Pix[y*f.image.Stride:]
+	for x := range f.image.Rect.Dx() {
+		k := (2*x + 1) * SamplesPerPixel / (2 * f.resolution)
+
+		lumaStart := max(k-f.lumaWidth/2, 0)
+		lumaEnd := min(k-f.lumaWidth/2+f.lumaWidth, samples)
+		lumaN := float32(lumaEnd - lumaStart)
+		chromaStart := max(k-SamplesPerCycle/2, 0)
+		chromaEnd := min(k+SamplesPerCycle/2, samples)
+		chromaN := float32(chromaEnd - chromaStart)
+
+		composite := yiq{
+			y: (f.sums[lumaEnd].y - f.sums[lumaStart].y) / lumaN,
+			i: (f.sums[chromaEnd].i - f.sums[chromaStart].i) / chromaN,
+			q: (f.sums[chromaEnd].q - f.sums[chromaStart].q) / chromaN,
+		}
+		clean := yiq{
+			y: (f.clean[lumaEnd].y - f.clean[lumaStart].y) / lumaN,
+			i: (f.clean[chromaEnd].i - f.clean[chromaStart].i) / chromaN,
+			q: (f.clean[chromaEnd].q - f.clean[chromaStart].q) / chromaN,
+		}
+
+		c := yiq{
+			y: clean.y + (composite.y-clean.y)*f.artifacts,
+			i: (clean.i + (composite.i-clean.i)*f.artifacts) * f.saturation,
+			q: (clean.q + (composite.q-clean.q)*f.artifacts) * f.saturation,
+		}
+		row[x*4+0] = toByte(c.y + 0.956*c.i + 0.621*c.q)
+		row[x*4+1] = toByte(c.y - 0.272*c.i - 0.647*c.q)
+		row[x*4+2] = toByte(c.y - 1.106*c.i + 1.703*c.q)
+		row[x*4+3] = 0xFF
+	}
+}
+
+func toByte(v float32) byte {
+	switch {
+	case v <= 0:
+		return 0
+	case v >= 1:
+		return 0xFF
+	default:
+		return byte(v*0xFF + 0.5)
+	}
+}
@@ -0,0 +1,52 @@
+package ntsc
+
+import (
+	"image"
+	"slices"
+	"testing"
+
+	"gabe565.com/gones/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignal(t *testing.T) {
+	t.Parallel()
+	for phase := range SamplesPerCycle {
+		assert.InDelta(t, 0, Signal(0x0F, phase), 0.001, "$0F is black")
+		assert.InDelta(t, 1, Signal(0x20, phase), 0.001, "$20 is white")
+	}
+	assert.Less(t, Signal(0x20|0x1C0, 0), Signal(0x20, 0), "emphasis attenuates the signal")
+}
+
+func TestFilter_Render(t *testing.T) {
+	t.Parallel()
+
+	conf := config.NewDefault().UI.NTSC
+	f := New(conf, 16, 2)
+	assert.Equal(t, image.Rect(0, 0, 16*conf.Resolution, 2), f.Image().Rect)
+
+	// A flat red line decodes to red
+	pixels := slices.Repeat([]uint16{0x16}, 32)
+	img := f.Render(pixels, []byte{0, 4})
+	c := img.RGBAAt(img.Rect.Dx()/2, 0)
+	assert.Greater(t, c.R, c.G)
+	assert.Greater(t, c.R, c.B)
+
+	// Alternating black and white pixels produce artifact colors
+	for i := range pixels {
+		if i%2 == 0 {
+			pixels[i] = 0x30
+		} else {
+			pixels[i] = 0x0F
+		}
+	}
+	img = f.Render(pixels, []byte{0, 4})
+	c = img.RGBAAt(img.Rect.Dx()/2, 0)
+	assert.False(t, c.R == c.G && c.G == c.B, "artifacts are colored")
+
+	conf.Artifacts = 0
+	img = New(conf, 16, 2).Render(pixels, []byte{0, 4})
+	c = img.RGBAAt(img.Rect.Dx()/2, 0)
+	assert.InDelta(t, c.R, c.G, 1, "no artifacts without the composite signal")
+	assert.InDelta(t, c.G, c.B, 1, "no artifacts without the composite signal")
+}
@@ -12,6 +12,7 @@ import (
 	"gabe565.com/gones/internal/memory"
 	"gabe565.com/gones/internal/ppu/palette"
 	"gabe565.com/gones/internal/ppu/registers"
+	"gabe565.com/gones/internal/region"
 )
 
 type CPU interface {
@@ -21,18 +22,21 @@ type CPU interface {
 	interrupt.Stall
 }
 
-func New(conf *config.Config, mapper cartridge.Mapper) *PPU {
+func New(conf *config.Config, mapper cartridge.Mapper, r region.Region) *PPU {
 	rect := conf.UI.Overscan.Rect()
 	spriteLimit := uint8(8)
 	if conf.UI.RemoveSpriteLimit {
 		spriteLimit = consts.PPUOAMSize / 4
 	}
-	return &PPU{
+	p := &PPU{
 		offsets:       rect.Min,
-		mapper:        mapper,
 		image:         image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy())),
+		pixels:        make([]uint16, rect.Dx()*rect.Dy()),
 		Cycles:        21,
 		systemPalette: &palette.Default,
+		preLine:       r.Scanlines() - 1,
+		vblankLine:    r.VblankScanline(),
+		skipOddDot:    r.SkipsOddFrameDot(),
 		SpriteData: SpriteData{
 			limit:      spriteLimit,
 			Patterns:   make([]uint32, spriteLimit),
@@ -41,12 +45,20 @@ func New(conf *config.Config, mapper cartridge.Mapper) *PPU {
 			Indexes:    make([]byte, spriteLimit),
 		},
 	}
+	p.SetMapper(mapper)
+	return p
 }
 
 type PPU struct {
-	mapper  cartridge.Mapper
-	cpu     CPU
-	offsets image.Point
+	mapper     cartridge.Mapper
+	fetcher    cartridge.MapperPPUFetch
+	nametables cartridge.MapperNametable
+	cpu        CPU
+	offsets    image.Point
+
+	preLine    int
+	vblankLine int
+	skipOddDot bool
 
 	Ctrl      registers.Control
 	Mask      registers.Mask
@@ -71,7 +83,13 @@ type PPU struct {
 	ReadBuf    byte
 	OpenBus    byte
 	RenderDone bool
+	frames     uint64
 	image      *image.RGBA
+	pixels     []uint16
+	emphasis   uint16
+	// signalPhase is the NTSC color subcarrier phase of the current dot, in 1/12ths of a cycle
+	signalPhase byte
+	linePhases  [consts.Height]byte
 
 	BgTile     BgTile
 	SpriteData SpriteData
@@ -105,6 +123,7 @@ func (p *PPU) WriteMask(data byte) {
 }
 
 func (p *PPU) UpdatePalette(data byte) {
+	p.emphasis = uint16(data>>5) << 6
 	switch data & (registers.MaskEmphasizeRed | registers.MaskEmphasizeGreen | registers.MaskEmphasizeBlue) {
 	case 0:
 		p.systemPalette = &palette.Default
@@ -165,7 +184,7 @@ func (p *PPU) ReadStatus() byte {
 	p.VblRace = false
 	p.updateNMI()
 	p.AddrLatch = false
-	if p.Scanline == 241 && p.Cycles == 0 {
+	if p.Scanline == p.vblankLine && p.Cycles == 0 {
 		p.VblRace = true
 	}
 	return status
@@ -177,6 +196,10 @@ func (p *PPU) WriteData(data byte) {
 	case addr < 0x2000:
 		p.mapper.WriteMem(addr, data)
 	case 0x2000 <= addr && addr < 0x3F00:
+		if p.nametables != nil {
+			p.nametables.WriteNametable(p.VRAM[:], addr, data)
+			break
+		}
 		addr := p.MirrorVRAMAddr(addr)
 		p.VRAM[addr] = data
 	case 0x3F00 <= addr && addr < 0x4000:
@@ -192,7 +215,7 @@ func (p *PPU) WriteData(data byte) {
 
 func (p *PPU) ReadData() byte {
 	addr := p.Addr.Get() % 0x4000
-	if p.Mask.RenderingEnabled() && (p.Scanline == 261 || p.Scanline < 240) {
+	if p.Mask.RenderingEnabled() && (p.Scanline == p.preLine || p.Scanline < consts.Height) {
 		// If rendering enabled, increment Coarse X and Y
 		// https://www.nesdev.org/wiki/PPU_scrolling#$2007_reads_and_writes
 		p.Addr.IncrementX()
@@ -222,6 +245,9 @@ func (p *PPU) ReadDataAddr(addr uint16) byte {
 	case addr < 0x2000:
 		return p.mapper.ReadMem(addr)
 	case 0x2000 <= addr && addr < 0x3F00:
+		if p.nametables != nil {
+			return p.nametables.ReadNametable(p.VRAM[:], addr)
+		}
 		addr := p.MirrorVRAMAddr(addr)
 		return p.VRAM[addr]
 	case 0x3F00 <= addr && addr < 0x4000:
@@ -280,6 +306,9 @@ func (p *PPU) WriteMem(addr uint16, data byte) {
 	default:
 		slog.Error("Invalid PPU write", "addr", log.HexAddr(addr))
 	}
+	if mapper, ok := p.mapper.(cartridge.MapperOnPPUWrite); ok {
+		mapper.OnPPUWrite(addr, data)
+	}
 	p.OpenBus = data
 }
 
@@ -300,6 +329,9 @@ func (p *PPU) MirrorVRAMAddr(addr uint16) uint16 {
 }
 
 func (p *PPU) tick() {
+	// Each dot is 8 samples of the 12 sample color subcarrier
+	p.signalPhase = (p.signalPhase + 8) % 12
+
 	if p.NMIOffset != 0 {
 		p.NMIOffset--
 		if p.NMIOffset == 0 {
@@ -312,7 +344,7 @@ func (p *PPU) tick() {
 	}
 
 	if p.Mask.RenderingEnabled() {
-		if p.OddFrame && p.Scanline == 261 && p.Cycles == 339 {
+		if p.skipOddDot && p.OddFrame && p.Scanline == p.preLine && p.Cycles == 339 {
 			p.Cycles = 0
 			p.Scanline = 0
 			p.OddFrame = !p.OddFrame
@@ -324,7 +356,7 @@ func (p *PPU) tick() {
 		p.Cycles++
 	} else {
 		p.Cycles = 0
-		if p.Scanline < 261 {
+		if p.Scanline < p.preLine {
 			p.Scanline++
 		} else {
 			p.Scanline = 0
@@ -336,8 +368,8 @@ func (p *PPU) tick() {
 func (p *PPU) Step(render bool) {
 	p.tick()
 
-	preLine := p.Scanline == 261
-	visibleLine := p.Scanline < 240
+	preLine := p.Scanline == p.preLine
+	visibleLine := p.Scanline < consts.Height
 	renderLine := preLine || visibleLine
 	visibleCycle := 1 <= p.Cycles && p.Cycles <= 256
 
@@ -394,10 +426,13 @@ func (p *PPU) Step(render bool) {
 
 	switch p.Cycles {
 	case 1:
-		if p.Scanline == 241 && !p.VblRace {
-			p.Status.Vblank = true
-			p.updateNMI()
-			p.RenderDone = true
+		if p.Scanline == p.vblankLine {
+			p.frames++
+			if !p.VblRace {
+				p.Status.Vblank = true
+				p.updateNMI()
+				p.RenderDone = true
+			}
 		} else if preLine {
 			p.Status.Vblank = false
 			p.updateNMI()
@@ -450,6 +485,8 @@ func (p *PPU) SetCPU(c CPU) {
 
 func (p *PPU) SetMapper(m cartridge.Mapper) {
 	p.mapper = m
+	p.fetcher, _ = m.(cartridge.MapperPPUFetch)
+	p.nametables, _ = m.(cartridge.MapperNametable)
 }
 
 func (p *PPU) Width() int {
@@ -1,9 +1,13 @@
 package ppu
 
 import (
+	"image"
 	"testing"
 
 	"gabe565.com/gones/internal/cartridge"
+	"gabe565.com/gones/internal/consts"
+	"gabe565.com/gones/internal/ppu/palette"
+	"gabe565.com/gones/internal/ppu/registers"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -194,3 +198,73 @@ func TestCPU_OamDma(t *testing.T) {
 	ppu.WriteOamAddr(0x11)
 	assert.EqualValues(t, 0x66, ppu.ReadOam())
 }
+
+func TestPPU_Pixels(t *testing.T) {
+	t.Parallel()
+
+	ppu, _ := stubPPU()
+	ppu.image = image.NewRGBA(image.Rect(0, 0, consts.Width, consts.Height))
+	ppu.pixels = make([]uint16, consts.Width*consts.Height)
+	ppu.Palette[0] = 0x16
+	ppu.WriteMask(registers.MaskEmphasizeRed)
+	ppu.Scanline = 1
+	ppu.Cycles = 1
+	ppu.signalPhase = 4
+	ppu.renderPixel(true)
+
+	assert.EqualValues(t, 0x16|1<<6, ppu.Pixels()[consts.Width], "pixels include the emphasis bits")
+	assert.EqualValues(t, 4, ppu.LinePhases()[1])
+	assert.Equal(t, palette.EmphasizeR.RGBA[0x16], ppu.Image().RGBAAt(0, 1))
+}
+
+func TestPPU_Scroll(t *testing.T) {
+	t.Parallel()
+
+	ppu, _ := stubPPU()
+	ppu.WriteCtrl(0b11)
+	ppu.WriteScroll(13)
+	ppu.WriteScroll(42)
+	assert.Equal(t, image.Pt(consts.Width+13, consts.Height+42), ppu.Scroll())
+}
+
+func TestPPU_DrawPatternTable(t *testing.T) {
+	t.Parallel()
+
+	ppu, cart := stubPPU()
+	cart.CHR = make([]byte, 0x2000)
+	ppu.systemPalette = &palette.Default
+	ppu.Palette = [0x20]byte{0x0F, 0x16, 0x1A, 0x12, 0x0F, 0x20, 0x00, 0x10}
+	// Tile 1's first row is colors 1, 2, 3, 0, ...
+	cart.CHR[0x10] = 0b1010_0000
+	cart.CHR[0x18] = 0b0110_0000
+
+	img := image.NewRGBA(image.Rect(0, 0, PatternTableSize, PatternTableSize))
+	ppu.DrawPatternTable(img, image.Point{}, 0, 0)
+	assert.Equal(t, palette.Default.RGBA[0x16], img.RGBAAt(8, 0))
+	assert.Equal(t, palette.Default.RGBA[0x1A], img.RGBAAt(9, 0))
+	assert.Equal(t, palette.Default.RGBA[0x12], img.RGBAAt(10, 0))
+	assert.Equal(t, palette.Default.RGBA[0x0F], img.RGBAAt(11, 0))
+
+	ppu.DrawPatternTable(img, image.Point{}, 0, 1)
+	assert.Equal(t, palette.Default.RGBA[0x20], img.RGBAAt(8, 0))
+}
+
+func TestPPU_DrawSprite(t *testing.T) {
+	t.Parallel()
+
+	ppu, cart := stubPPU()
+	cart.CHR = make([]byte, 0x2000)
+	ppu.systemPalette = &palette.Default
+	ppu.Palette[0x11] = 0x16
+	cart.CHR[0x10] = 0b1000_0000
+	// Sprite 1 uses tile 1, flipped horizontally and vertically
+	copy(ppu.OAM[4:], []byte{10, 1, 0xC0, 20})
+
+	s := ppu.Sprite(1)
+	assert.Equal(t, Sprite{X: 20, Y: 10, Tile: 1, Palette: 4, FlipH: true, FlipV: true, Attributes: 0xC0}, s)
+
+	img := image.NewRGBA(image.Rect(0, 0, 8, 16))
+	ppu.DrawSprite(img, image.Point{}, 1)
+	assert.Equal(t, palette.Default.RGBA[0x16], img.RGBAAt(7, 7))
+	assert.NotEqual(t, palette.Default.RGBA[0x16], img.RGBAAt(0, 0))
+}
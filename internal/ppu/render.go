@@ -8,6 +8,29 @@ func (p *PPU) Image() *image.RGBA {
 	return p.image
 }
 
+// Pixels returns the 9-bit value of each pixel in [PPU.Image], as the PPU outputs them to the video signal.
+// Bits 0-5 are the color index, and bits 6-8 are the red, green, and blue emphasis bits.
+func (p *PPU) Pixels() []uint16 {
+	return p.pixels
+}
+
+// LinePhases returns the NTSC color subcarrier phase of the first pixel of each line in [PPU.Image],
+// in 1/12ths of a cycle.
+func (p *PPU) LinePhases() []byte {
+	return p.linePhases[p.offsets.Y : p.offsets.Y+p.Height()]
+}
+
+// Frames returns the number of frames that have been rendered since the PPU was created.
+// Unlike [PPU.RenderDone], it is never reset, so it can tell when each frame completes.
+func (p *PPU) Frames() uint64 {
+	return p.frames
+}
+
+// BeamPosition returns the pixel currently being drawn, relative to [PPU.Image].
+func (p *PPU) BeamPosition() image.Point {
+	return image.Pt(p.Cycles-1, p.Scanline).Sub(p.offsets)
+}
+
 func (p *PPU) renderPixel(render bool) {
 	x := p.Cycles - 1
 	y := p.Scanline
@@ -44,5 +67,12 @@ func (p *PPU) renderPixel(render bool) {
 
 		c := p.systemPalette.RGBA[colorIdx]
 		p.image.SetRGBA(x-p.offsets.X, y-p.offsets.Y, c)
+
+		if pt := image.Pt(x, y).Sub(p.offsets); pt.In(p.image.Rect) {
+			if pt.X == 0 {
+				p.linePhases[y] = p.signalPhase
+			}
+			p.pixels[pt.Y*p.image.Rect.Dx()+pt.X] = uint16(colorIdx) | p.emphasis
+		}
 	}
 }
@@ -0,0 +1,115 @@
+package record
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// MixName is the name of the file that holds the mixed output in an audio dump.
+const MixName = "mix.wav"
+
+// StemNames are the names of the files that hold each APU channel in an audio dump,
+// in the order of the channels passed to [AudioDump.WriteChannels].
+//
+//nolint:gochecknoglobals
+var StemNames = [5]string{"square1.wav", "square2.wav", "triangle.wav", "noise.wav", "dmc.wav"}
+
+// AudioDump writes every sample the APU produces to WAV files in a directory.
+//
+// Unlike [Recorder], samples are not padded or dropped to match the video, so the files hold exactly what the
+// APU produced. Stems are each channel mixed on its own through the APU's non-linear mixer, so they do not sum
+// exactly to the mix, and they ignore the channel toggles in the config.
+type AudioDump struct {
+	dir   string
+	files []*os.File
+	mix   *WAV
+	stems []*WAV
+	err   error
+}
+
+// NewAudioDump creates a directory with a WAV file for the mix, and one for each APU channel if stems is true.
+func NewAudioDump(dir string, rate int, stems bool) (*AudioDump, error) {
+	if err := os.MkdirAll(dir, 0o777); err != nil {
+		return nil, err
+	}
+
+	d := &AudioDump{dir: dir}
+	var err error
+	if d.mix, err = d.create(MixName, rate); err != nil {
+		return nil, errors.Join(err, d.closeFiles())
+	}
+	if stems {
+		d.stems = make([]*WAV, 0, len(StemNames))
+		for _, name := range StemNames {
+			wav, err := d.create(name, rate)
+			if err != nil {
+				return nil, errors.Join(err, d.closeFiles())
+			}
+			d.stems = append(d.stems, wav)
+		}
+	}
+	return d, nil
+}
+
+func (d *AudioDump) create(name string, rate int) (*WAV, error) {
+	f, err := os.Create(filepath.Join(d.dir, name))
+	if err != nil {
+		return nil, err
+	}
+	d.files = append(d.files, f)
+	return NewWAV(f, 1, rate)
+}
+
+// Dir returns the directory that the files are written to.
+func (d *AudioDump) Dir() string {
+	return d.dir
+}
+
+// Stems reports whether each channel is written to its own file.
+func (d *AudioDump) Stems() bool {
+	return d.stems != nil
+}
+
+// Samples returns the number of samples written to each file.
+func (d *AudioDump) Samples() int {
+	return d.mix.Samples()
+}
+
+// WriteSample writes a sample to the mix.
+func (d *AudioDump) WriteSample(sample float32) {
+	if d.err != nil {
+		return
+	}
+	d.err = d.mix.WriteSample(sample)
+}
+
+// WriteChannels writes a sample of each channel to its stem. It does nothing if stems are disabled.
+func (d *AudioDump) WriteChannels(channels [5]float32) {
+	if d.err != nil {
+		return
+	}
+	for i, wav := range d.stems {
+		if d.err = wav.WriteSample(channels[i]); d.err != nil {
+			return
+		}
+	}
+}
+
+// Close finishes every file.
+func (d *AudioDump) Close() error {
+	errs := []error{d.err, d.mix.Close()}
+	for _, wav := range d.stems {
+		errs = append(errs, wav.Close())
+	}
+	errs = append(errs, d.closeFiles())
+	return errors.Join(errs...)
+}
+
+func (d *AudioDump) closeFiles() error {
+	errs := make([]error, 0, len(d.files))
+	for _, f := range d.files {
+		errs = append(errs, f.Close())
+	}
+	return errors.Join(errs...)
+}
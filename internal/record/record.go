@@ -0,0 +1,103 @@
+// Package record writes gameplay to a video file and a matching WAV file, and dumps raw APU output to WAV files.
+package record
+
+import (
+	"errors"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Recorder writes frames to a Y4M file and samples to a WAV file.
+//
+// Both files are timed by the emulated frame count instead of the wall clock, so fast-forward and lag never
+// make them drift. Each frame is followed by rate/fps samples. If the emulator produced too few samples,
+// the last sample is repeated, and if it produced too many, the extras are dropped.
+type Recorder struct {
+	videoFile *os.File
+	audioFile *os.File
+	video     *Y4M
+	audio     *WAV
+
+	fps    int
+	rate   int
+	frames int
+	last   float32
+	err    error
+}
+
+// AudioPath returns the path of the WAV file that is written next to a video.
+func AudioPath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path)) + ".wav"
+}
+
+// New creates a video file at path, and a WAV file next to it.
+func New(path string, width, height, fps, rate int) (*Recorder, error) {
+	r := &Recorder{fps: fps, rate: rate}
+
+	var err error
+	if r.videoFile, err = os.Create(path); err != nil {
+		return nil, err
+	}
+	if r.audioFile, err = os.Create(AudioPath(path)); err != nil {
+		_ = r.videoFile.Close()
+		return nil, err
+	}
+	if r.video, err = NewY4M(r.videoFile, width, height, fps); err != nil {
+		return nil, errors.Join(err, r.closeFiles())
+	}
+	if r.audio, err = NewWAV(r.audioFile, 1, rate); err != nil {
+		return nil, errors.Join(err, r.closeFiles())
+	}
+	return r, nil
+}
+
+// WriteSample writes an audio sample.
+func (r *Recorder) WriteSample(sample float32) {
+	// Drop samples that are over a frame ahead of the video
+	if r.err != nil || r.audio.Samples() >= r.expectedSamples(r.frames+2) {
+		return
+	}
+	r.last = sample
+	r.err = r.audio.WriteSample(sample)
+}
+
+// WriteFrame writes a video frame, then pads the audio if it is behind.
+func (r *Recorder) WriteFrame(img *image.RGBA) error {
+	if r.err != nil {
+		return r.err
+	}
+	if r.err = r.video.WriteFrame(img); r.err != nil {
+		return r.err
+	}
+	r.frames++
+
+	for r.audio.Samples() < r.expectedSamples(r.frames) {
+		if r.err = r.audio.WriteSample(r.last); r.err != nil {
+			return r.err
+		}
+	}
+	return nil
+}
+
+// Frames returns the number of frames that have been written.
+func (r *Recorder) Frames() int {
+	return r.frames
+}
+
+// expectedSamples returns the number of samples that match the given number of frames.
+func (r *Recorder) expectedSamples(frames int) int {
+	return frames * r.rate / r.fps
+}
+
+// Close finishes both files.
+func (r *Recorder) Close() error {
+	errs := []error{r.err, r.video.Flush(), r.audio.Close()}
+	errs = append(errs, r.closeFiles())
+	return errors.Join(errs...)
+}
+
+func (r *Recorder) closeFiles() error {
+	return errors.Join(r.videoFile.Close(), r.audioFile.Close())
+}
@@ -0,0 +1,115 @@
+package record
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestY4M(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	y, err := NewY4M(&buf, 2, 1, 60)
+	require.NoError(t, err)
+
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.SetRGBA(0, 0, color.RGBA{0xFF, 0xFF, 0xFF, 0xFF})
+	require.NoError(t, y.WriteFrame(img))
+	require.NoError(t, y.Flush())
+
+	header := "YUV4MPEG2 W2 H1 F60:1 Ip A1:1 C444\nFRAME\n"
+	require.True(t, bytes.HasPrefix(buf.Bytes(), []byte(header)))
+	// Y, Cb, and Cr planes of a white and a black pixel
+	assert.Equal(t, []byte{235, 16, 128, 128, 128, 128}, buf.Bytes()[len(header):])
+}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "video.y4m")
+	assert.Equal(t, filepath.Join(filepath.Dir(path), "video.wav"), AudioPath(path))
+
+	// 10 samples per frame
+	r, err := New(path, 1, 1, 60, 600)
+	require.NoError(t, err)
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+
+	// Too few samples are padded with the last sample
+	for range 4 {
+		r.WriteSample(0.5)
+	}
+	require.NoError(t, r.WriteFrame(img))
+	assert.Equal(t, 10, r.audio.Samples())
+
+	// Samples over a frame ahead of the video are dropped
+	for range 30 {
+		r.WriteSample(0.25)
+	}
+	require.NoError(t, r.WriteFrame(img))
+	assert.Equal(t, 30, r.audio.Samples())
+	assert.Equal(t, 2, r.Frames())
+	require.NoError(t, r.Close())
+
+	video, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, bytes.Count(video, []byte("FRAME\n")))
+
+	audio, err := os.ReadFile(AudioPath(path))
+	require.NoError(t, err)
+	require.Len(t, audio, wavHeaderSize+30*2)
+	assert.Equal(t, "RIFF", string(audio[:4]))
+	assert.Equal(t, "WAVE", string(audio[8:12]))
+	assert.EqualValues(t, 600, binary.LittleEndian.Uint32(audio[24:]))
+	assert.EqualValues(t, 30*2, binary.LittleEndian.Uint32(audio[40:]))
+	assert.EqualValues(t, 16384, binary.LittleEndian.Uint16(audio[wavHeaderSize+2*9:]), "padded with the last sample")
+}
+
+func TestAudioDump(t *testing.T) {
+	t.Parallel()
+
+	t.Run("mix only", func(t *testing.T) {
+		t.Parallel()
+		dir := filepath.Join(t.TempDir(), "dump")
+		d, err := NewAudioDump(dir, 600, false)
+		require.NoError(t, err)
+		assert.False(t, d.Stems())
+		d.WriteSample(0.5)
+		d.WriteChannels([5]float32{0.5})
+		require.NoError(t, d.Close())
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, MixName, entries[0].Name())
+	})
+
+	t.Run("stems", func(t *testing.T) {
+		t.Parallel()
+		dir := t.TempDir()
+		d, err := NewAudioDump(dir, 600, true)
+		require.NoError(t, err)
+		assert.True(t, d.Stems())
+		for range 3 {
+			d.WriteSample(0.5)
+			d.WriteChannels([5]float32{0.25, 0, 0, 0, -0.5})
+		}
+		assert.Equal(t, 3, d.Samples())
+		require.NoError(t, d.Close())
+
+		for name, want := range map[string]int16{MixName: 16384, StemNames[0]: 8192, StemNames[1]: 0, StemNames[4]: -16384} {
+			audio, err := os.ReadFile(filepath.Join(dir, name))
+			require.NoError(t, err)
+			require.Len(t, audio, wavHeaderSize+3*2, name)
+			assert.EqualValues(t, 3*2, binary.LittleEndian.Uint32(audio[40:]), name)
+			assert.Equal(t, want, int16(binary.LittleEndian.Uint16(audio[wavHeaderSize:])), name) //nolint:gosec
+		}
+	})
+}
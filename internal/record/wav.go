@@ -0,0 +1,104 @@
+package record
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+const (
+	wavHeaderSize    = 44
+	wavBitsPerSample = 16
+)
+
+// WAV writes 16-bit PCM audio.
+// The header's sizes are written by [WAV.Close], so the underlying writer must be seekable.
+type WAV struct {
+	w        io.WriteSeeker
+	buf      *bufio.Writer
+	channels int
+	rate     int
+	samples  int
+}
+
+// NewWAV returns a writer for audio with the given number of channels and sample rate.
+func NewWAV(w io.WriteSeeker, channels, rate int) (*WAV, error) {
+	wav := &WAV{
+		w:        w,
+		buf:      bufio.NewWriter(w),
+		channels: channels,
+		rate:     rate,
+	}
+	// Reserve space for the header
+	if _, err := wav.buf.Write(make([]byte, wavHeaderSize)); err != nil {
+		return nil, err
+	}
+	return wav, nil
+}
+
+// WriteSample writes one sample for each channel. Samples are clamped between -1 and 1.
+func (w *WAV) WriteSample(samples ...float32) error {
+	var b [2]byte
+	for _, s := range samples {
+		v := int16(math.Round(float64(min(max(s, -1), 1)) * math.MaxInt16))
+		binary.LittleEndian.PutUint16(b[:], uint16(v)) //nolint:gosec
+		if _, err := w.buf.Write(b[:]); err != nil {
+			return err
+		}
+	}
+	w.samples++
+	return nil
+}
+
+// Samples returns the number of samples written to each channel.
+func (w *WAV) Samples() int {
+	return w.samples
+}
+
+// Close writes the header. It does not close the underlying writer.
+func (w *WAV) Close() error {
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+	if _, err := w.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	blockAlign := w.channels * wavBitsPerSample / 8
+	dataSize := w.samples * blockAlign
+	header := struct {
+		RIFF          [4]byte
+		Size          uint32
+		WAVE          [4]byte
+		Fmt           [4]byte
+		FmtSize       uint32
+		Format        uint16
+		Channels      uint16
+		Rate          uint32
+		ByteRate      uint32
+		BlockAlign    uint16
+		BitsPerSample uint16
+		Data          [4]byte
+		DataSize      uint32
+	}{
+		RIFF:          [4]byte{'R', 'I', 'F', 'F'},
+		Size:          uint32(wavHeaderSize - 8 + dataSize), //nolint:gosec
+		WAVE:          [4]byte{'W', 'A', 'V', 'E'},
+		Fmt:           [4]byte{'f', 'm', 't', ' '},
+		FmtSize:       16,
+		Format:        1,                           // PCM
+		Channels:      uint16(w.channels),          //nolint:gosec
+		Rate:          uint32(w.rate),              //nolint:gosec
+		ByteRate:      uint32(w.rate * blockAlign), //nolint:gosec
+		BlockAlign:    uint16(blockAlign),          //nolint:gosec
+		BitsPerSample: wavBitsPerSample,
+		Data:          [4]byte{'d', 'a', 't', 'a'},
+		DataSize:      uint32(dataSize), //nolint:gosec
+	}
+	if err := binary.Write(w.w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+	_, err := w.w.Seek(0, io.SeekEnd)
+	return err
+}
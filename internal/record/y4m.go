@@ -0,0 +1,61 @@
+package record
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+)
+
+// Y4M writes uncompressed video in the YUV4MPEG2 format.
+//
+// Frames are converted to full resolution 4:4:4 YCbCr with the BT.601 limited range,
+// so no color detail is lost to chroma subsampling.
+type Y4M struct {
+	w      *bufio.Writer
+	width  int
+	height int
+	planes []byte
+}
+
+// NewY4M writes the stream header, and returns a writer for frames of the given size.
+func NewY4M(w io.Writer, width, height, fps int) (*Y4M, error) {
+	y := &Y4M{
+		w:      bufio.NewWriter(w),
+		width:  width,
+		height: height,
+		planes: make([]byte, 3*width*height),
+	}
+	if _, err := fmt.Fprintf(y.w, "YUV4MPEG2 W%d H%d F%d:1 Ip A1:1 C444\n", width, height, fps); err != nil {
+		return nil, err
+	}
+	return y, nil
+}
+
+// WriteFrame writes a frame. Images larger than the stream are cropped, and smaller images are padded with black.
+func (y *Y4M) WriteFrame(img *image.RGBA) error {
+	size := y.width * y.height
+	luma, cb, cr := y.planes[:size], y.planes[size:2*size], y.planes[2*size:]
+	for i := range size {
+		row, col := i/y.width, i%y.width
+		var r, g, b int32
+		if pt := img.Rect.Min.Add(image.Pt(col, row)); pt.In(img.Rect) {
+			c := img.Pix[img.PixOffset(pt.X, pt.Y):]
+			r, g, b = int32(c[0]), int32(c[1]), int32(c[2])
+		}
+		luma[i] = byte((66*r+129*g+25*b+128)>>8 + 16)
+		cb[i] = byte((-38*r-74*g+112*b+128)>>8 + 128)
+		cr[i] = byte((112*r-94*g-18*b+128)>>8 + 128)
+	}
+
+	if _, err := io.WriteString(y.w, "FRAME\n"); err != nil {
+		return err
+	}
+	_, err := y.w.Write(y.planes)
+	return err
+}
+
+// Flush writes buffered frames to the underlying writer.
+func (y *Y4M) Flush() error {
+	return y.w.Flush()
+}
@@ -0,0 +1,182 @@
+package region
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gabe565.com/gones/internal/cartridge"
+	"gabe565.com/gones/internal/consts"
+)
+
+//go:generate go tool stringer -type Region -linecomment
+
+// Region selects the console's CPU, PPU, and APU timing.
+type Region uint8
+
+const (
+	Auto  Region = iota // auto
+	NTSC                // ntsc
+	PAL                 // pal
+	Dendy               // dendy
+)
+
+var ErrInvalid = errors.New("invalid region")
+
+func Values() []Region {
+	return []Region{Auto, NTSC, PAL, Dendy}
+}
+
+func Strings() []string {
+	values := Values()
+	s := make([]string, 0, len(values))
+	for _, v := range values {
+		s = append(s, v.String())
+	}
+	return s
+}
+
+func (r Region) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+func (r *Region) UnmarshalText(text []byte) error {
+	for _, v := range Values() {
+		if strings.EqualFold(string(text), v.String()) {
+			*r = v
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrInvalid, text)
+}
+
+// Resolve returns r, or the region detected from the cartridge when r is [Auto].
+func (r Region) Resolve(cart *cartridge.Cartridge) Region {
+	if r != Auto {
+		return r
+	}
+	return Detect(cart)
+}
+
+// Detect returns the region declared by a NES 2.0 header.
+// Otherwise, the region is guessed from the No-Intro name, falling back to NTSC.
+func Detect(cart *cartridge.Cartridge) Region {
+	if cart.Header.NESv2() {
+		switch cart.Header.Timing() {
+		case cartridge.TimingPAL:
+			return PAL
+		case cartridge.TimingDendy:
+			return Dendy
+		default:
+			return NTSC
+		}
+	}
+	return FromName(cart.Name())
+}
+
+// FromName guesses the region from the country tag in a No-Intro or GoodNES name.
+// Games released in both NTSC and PAL countries are treated as NTSC.
+func FromName(name string) Region {
+	for {
+		start := strings.IndexByte(name, '(')
+		if start == -1 {
+			return NTSC
+		}
+		end := strings.IndexByte(name[start:], ')')
+		if end == -1 {
+			return NTSC
+		}
+
+		if r, ok := fromCountryTag(name[start+1 : start+end]); ok {
+			return r
+		}
+		name = name[start+end+1:]
+	}
+}
+
+func fromCountryTag(tag string) (Region, bool) {
+	r := PAL
+	for country := range strings.SplitSeq(tag, ",") {
+		switch strings.TrimSpace(country) {
+		case "Europe", "Australia", "Germany", "France", "Spain", "Italy", "Sweden",
+			"Netherlands", "UK", "Scandinavia", "E":
+		case "USA", "Japan", "World", "Korea", "Canada", "Brazil", "Asia", "U", "J":
+			r = NTSC
+		default:
+			return Auto, false
+		}
+	}
+	return r, true
+}
+
+// CPUFrequency returns the CPU clock rate in Hz.
+func (r Region) CPUFrequency() float64 {
+	switch r {
+	case PAL:
+		return 1662607
+	case Dendy:
+		return 1773448
+	default:
+		return consts.CPUFrequency
+	}
+}
+
+// PPUClockRatio returns the number of PPU dots per CPU cycle as a fraction.
+func (r Region) PPUClockRatio() (uint, uint) {
+	if r == PAL {
+		return 16, 5
+	}
+	return 3, 1
+}
+
+// FrameRate returns the rate the hardware renders frames at.
+func (r Region) FrameRate() float64 {
+	switch r {
+	case PAL, Dendy:
+		return 50.0069789081886
+	default:
+		return consts.HardwareFrameRate
+	}
+}
+
+// TargetFrameRate returns the rate frames are presented at.
+func (r Region) TargetFrameRate() int {
+	switch r {
+	case PAL, Dendy:
+		return 50
+	default:
+		return consts.TargetFrameRate
+	}
+}
+
+// Scanlines returns the number of scanlines per frame, including the pre-render line.
+func (r Region) Scanlines() int {
+	switch r {
+	case PAL, Dendy:
+		return 312
+	default:
+		return 262
+	}
+}
+
+// VblankScanline returns the scanline where the vblank flag is set.
+func (r Region) VblankScanline() int {
+	if r == Dendy {
+		// Dendy delays vblank so that NTSC games get NTSC-like vblank timing
+		return 291
+	}
+	return 241
+}
+
+// SkipsOddFrameDot reports whether the PPU skips a dot on odd frames when rendering is enabled.
+func (r Region) SkipsOddFrameDot() bool {
+	return r != PAL && r != Dendy
+}
+
+// FrameCounterRate returns the number of CPU cycles between APU frame counter steps.
+func (r Region) FrameCounterRate() float64 {
+	if r == PAL {
+		return 8313
+	}
+	return float64(consts.CPUFrequency) / 240.0
+}
@@ -0,0 +1,59 @@
+package region
+
+import (
+	"testing"
+
+	"gabe565.com/gones/internal/cartridge"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		want Region
+	}{
+		{"", NTSC},
+		{"Super Mario Bros. (World)", NTSC},
+		{"Action in New York (Europe)", PAL},
+		{"Addams Family, The (Europe) (En,Fr,De)", PAL},
+		{"Tetris (USA, Europe)", NTSC},
+		{"Kirby's Adventure (Germany) (Rev 1)", PAL},
+		{"Some Homebrew (2024) (Europe)", PAL},
+		{"Unclosed (Europe", NTSC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, FromName(tt.name))
+		})
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+	cart := cartridge.New()
+	cart.SetName("Game (Europe).nes")
+	assert.Equal(t, NTSC, Detect(cart))
+
+	cart.Header.SetTiming(cartridge.TimingDendy)
+	assert.Equal(t, Dendy, Detect(cart))
+
+	cart.Header.SetNESv2(false)
+	assert.Equal(t, PAL, Detect(cart))
+	assert.Equal(t, NTSC, NTSC.Resolve(cart))
+}
+
+func TestRegion_UnmarshalText(t *testing.T) {
+	t.Parallel()
+	var r Region
+	require.NoError(t, r.UnmarshalText([]byte("PAL")))
+	assert.Equal(t, PAL, r)
+
+	require.ErrorIs(t, r.UnmarshalText([]byte("secam")), ErrInvalid)
+
+	text, err := Dendy.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "dendy", string(text))
+}
@@ -0,0 +1,153 @@
+// Package rewind keeps periodic state snapshots so the game can be stepped backwards.
+package rewind
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"io"
+	"slices"
+	"time"
+)
+
+var ErrEmpty = errors.New("no rewind state available")
+
+type entry struct {
+	Data  []byte
+	Delta bool
+}
+
+// Buffer is a ring of periodic state snapshots used to step the game backwards.
+//
+// The newest snapshot is kept uncompressed. Every older snapshot is stored as a
+// compressed XOR delta against the snapshot that was taken after it, so stepping
+// backwards only ever needs the current head and a single entry.
+type Buffer struct {
+	size     int
+	interval int
+	frames   int
+
+	head    []byte
+	entries []entry
+
+	buf bytes.Buffer
+	fw  *flate.Writer
+}
+
+// New returns a buffer that holds duration of gameplay, with a snapshot every interval frames.
+func New(duration time.Duration, interval, frameRate int) *Buffer {
+	interval = max(interval, 1)
+	size := int(duration.Seconds()*float64(frameRate)) / interval
+	size = max(size, 2)
+
+	return &Buffer{
+		size:     size,
+		interval: interval,
+		entries:  make([]entry, 0, size-1),
+	}
+}
+
+// Capture records a snapshot written by encode once the configured number of frames have elapsed.
+func (r *Buffer) Capture(frames int, encode func(io.Writer) error) error {
+	r.frames += frames
+	if r.frames < r.interval {
+		return nil
+	}
+	r.frames = 0
+
+	var buf bytes.Buffer
+	buf.Grow(len(r.head))
+	if err := encode(&buf); err != nil {
+		return err
+	}
+	curr := buf.Bytes()
+
+	if r.head != nil {
+		e, err := r.compress(r.head, curr)
+		if err != nil {
+			return err
+		}
+
+		if len(r.entries) >= r.size-1 {
+			r.entries = slices.Delete(r.entries, 0, 1)
+		}
+		r.entries = append(r.entries, e)
+	}
+
+	r.head = curr
+	return nil
+}
+
+// Rewind passes the newest snapshot to decode, then drops it so the next call goes further back.
+// Once the oldest snapshot is reached, it is loaded repeatedly.
+func (r *Buffer) Rewind(decode func(io.Reader) error) error {
+	if r.head == nil {
+		return ErrEmpty
+	}
+
+	if err := decode(bytes.NewReader(r.head)); err != nil {
+		return err
+	}
+	r.frames = 0
+
+	if n := len(r.entries); n != 0 {
+		prev, err := r.decompress(r.entries[n-1], r.head)
+		if err != nil {
+			return err
+		}
+		r.head = prev
+		r.entries = slices.Delete(r.entries, n-1, n)
+	}
+	return nil
+}
+
+func (r *Buffer) compress(prev, next []byte) (entry, error) {
+	e := entry{Data: prev}
+	if len(prev) == len(next) {
+		// Most of the state is unchanged between snapshots, so the XOR is mostly zeroes
+		delta := make([]byte, len(prev))
+		for i := range prev {
+			delta[i] = prev[i] ^ next[i]
+		}
+		e = entry{Data: delta, Delta: true}
+	}
+
+	r.buf.Reset()
+	if r.fw == nil {
+		var err error
+		if r.fw, err = flate.NewWriter(&r.buf, flate.BestSpeed); err != nil {
+			return e, err
+		}
+	} else {
+		r.fw.Reset(&r.buf)
+	}
+
+	if _, err := r.fw.Write(e.Data); err != nil {
+		return e, err
+	}
+	if err := r.fw.Close(); err != nil {
+		return e, err
+	}
+
+	e.Data = bytes.Clone(r.buf.Bytes())
+	return e, nil
+}
+
+func (r *Buffer) decompress(e entry, next []byte) ([]byte, error) {
+	fr := flate.NewReader(bytes.NewReader(e.Data))
+	defer func() {
+		_ = fr.Close()
+	}()
+
+	data, err := io.ReadAll(fr)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Delta {
+		for i := range data {
+			data[i] ^= next[i]
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,95 @@
+package rewind
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuffer_compress(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		prev      []byte
+		next      []byte
+		wantDelta bool
+	}{
+		{"same size", []byte("state 1 abcdef"), []byte("state 2 abcdeg"), true},
+		{"same data", []byte("state"), []byte("state"), true},
+		{"different size", []byte("state 1"), []byte("state 10"), false},
+		{"empty next", []byte("state"), []byte{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := New(time.Second, 1, 60)
+
+			e, err := r.compress(tt.prev, tt.next)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDelta, e.Delta)
+
+			got, err := r.decompress(e, tt.next)
+			require.NoError(t, err)
+			assert.Equal(t, tt.prev, got)
+		})
+	}
+}
+
+// state is a fake console whose state is a single number.
+type state struct {
+	value byte
+}
+
+func (s *state) encode(w io.Writer) error {
+	_, err := w.Write(bytes.Repeat([]byte{s.value}, 64))
+	return err
+}
+
+func (s *state) decode(r io.Reader) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.value = b[0]
+	return nil
+}
+
+func TestBuffer(t *testing.T) {
+	t.Parallel()
+
+	// 4 snapshots, one every 2 frames
+	r := New(8*time.Second, 2, 1)
+	require.Equal(t, 4, r.size)
+
+	var s state
+	require.ErrorIs(t, r.Rewind(s.decode), ErrEmpty)
+
+	for i := range byte(6) {
+		s.value = i
+		// Snapshots are only taken every interval
+		require.NoError(t, r.Capture(1, s.encode))
+		require.NoError(t, r.Capture(1, s.encode))
+	}
+	// The head and size-1 entries are kept, so the oldest snapshots are evicted
+	assert.Len(t, r.entries, r.size-1)
+
+	for _, want := range []byte{5, 4, 3, 2, 2, 2} {
+		s.value = 0xFF
+		require.NoError(t, r.Rewind(s.decode))
+		assert.Equal(t, want, s.value)
+	}
+	assert.Empty(t, r.entries)
+
+	// Capturing again continues from the oldest snapshot
+	s.value = 10
+	require.NoError(t, r.Capture(2, s.encode))
+	require.NoError(t, r.Rewind(s.decode))
+	assert.Equal(t, byte(10), s.value)
+	require.NoError(t, r.Rewind(s.decode))
+	assert.Equal(t, byte(2), s.value)
+}
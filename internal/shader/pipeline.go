@@ -0,0 +1,168 @@
+package shader
+
+import (
+	"image"
+	"time"
+
+	"gabe565.com/gones/internal/config"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Pipeline draws a frame through a chain of shader passes.
+// Each pass draws into a buffer that is the source of the next pass, and the last pass draws to the screen.
+type Pipeline struct {
+	passes  []*Pass
+	src     *ebiten.Image
+	buffers [2]*ebiten.Image
+	rect    image.Rectangle
+	start   time.Time
+}
+
+// New loads a pipeline for the given shaders and uniforms.
+func New(names []string, uniforms config.Uniforms) (*Pipeline, error) {
+	p := &Pipeline{
+		passes: make([]*Pass, 0, len(names)),
+		start:  time.Now(),
+	}
+	for _, name := range names {
+		pass, err := Load(name, uniforms)
+		if err != nil {
+			return nil, err
+		}
+		p.passes = append(p.passes, pass)
+	}
+	return p, nil
+}
+
+// SetFrame sets the image that is drawn by the next call to Draw.
+func (p *Pipeline) SetFrame(img *image.RGBA) {
+	size := img.Rect.Size()
+	if p.src == nil || p.src.Bounds().Size() != size {
+		if p.src != nil {
+			p.src.Deallocate()
+		}
+		p.src = ebiten.NewImage(size.X, size.Y)
+	}
+	p.src.WritePixels(img.Pix)
+}
+
+// Rect returns the area of the screen that the frame was last drawn to.
+func (p *Pipeline) Rect() image.Rectangle {
+	return p.rect
+}
+
+// Draw draws the frame through each pass, scaled to fit the screen while keeping its aspect ratio.
+func (p *Pipeline) Draw(screen *ebiten.Image) {
+	screen.Clear()
+	if p.src == nil {
+		return
+	}
+
+	p.rect = fit(p.src.Bounds().Size(), screen.Bounds().Size())
+	size := p.rect.Size()
+	if size.X <= 0 || size.Y <= 0 {
+		return
+	}
+	for i, buf := range p.buffers {
+		if buf == nil || buf.Bounds().Size() != size {
+			if buf != nil {
+				buf.Deallocate()
+			}
+			p.buffers[i] = ebiten.NewImage(size.X, size.Y)
+		}
+	}
+
+	if len(p.passes) == 0 {
+		var op ebiten.DrawImageOptions
+		op.GeoM.Scale(float64(size.X)/float64(p.src.Bounds().Dx()), float64(size.Y)/float64(p.src.Bounds().Dy()))
+		op.GeoM.Translate(float64(p.rect.Min.X), float64(p.rect.Min.Y))
+		screen.DrawImage(p.src, &op)
+		return
+	}
+
+	for _, step := range p.steps(screen, time.Since(p.start)) {
+		if step.dst != screen {
+			step.dst.Clear()
+		}
+
+		vertices := quad(step.src.Bounds(), step.dstRect)
+		step.dst.DrawTrianglesShader(vertices[:], []uint16{0, 1, 2, 1, 2, 3}, step.pass.shader, &ebiten.DrawTrianglesShaderOptions{
+			Uniforms: step.uniforms,
+			Images:   [4]*ebiten.Image{step.src},
+		})
+	}
+}
+
+// step is a single pass of a frame.
+type step struct {
+	pass     *Pass
+	src, dst *ebiten.Image
+	dstRect  image.Rectangle
+	uniforms map[string]any
+}
+
+// steps returns the passes that draw the frame to the screen.
+// Every pass after the first samples the buffer drawn by the pass before it, so SourceSize is the buffer's size.
+func (p *Pipeline) steps(screen *ebiten.Image, elapsed time.Duration) []step {
+	steps := make([]step, 0, len(p.passes))
+	src := p.src
+	for i, pass := range p.passes {
+		dst, dstRect := screen, p.rect
+		if i != len(p.passes)-1 {
+			dst, dstRect = p.buffers[i%2], p.buffers[i%2].Bounds()
+		}
+		steps = append(steps, step{
+			pass:     pass,
+			src:      src,
+			dst:      dst,
+			dstRect:  dstRect,
+			uniforms: pass.uniformsAt(src.Bounds().Size(), dstRect.Size(), elapsed),
+		})
+		src = dst
+	}
+	return steps
+}
+
+// Deallocate frees the pipeline's shaders and images.
+func (p *Pipeline) Deallocate() {
+	for _, pass := range p.passes {
+		pass.shader.Deallocate()
+	}
+	for _, img := range append(p.buffers[:], p.src) {
+		if img != nil {
+			img.Deallocate()
+		}
+	}
+}
+
+// fit returns the largest rectangle with the aspect ratio of src that fits in dst, centered in dst.
+func fit(src, dst image.Point) image.Rectangle {
+	if src.X == 0 || src.Y == 0 {
+		return image.Rectangle{}
+	}
+	size := dst
+	if dst.X*src.Y > dst.Y*src.X {
+		size.X = dst.Y * src.X / src.Y
+	} else {
+		size.Y = dst.X * src.Y / src.X
+	}
+	offset := dst.Sub(size).Div(2)
+	return image.Rectangle{Min: offset, Max: offset.Add(size)}
+}
+
+// quad returns the vertices that map src's pixels onto dst.
+func quad(src, dst image.Rectangle) [4]ebiten.Vertex {
+	vertex := func(sx, sy, dx, dy int) ebiten.Vertex {
+		return ebiten.Vertex{
+			DstX: float32(dx), DstY: float32(dy),
+			SrcX: float32(sx), SrcY: float32(sy),
+			ColorR: 1, ColorG: 1, ColorB: 1, ColorA: 1,
+		}
+	}
+	return [4]ebiten.Vertex{
+		vertex(src.Min.X, src.Min.Y, dst.Min.X, dst.Min.Y),
+		vertex(src.Max.X, src.Min.Y, dst.Max.X, dst.Min.Y),
+		vertex(src.Min.X, src.Max.Y, dst.Min.X, dst.Max.Y),
+		vertex(src.Max.X, src.Max.Y, dst.Max.X, dst.Max.Y),
+	}
+}
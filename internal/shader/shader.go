@@ -0,0 +1,165 @@
+package shader
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"image"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"gabe565.com/gones/internal/config"
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const Ext = ".kage"
+
+var (
+	ErrNotFound       = errors.New("shader not found")
+	ErrInvalidUniform = errors.New("invalid uniform")
+)
+
+//go:embed shaders
+var builtin embed.FS
+
+// Pass is a compiled shader and its uniforms.
+type Pass struct {
+	Name     string
+	shader   *ebiten.Shader
+	uniforms map[string]any
+}
+
+// Load compiles a shader. The name can be an absolute path, the name of a file in the shaders directory,
+// or the name of a built-in shader. The .kage extension is optional.
+func Load(name string, uniforms config.Uniforms) (*Pass, error) {
+	src, err := read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	key := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	pass := &Pass{Name: key, uniforms: make(map[string]any, len(uniforms[key]))}
+	for k, v := range uniforms[key] {
+		if pass.uniforms[k], err = toUniform(v); err != nil {
+			return nil, fmt.Errorf("%s: %w: %s", key, err, k)
+		}
+	}
+
+	if pass.shader, err = ebiten.NewShader(src); err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+
+	// Uniforms with the wrong type or length only fail when drawing, so validate them now
+	if err := pass.validate(); err != nil {
+		pass.shader.Deallocate()
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+	return pass, nil
+}
+
+func read(name string) ([]byte, error) {
+	if filepath.IsAbs(name) {
+		return os.ReadFile(name)
+	}
+
+	file := name
+	if filepath.Ext(file) == "" {
+		file += Ext
+	}
+
+	if runtime.GOOS != "js" {
+		dir, err := config.GetShaderDir()
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := os.ReadFile(filepath.Join(dir, file))
+		if !errors.Is(err, os.ErrNotExist) {
+			return b, err
+		}
+	}
+
+	b, err := builtin.ReadFile(path.Join("shaders", file))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return b, err
+}
+
+// toUniform converts a uniform from the config to a type the shader accepts.
+func toUniform(v any) (any, error) {
+	switch v := v.(type) {
+	case []any:
+		arr := make([]float32, 0, len(v))
+		for _, v := range v {
+			f, ok := toFloat(v)
+			if !ok {
+				return nil, ErrInvalidUniform
+			}
+			arr = append(arr, f)
+		}
+		return arr, nil
+	case bool:
+		if v {
+			return float32(1), nil
+		}
+		return float32(0), nil
+	default:
+		f, ok := toFloat(v)
+		if !ok {
+			return nil, ErrInvalidUniform
+		}
+		return f, nil
+	}
+}
+
+func toFloat(v any) (float32, bool) {
+	switch v := v.(type) {
+	case float64:
+		return float32(v), true
+	case int64:
+		return float32(v), true
+	case float32:
+		return v, true
+	case int:
+		return float32(v), true
+	default:
+		return 0, false
+	}
+}
+
+func (p *Pass) validate() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%w: %v", ErrInvalidUniform, r)
+		}
+	}()
+
+	// Ebiten panics if an image is drawn onto itself, so the source and destination must differ
+	src := ebiten.NewImage(1, 1)
+	defer src.Deallocate()
+	dst := ebiten.NewImage(1, 1)
+	defer dst.Deallocate()
+	dst.DrawRectShader(1, 1, p.shader, &ebiten.DrawRectShaderOptions{
+		Uniforms: p.uniformsAt(image.Pt(1, 1), image.Pt(1, 1), 0),
+		Images:   [4]*ebiten.Image{src},
+	})
+	return nil
+}
+
+// uniformsAt returns the pass's uniforms, along with the uniforms set by GoNES.
+func (p *Pass) uniformsAt(src, dst image.Point, elapsed time.Duration) map[string]any {
+	uniforms := make(map[string]any, len(p.uniforms)+3)
+	for k, v := range p.uniforms {
+		uniforms[k] = v
+	}
+	uniforms["SourceSize"] = []float32{float32(src.X), float32(src.Y)}
+	uniforms["OutputSize"] = []float32{float32(dst.X), float32(dst.Y)}
+	uniforms["Time"] = float32(elapsed.Seconds())
+	return uniforms
+}
@@ -0,0 +1,99 @@
+package shader
+
+import (
+	"image"
+	"testing"
+
+	"gabe565.com/gones/internal/config"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	t.Parallel()
+	for _, name := range []string{"bilinear", "sharp-bilinear", "scanlines.kage", "mask", "crt"} {
+		b, err := read(name)
+		require.NoError(t, err, name)
+		assert.NotEmpty(t, b, name)
+	}
+
+	_, err := read("missing")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLoad(t *testing.T) {
+	for _, name := range []string{"bilinear", "sharp-bilinear", "scanlines", "mask", "crt"} {
+		pass, err := Load(name, nil)
+		require.NoError(t, err, name)
+		assert.Equal(t, name, pass.Name)
+		pass.shader.Deallocate()
+	}
+
+	pass, err := Load("crt", config.Uniforms{"crt": {"Curvature": 0.5}})
+	require.NoError(t, err)
+	pass.shader.Deallocate()
+
+	_, err = Load("crt", config.Uniforms{"crt": {"Curvature": []any{1.0, 2.0}}})
+	require.ErrorIs(t, err, ErrInvalidUniform)
+
+	_, err = Load("missing", nil)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestPipeline(t *testing.T) {
+	p, err := New([]string{"crt", "scanlines", "sharp-bilinear"}, nil)
+	require.NoError(t, err)
+	t.Cleanup(p.Deallocate)
+
+	p.SetFrame(image.NewRGBA(image.Rect(0, 0, 256, 240)))
+	screen := ebiten.NewImage(800, 480)
+	t.Cleanup(screen.Deallocate)
+	p.Draw(screen)
+	assert.Equal(t, image.Rect(144, 0, 656, 480), p.Rect())
+
+	steps := p.steps(screen, 0)
+	require.Len(t, steps, 3)
+	assert.Equal(t, []float32{256, 240}, steps[0].uniforms["SourceSize"])
+	for _, step := range steps[1:] {
+		assert.Equal(t, []float32{512, 480}, step.uniforms["SourceSize"], step.pass.Name)
+	}
+	for _, step := range steps {
+		assert.Equal(t, []float32{512, 480}, step.uniforms["OutputSize"], step.pass.Name)
+	}
+	assert.NotSame(t, steps[0].dst, steps[1].dst)
+	assert.Same(t, screen, steps[2].dst)
+}
+
+func TestToUniform(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		v       any
+		want    any
+		wantErr require.ErrorAssertionFunc
+	}{
+		{"float", 0.5, float32(0.5), require.NoError},
+		{"int", int64(2), float32(2), require.NoError},
+		{"bool", true, float32(1), require.NoError},
+		{"list", []any{int64(1), 0.5}, []float32{1, 0.5}, require.NoError},
+		{"string", "a", nil, require.Error},
+		{"list of strings", []any{"a"}, nil, require.Error},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := toUniform(tt.v)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFit(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, image.Rect(0, 0, 512, 480), fit(image.Pt(256, 240), image.Pt(512, 480)))
+	assert.Equal(t, image.Rect(144, 0, 656, 480), fit(image.Pt(256, 240), image.Pt(800, 480)))
+	assert.Equal(t, image.Rect(0, 60, 512, 540), fit(image.Pt(256, 240), image.Pt(512, 600)))
+	assert.Equal(t, image.Rectangle{}, fit(image.Point{}, image.Pt(512, 480)))
+}
@@ -5,3 +5,13 @@ import "github.com/spf13/cobra"
 func CompleteROM(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 	return []string{"nes"}, cobra.ShellCompDirectiveFilterFileExt
 }
+
+// CompleteGame completes files that can be played, including Famicom Disk System images and NSF music.
+func CompleteGame(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return []string{"nes", "fds", "nsf", "nsfe"}, cobra.ShellCompDirectiveFilterFileExt
+}
+
+// CompleteNSF completes NSF music files.
+func CompleteNSF(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return []string{"nsf", "nsfe"}, cobra.ShellCompDirectiveFilterFileExt
+}
@@ -5,13 +5,9 @@ import (
 	"errors"
 	"io"
 
-	"gabe565.com/gones/internal/apu"
-	"gabe565.com/gones/internal/bus"
 	"gabe565.com/gones/internal/cartridge"
 	"gabe565.com/gones/internal/config"
 	"gabe565.com/gones/internal/console"
-	"gabe565.com/gones/internal/cpu"
-	"gabe565.com/gones/internal/ppu"
 )
 
 //go:embed roms
@@ -22,28 +18,8 @@ func stubConsole(r io.Reader) (*console.Console, error) {
 	if err != nil {
 		return nil, err
 	}
-	mapper, err := cartridge.NewMapper(cart)
-	if err != nil {
-		return nil, err
-	}
-
-	conf := config.NewDefault()
-	c := &console.Console{
-		Config:    conf,
-		CPU:       nil,
-		Bus:       nil,
-		PPU:       ppu.New(conf, mapper),
-		APU:       apu.New(conf),
-		Cartridge: cart,
-		Mapper:    mapper,
-	}
-	c.Bus = bus.New(conf, c.Mapper, c.PPU, c.APU)
-	c.CPU = cpu.New(c.Bus)
-
-	c.PPU.SetCPU(c.CPU)
-	c.APU.SetCPU(c.CPU)
 
-	return c, nil
+	return console.New(config.NewDefault(), cart, console.WithHeadless())
 }
 
 type consoleTest struct {